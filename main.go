@@ -1,11 +1,34 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+
 	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: sbercloud.Provider})
+	ctx := context.Background()
+
+	// The SDK provider must come first, as the framework provider reuses its configuration.
+	sdkProvider := sbercloud.Provider()
+	providers := []func() tfprotov5.ProviderServer{
+		sdkProvider.GRPCProvider,
+		providerserver.NewProtocol5(sbercloud.NewFrameworkProvider(sdkProvider)),
+	}
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = tf5server.Serve("registry.terraform.io/sbercloud-terraform/sbercloud", muxServer.ProviderServer)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
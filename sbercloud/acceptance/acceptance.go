@@ -181,11 +181,27 @@ func init() {
 
 	rawProvider := TestAccProvider
 	rawProvider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		d.Set("endpoints", map[string]interface{}{
+		endpoints := map[string]interface{}{
 			"er": "https://er.ru-moscow-1.hc.cloud.ru",
-		})
+		}
 
-		return sbercloud.Provider().ConfigureContextFunc(ctx, d)
+		// In replay mode, the requests sent while configuring the provider are answered by a local stub
+		if cassetteMode() == cassetteModeReplay {
+			identityURL := startReplayIdentityServer()
+			endpoints["iam"] = identityURL
+			d.Set("auth_url", identityURL+"/v3")
+			if d.Get("access_key").(string) == "" {
+				d.Set("access_key", replayAccessKey)
+				d.Set("secret_key", replaySecretKey)
+			}
+		}
+		d.Set("endpoints", endpoints)
+
+		meta, diags := sbercloud.Provider().ConfigureContextFunc(ctx, d)
+		if !diags.HasError() && cassetteMode() != "" {
+			installCassetteTransport(meta.(*config.Config))
+		}
+		return meta, diags
 	}
 
 	TestAccProviderFactories = map[string]func() (*schema.Provider, error){
@@ -456,6 +472,7 @@ func preCheckRequiredEnvVars(t *testing.T) {
 
 func TestAccPreCheck(t *testing.T) {
 	preCheckRequiredEnvVars(t)
+	useCassette(t)
 }
 
 func TestAccPreCheckVpcId(t *testing.T) {
@@ -835,22 +852,22 @@ func TestAccPreCheckECSID(t *testing.T) {
 
 // lintignore:AT003
 func RandomAccResourceName() string {
-	return fmt.Sprintf("tf_acc_test_%s", acctest.RandString(5))
+	return fmt.Sprintf("tf_acc_test_%s", randString(5))
 }
 
 // lintignore:AT003
 func RandomAccResourceNameWithDash() string {
-	return fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	return fmt.Sprintf("tf-acc-test-%s", randString(5))
 }
 
 // lintignore:AT003
 func RandomCidr() string {
-	return fmt.Sprintf("172.16.%d.0/24", acctest.RandIntRange(0, 255))
+	return fmt.Sprintf("172.16.%d.0/24", randIntRange(0, 255))
 }
 
 // lintignore:AT003
 func RandomCidrAndGatewayIp() (string, string) {
-	seed := acctest.RandIntRange(0, 255)
+	seed := randIntRange(0, 255)
 	return fmt.Sprintf("172.16.%d.0/24", seed), fmt.Sprintf("172.16.%d.1", seed)
 }
 
@@ -862,10 +879,10 @@ func RandomPassword(customChars ...string) string {
 		specialChars = customChars[0]
 	}
 	return fmt.Sprintf("%s%s%s%d",
-		acctest.RandStringFromCharSet(2, "ABCDEFGHIJKLMNOPQRSTUVWXZY"),
-		acctest.RandStringFromCharSet(3, acctest.CharSetAlpha),
-		acctest.RandStringFromCharSet(2, specialChars),
-		acctest.RandIntRange(1000, 9999))
+		randStringFromCharSet(2, "ABCDEFGHIJKLMNOPQRSTUVWXZY"),
+		randStringFromCharSet(3, acctest.CharSetAlpha),
+		randStringFromCharSet(2, specialChars),
+		randIntRange(1000, 9999))
 }
 
 func ReplaceVarsForTest(rs *terraform.ResourceState, linkTmpl string) (string, error) {
@@ -0,0 +1,112 @@
+package bss
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
+)
+
+func TestAccDatasourcePriceEstimate_postPaid(t *testing.T) {
+	rName := "data.sbercloud_price_estimate.test"
+	dc := acceptance.InitDataSourceCheck(rName)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatasourcePriceEstimate_basic("postPaid", ""),
+				Check: resource.ComposeTestCheckFunc(
+					dc.CheckResourceExists(),
+					resource.TestCheckResourceAttr(rName, "currency", "RUB"),
+					resource.TestCheckResourceAttrSet(rName, "total_price"),
+					resource.TestCheckResourceAttrSet(rName, "total_official_price"),
+					resource.TestCheckResourceAttr(rName, "items.#", "6"),
+					resource.TestCheckResourceAttr(rName, "items.0.id", "compute_instances.0"),
+					resource.TestCheckResourceAttr(rName, "items.0.quantity", "2"),
+					resource.TestCheckResourceAttrSet(rName, "items.0.price"),
+					resource.TestCheckResourceAttr(rName, "items.1.id", "compute_instances.0.system_disk"),
+					resource.TestCheckResourceAttr(rName, "items.2.id", "compute_instances.0.data_disks.0"),
+					resource.TestCheckResourceAttr(rName, "items.3.id", "bandwidths.0"),
+					resource.TestCheckResourceAttr(rName, "items.4.id", "rds_instances.0"),
+					resource.TestCheckResourceAttr(rName, "items.5.id", "rds_instances.0.volume"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDatasourcePriceEstimate_prePaid(t *testing.T) {
+	rName := "data.sbercloud_price_estimate.test"
+	dc := acceptance.InitDataSourceCheck(rName)
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDatasourcePriceEstimate_basic("prePaid", `
+  period_unit = "month"
+  period      = 1
+`),
+				Check: resource.ComposeTestCheckFunc(
+					dc.CheckResourceExists(),
+					resource.TestCheckResourceAttr(rName, "currency", "RUB"),
+					resource.TestCheckResourceAttrSet(rName, "total_price"),
+					resource.TestCheckResourceAttrSet(rName, "total_official_price"),
+					resource.TestCheckResourceAttr(rName, "items.#", "6"),
+					resource.TestCheckResourceAttrSet(rName, "items.0.price"),
+					resource.TestCheckResourceAttrSet(rName, "items.0.official_price"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDatasourcePriceEstimate_basic(chargingMode, period string) string {
+	return `
+data "sbercloud_availability_zones" "test" {}
+
+data "sbercloud_compute_flavors" "test" {
+  availability_zone = data.sbercloud_availability_zones.test.names[0]
+  performance_type  = "normal"
+  cpu_core_count    = 2
+  memory_size       = 4
+}
+
+data "sbercloud_rds_flavors" "test" {
+  db_type       = "PostgreSQL"
+  db_version    = "12"
+  instance_mode = "single"
+}
+
+data "sbercloud_price_estimate" "test" {
+  charging_mode = "` + chargingMode + `"
+` + period + `
+  compute_instances {
+    flavor_id         = data.sbercloud_compute_flavors.test.ids[0]
+    availability_zone = data.sbercloud_availability_zones.test.names[0]
+    system_disk_type  = "SAS"
+    system_disk_size  = 40
+    quantity          = 2
+
+    data_disks {
+      type = "SSD"
+      size = 100
+    }
+  }
+
+  bandwidths {
+    size = 5
+  }
+
+  rds_instances {
+    flavor      = data.sbercloud_rds_flavors.test.flavors[0].name
+    volume_type = "ULTRAHIGH"
+    volume_size = 40
+  }
+}
+`
+}
@@ -0,0 +1,499 @@
+package acceptance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+
+	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud"
+)
+
+// The acceptance tests can record the HTTP interactions with SberCloud into cassette files and replay them later
+// without an account. The mode is selected by the SBC_ACC_CASSETTE_MODE environment variable:
+//   - record: the requests are sent to SberCloud and the interactions are saved to the cassette of every test;
+//   - replay: the requests are answered from the cassette of every test, the tests without a cassette are skipped.
+//
+// The cassettes are saved to the testdata/cassettes directory of the test package, or to SBC_ACC_CASSETTE_DIR.
+// The project IDs, the domain ID and the credentials are replaced with fake values, the sensitive JSON fields are
+// redacted and only the Content-Type header is kept, so the cassettes can be committed.
+const (
+	cassetteModeRecord = "record"
+	cassetteModeReplay = "replay"
+
+	defaultCassetteDir = "testdata/cassettes"
+	cassetteVersion    = 1
+
+	replayAccessKey = "REPLAY_ACCESS_KEY"
+	replaySecretKey = "REPLAY_SECRET_KEY"
+)
+
+var (
+	// cassetteSlot serializes the tests using cassettes, as all of them share the provider instance
+	cassetteSlot = make(chan struct{}, 1)
+
+	cassetteMu      sync.Mutex
+	currentCassette *cassette
+	// cassetteConfig is the configuration of the provider, used to scrub the recorded interactions
+	cassetteConfig *config.Config
+
+	replayIdentityOnce sync.Once
+	replayIdentityURL  string
+
+	testRandsMu sync.Mutex
+	testRands   = make(map[string]*rand.Rand)
+
+	testNameRegexp = regexp.MustCompile(`^Test[A-Z_0-9]`)
+)
+
+func cassetteMode() string {
+	return strings.ToLower(os.Getenv("SBC_ACC_CASSETTE_MODE"))
+}
+
+func cassetteDir() string {
+	if dir := os.Getenv("SBC_ACC_CASSETTE_DIR"); dir != "" {
+		return dir
+	}
+	return defaultCassetteDir
+}
+
+// cassette is the recorded HTTP interactions of a test.
+type cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*interaction `json:"interactions"`
+
+	name string
+	path string
+	mode string
+
+	mu   sync.Mutex
+	used []bool
+}
+
+type interaction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`
+}
+
+func cassettePath(testName string) string {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(testName)
+	return filepath.Join(cassetteDir(), name+".json")
+}
+
+func loadCassette(path string) (*cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error parsing cassette %s: %s", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported version %d of cassette %s", c.Version, path)
+	}
+	c.used = make([]bool, len(c.Interactions))
+	return &c, nil
+}
+
+// save scrubs the recorded interactions with the provider configuration and writes them to the cassette file.
+func (c *cassette) save(cfg *config.Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	scrubber := newCassetteScrubber(cfg)
+	for _, i := range c.Interactions {
+		i.Request.URL = scrubber.Replace(i.Request.URL)
+		i.Request.Body = scrubber.Replace(i.Request.Body)
+		i.Response.Body = scrubber.Replace(i.Response.Body)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// record appends the interaction of the request and the response, which body is restored for the caller.
+func (c *cassette) record(req *http.Request, reqBody []byte, resp *http.Response) error {
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return err
+	}
+
+	i := &interaction{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URL:    normalizeCassetteURL(req.URL),
+			Body:   normalizeCassetteBody(reqBody),
+		},
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Body:       normalizeCassetteBody(respBody),
+		},
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		i.Response.Headers = map[string][]string{"Content-Type": {contentType}}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, i)
+	return nil
+}
+
+// replay returns the response of the first unused interaction matching the request. If all the matching
+// interactions are used, the last one is returned again, so that an additional refresh does not fail the test.
+func (c *cassette) replay(req *http.Request, reqBody []byte) (*http.Response, error) {
+	method := req.Method
+	reqURL := normalizeCassetteURL(req.URL)
+	body := normalizeCassetteBody(reqBody)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matched *interaction
+	for n, i := range c.Interactions {
+		if i.Request.Method != method || i.Request.URL != reqURL || i.Request.Body != body {
+			continue
+		}
+		matched = i
+		if !c.used[n] {
+			c.used[n] = true
+			break
+		}
+	}
+	if matched == nil {
+		return nil, fmt.Errorf("no interaction recorded in cassette %s for %s %s", c.path, method, reqURL)
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", matched.Response.StatusCode, http.StatusText(matched.Response.StatusCode)),
+		StatusCode:    matched.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(matched.Response.Body)),
+		ContentLength: int64(len(matched.Response.Body)),
+		Request:       req,
+	}
+	for key, values := range matched.Response.Headers {
+		resp.Header[key] = values
+	}
+	return resp, nil
+}
+
+// normalizeCassetteURL returns the URL with the query parameters sorted.
+func normalizeCassetteURL(u *url.URL) string {
+	normalized := *u
+	normalized.User = nil
+	normalized.RawQuery = normalized.Query().Encode()
+	return normalized.String()
+}
+
+// normalizeCassetteBody returns the JSON body re-encoded with the sorted keys and the sensitive fields redacted,
+// so that the bodies of the recorded and the replayed requests can be compared. Other bodies are kept as is.
+func normalizeCassetteBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return string(body)
+	}
+
+	normalized, err := json.Marshal(sbercloud.RedactSensitiveValues(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(normalized)
+}
+
+// fakeCassetteID returns a stable fake ID in the format of the SberCloud project and domain IDs.
+func fakeCassetteID(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:16])
+}
+
+// newCassetteScrubber replaces the IDs of the account with the fake ones served by the replay identity server,
+// and the credentials with the placeholders.
+func newCassetteScrubber(cfg *config.Config) *strings.Replacer {
+	if cfg == nil {
+		return strings.NewReplacer()
+	}
+
+	var pairs []string
+	cfg.RPLock.Lock()
+	for region, projectID := range cfg.RegionProjectIDMap {
+		if projectID != "" {
+			pairs = append(pairs, projectID, fakeCassetteID(region))
+		}
+	}
+	cfg.RPLock.Unlock()
+
+	if cfg.DomainID != "" {
+		pairs = append(pairs, cfg.DomainID, fakeCassetteID("domain"))
+	}
+	for value, placeholder := range map[string]string{
+		cfg.AccessKey:     replayAccessKey,
+		cfg.SecretKey:     replaySecretKey,
+		cfg.SecurityToken: "***",
+	} {
+		if value != "" {
+			pairs = append(pairs, value, placeholder)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// cassetteTransport records the requests to or replays them from the cassette of the current test.
+type cassetteTransport struct {
+	Transport http.RoundTripper
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cassetteMu.Lock()
+	c := currentCassette
+	cassetteMu.Unlock()
+
+	if c == nil {
+		if cassetteMode() == cassetteModeReplay {
+			return nil, fmt.Errorf("no cassette is used for %s %s, the test must call TestAccPreCheck",
+				req.Method, req.URL)
+		}
+		return t.Transport.RoundTrip(req)
+	}
+
+	reqBody, err := readCassetteRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.mode == cassetteModeReplay {
+		return c.replay(req, reqBody)
+	}
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, c.record(req, reqBody, resp)
+}
+
+func readCassetteRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// useCassette records or replays the HTTP interactions of the test, depending on SBC_ACC_CASSETTE_MODE.
+// The tests using cassettes run one by one, until the cleanup of the test.
+func useCassette(t *testing.T) {
+	mode := cassetteMode()
+	if mode == "" {
+		return
+	}
+	if mode != cassetteModeRecord && mode != cassetteModeReplay {
+		t.Fatalf("invalid SBC_ACC_CASSETTE_MODE %q, the valid values are %q and %q",
+			mode, cassetteModeRecord, cassetteModeReplay)
+	}
+
+	cassetteMu.Lock()
+	if currentCassette != nil && currentCassette.name == t.Name() {
+		// the precheck is called more than once by the test
+		cassetteMu.Unlock()
+		return
+	}
+	cassetteMu.Unlock()
+
+	path := cassettePath(t.Name())
+	c := &cassette{Version: cassetteVersion}
+	if mode == cassetteModeReplay {
+		loaded, err := loadCassette(path)
+		if os.IsNotExist(err) {
+			t.Skipf("no cassette recorded for %s in %s", t.Name(), path)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		c = loaded
+	}
+	c.name, c.path, c.mode = t.Name(), path, mode
+
+	cassetteSlot <- struct{}{}
+	cassetteMu.Lock()
+	currentCassette = c
+	cassetteMu.Unlock()
+
+	t.Cleanup(func() {
+		cassetteMu.Lock()
+		currentCassette = nil
+		cfg := cassetteConfig
+		cassetteMu.Unlock()
+		<-cassetteSlot
+
+		if mode == cassetteModeRecord && !t.Failed() {
+			if err := c.save(cfg); err != nil {
+				t.Errorf("error saving cassette %s: %s", path, err)
+			}
+		}
+	})
+}
+
+// startReplayIdentityServer starts a stub of the IAM API which answers the requests sent while configuring
+// the provider in replay mode, and returns its URL.
+func startReplayIdentityServer() string {
+	replayIdentityOnce.Do(func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/projects"):
+				region := r.URL.Query().Get("name")
+				_, _ = fmt.Fprintf(w, `{"projects":[{"id":%q,"name":%q}],"links":{}}`, fakeCassetteID(region), region)
+			case strings.HasSuffix(r.URL.Path, "/domains"):
+				_, _ = fmt.Fprintf(w, `{"domains":[{"id":%q,"name":"replay"}],"links":{}}`, fakeCassetteID("domain"))
+			case strings.HasSuffix(r.URL.Path, "/catalog"):
+				_, _ = w.Write([]byte(`{"catalog":[],"links":{}}`))
+			default:
+				log.Printf("[WARN] unexpected request to the replay identity server: %s %s", r.Method, r.URL)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		replayIdentityURL = server.URL
+	})
+	return replayIdentityURL
+}
+
+// installCassetteTransport wraps the transport of every provider client with the cassette transport.
+func installCassetteTransport(cfg *config.Config) {
+	cassetteMu.Lock()
+	cassetteConfig = cfg
+	cassetteMu.Unlock()
+
+	for _, client := range []*golangsdk.ProviderClient{cfg.HwClient, cfg.DomainClient} {
+		if client == nil {
+			continue
+		}
+		if _, ok := client.HTTPClient.Transport.(*cassetteTransport); ok {
+			continue
+		}
+		client.HTTPClient.Transport = &cassetteTransport{Transport: client.HTTPClient.Transport}
+	}
+}
+
+// testRand returns the random source of the calling test in cassette mode, so that the random names are the same
+// when the test is recorded and replayed. It returns nil if cassettes are not used.
+func testRand() *rand.Rand {
+	if cassetteMode() == "" {
+		return nil
+	}
+
+	name := callerTestName()
+	if name == "" {
+		return nil
+	}
+
+	testRandsMu.Lock()
+	defer testRandsMu.Unlock()
+
+	r, ok := testRands[name]
+	if !ok {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name))
+		r = rand.New(rand.NewSource(int64(h.Sum64())))
+		testRands[name] = r
+	}
+	return r
+}
+
+// callerTestName returns the name of the test function in the call stack, e.g. "TestAccCBHInstance_basic".
+func callerTestName() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		// the function name is like "github.com/.../acceptance/cbh.TestAccCBHInstance_basic.func1"
+		name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		if parts := strings.Split(name, "."); len(parts) > 1 && testNameRegexp.MatchString(parts[1]) {
+			return parts[0] + "." + parts[1]
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func randStringFromCharSet(strlen int, charSet string) string {
+	r := testRand()
+	if r == nil {
+		return acctest.RandStringFromCharSet(strlen, charSet)
+	}
+
+	result := make([]byte, strlen)
+	for i := range result {
+		result[i] = charSet[r.Intn(len(charSet))]
+	}
+	return string(result)
+}
+
+func randString(strlen int) string {
+	return randStringFromCharSet(strlen, acctest.CharSetAlphaNum)
+}
+
+func randIntRange(minVal, maxVal int) int {
+	r := testRand()
+	if r == nil {
+		return acctest.RandIntRange(minVal, maxVal)
+	}
+	return r.Intn(maxVal-minVal) + minVal
+}
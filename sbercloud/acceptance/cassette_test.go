@@ -0,0 +1,201 @@
+package acceptance
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+const (
+	testCassetteProjectID = "0b8f2c5e0e504b8a9a3c1f7d6e5a4b3c"
+	testCassetteDomainID  = "4c9d3e2f1a0b4c5d8e7f6a5b4c3d2e1f"
+	testCassetteAccessKey = "TESTACCESSKEY1234567"
+)
+
+func newTestCassetteConfig() *config.Config {
+	return &config.Config{
+		AccessKey: testCassetteAccessKey,
+		SecretKey: "TEST_SECRET_KEY",
+		DomainID:  testCassetteDomainID,
+		RegionProjectIDMap: map[string]string{
+			"ru-moscow-1": testCassetteProjectID,
+		},
+		RPLock: new(sync.Mutex),
+		HwClient: &golangsdk.ProviderClient{
+			HTTPClient: http.Client{Transport: http.DefaultTransport},
+		},
+	}
+}
+
+func doCassetteRequest(t *testing.T, client *http.Client, method, url, body string) (int, string) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "SDK-HMAC-SHA256 Access="+testCassetteAccessKey+", Signature=abc")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error for %s %s: %s", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SBC_ACC_CASSETTE_DIR", dir)
+
+	var gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Subject-Token", "secret-token")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"instance":{"id":"instance-id","project_id":"` + testCassetteProjectID + `"}}`))
+			return
+		}
+		if atomic.AddInt32(&gets, 1) == 1 {
+			_, _ = w.Write([]byte(`{"instance":{"id":"instance-id","status":"BUILD"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"instance":{"id":"instance-id","status":"ACTIVE"}}`))
+	}))
+
+	cfg := newTestCassetteConfig()
+	installCassetteTransport(cfg)
+	client := &cfg.HwClient.HTTPClient
+	if _, ok := client.Transport.(*cassetteTransport); !ok {
+		t.Fatalf("expected the cassette transport to be installed, got %T", client.Transport)
+	}
+
+	instancesURL := server.URL + "/v3/" + testCassetteProjectID + "/instances"
+	t.Setenv("SBC_ACC_CASSETTE_MODE", cassetteModeRecord)
+	t.Run("TestAccRecord", func(t *testing.T) {
+		useCassette(t)
+
+		doCassetteRequest(t, client, http.MethodPost, instancesURL, `{"name":"test","password":"Recorded@123"}`)
+		doCassetteRequest(t, client, http.MethodGet, instancesURL+"/instance-id", "")
+		doCassetteRequest(t, client, http.MethodGet, instancesURL+"/instance-id", "")
+	})
+	server.Close()
+
+	recorded := filepath.Join(dir, "TestCassette_RecordAndReplay_TestAccRecord.json")
+	data, err := os.ReadFile(recorded)
+	if err != nil {
+		t.Fatalf("error reading the recorded cassette: %s", err)
+	}
+	for _, secret := range []string{testCassetteProjectID, testCassetteAccessKey, "Recorded@123", "secret-token", "Signature"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %q to be scrubbed from the cassette, got %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), fakeCassetteID("ru-moscow-1")) {
+		t.Errorf("expected the project ID to be replaced with the fake one, got %s", data)
+	}
+
+	// the replayed test runs against the fake project ID of the replay identity server
+	replayed := filepath.Join(dir, "TestCassette_RecordAndReplay_TestAccReplay.json")
+	if err := os.Rename(recorded, replayed); err != nil {
+		t.Fatal(err)
+	}
+	instancesURL = server.URL + "/v3/" + fakeCassetteID("ru-moscow-1") + "/instances"
+
+	t.Setenv("SBC_ACC_CASSETTE_MODE", cassetteModeReplay)
+	t.Run("TestAccReplay", func(t *testing.T) {
+		useCassette(t)
+
+		status, body := doCassetteRequest(t, client, http.MethodPost, instancesURL, `{"password":"Other@123","name":"test"}`)
+		if status != http.StatusAccepted || !strings.Contains(body, "instance-id") {
+			t.Errorf("unexpected replayed response: %d %s", status, body)
+		}
+
+		for _, expected := range []string{"BUILD", "ACTIVE", "ACTIVE"} {
+			_, body = doCassetteRequest(t, client, http.MethodGet, instancesURL+"/instance-id", "")
+			if !strings.Contains(body, expected) {
+				t.Errorf("expected the replayed status %s, got %s", expected, body)
+			}
+		}
+
+		req, _ := http.NewRequest(http.MethodDelete, instancesURL+"/instance-id", nil)
+		if _, err := client.Do(req); err == nil || !strings.Contains(err.Error(), "no interaction recorded") {
+			t.Errorf("expected an error for the request which was not recorded, got %v", err)
+		}
+	})
+}
+
+func TestCassette_RandomNames(t *testing.T) {
+	t.Setenv("SBC_ACC_CASSETTE_MODE", cassetteModeReplay)
+
+	if name := callerTestName(); name != "acceptance.TestCassette_RandomNames" {
+		t.Fatalf("unexpected test name %q", name)
+	}
+
+	first := []string{RandomAccResourceName(), RandomAccResourceNameWithDash(), RandomCidr()}
+
+	// the names of a test are generated again from the same seed when the test is replayed
+	testRandsMu.Lock()
+	delete(testRands, callerTestName())
+	testRandsMu.Unlock()
+
+	second := []string{RandomAccResourceName(), RandomAccResourceNameWithDash(), RandomCidr()}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("expected the random value %d to be the same, got %q and %q", i, first[i], second[i])
+		}
+	}
+	if first[0] == RandomAccResourceName() {
+		t.Errorf("expected the following names of the test to be different")
+	}
+}
+
+func TestCassette_ReplayProviderConfigure(t *testing.T) {
+	t.Setenv("SBC_ACC_CASSETTE_MODE", cassetteModeReplay)
+	t.Setenv("SBC_ACCESS_KEY", "")
+	t.Setenv("SBC_SECRET_KEY", "")
+
+	d := schema.TestResourceDataRaw(t, TestAccProvider.Schema, map[string]interface{}{
+		"region":      "ru-moscow-1",
+		"max_retries": 0,
+	})
+	meta, diags := TestAccProvider.ConfigureContextFunc(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	cfg := meta.(*config.Config)
+	if projectID := cfg.RegionProjectIDMap["ru-moscow-1"]; projectID != fakeCassetteID("ru-moscow-1") {
+		t.Errorf("expected the fake project ID, got %q", projectID)
+	}
+	if cfg.DomainID != fakeCassetteID("domain") {
+		t.Errorf("expected the fake domain ID, got %q", cfg.DomainID)
+	}
+	for name, client := range map[string]*golangsdk.ProviderClient{"HwClient": cfg.HwClient, "DomainClient": cfg.DomainClient} {
+		if _, ok := client.HTTPClient.Transport.(*cassetteTransport); !ok {
+			t.Errorf("expected %s to use the cassette transport, got %T", name, client.HTTPClient.Transport)
+		}
+	}
+}
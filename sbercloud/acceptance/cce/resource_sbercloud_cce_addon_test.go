@@ -262,7 +262,6 @@ resource "sbercloud_cce_addon" "test" {
 //	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils/fmtp"
 //	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
 //
-//	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 //	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 //	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 //
@@ -273,7 +272,7 @@ resource "sbercloud_cce_addon" "test" {
 //func TestAccCCEAddonV3_basic(t *testing.T) {
 //	var addon addons.Addon
 //
-//	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+//	rName := acceptance.RandomAccResourceNameWithDash()
 //	resourceName := "sbercloud_cce_addon.test"
 //	clusterName := "sbercloud_cce_cluster.test"
 //
@@ -302,7 +301,7 @@ resource "sbercloud_cce_addon" "test" {
 //func TestAccCCEAddonV3_values(t *testing.T) {
 //	var addon addons.Addon
 //
-//	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+//	rName := acceptance.RandomAccResourceNameWithDash()
 //	resourceName := "sbercloud_cce_addon.test"
 //	clusterName := "sbercloud_cce_cluster.test"
 //
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
@@ -16,7 +15,7 @@ import (
 func TestAccCluster_basic(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -65,7 +64,7 @@ func TestAccCluster_basic(t *testing.T) {
 func TestAccCluster_prePaid(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -101,7 +100,7 @@ func TestAccCluster_prePaid(t *testing.T) {
 func TestAccCluster_withEip(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -138,7 +137,7 @@ func TestAccCluster_withEip(t *testing.T) {
 func TestAccCluster_withEpsId(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -170,7 +169,7 @@ func TestAccCluster_withEpsId(t *testing.T) {
 func TestAccCluster_turbo(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -202,7 +201,7 @@ func TestAccCluster_turbo(t *testing.T) {
 func TestAccCluster_hibernate(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -241,7 +240,7 @@ func TestAccCluster_hibernate(t *testing.T) {
 func TestAccCluster_multiContainerNetworkCidrs(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -284,7 +283,7 @@ func TestAccCluster_multiContainerNetworkCidrs(t *testing.T) {
 func TestAccCluster_secGroup(t *testing.T) {
 	var cluster clusters.Clusters
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_cce_cluster.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -5,7 +5,6 @@ import (
 	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
@@ -31,7 +30,7 @@ func getNamespaceResourceFunc(conf *config.Config, state *terraform.ResourceStat
 func TestAccCCENamespaceV1_basic(t *testing.T) {
 	var namespace namespaces.Namespace
 	resourceName := "sbercloud_cce_namespace.test"
-	randName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	randName := acceptance.RandomAccResourceNameWithDash()
 
 	rc := acceptance.InitResourceCheck(
 		resourceName,
@@ -69,7 +68,7 @@ func TestAccCCENamespaceV1_basic(t *testing.T) {
 func TestAccCCENamespaceV1_generateName(t *testing.T) {
 	var namespace namespaces.Namespace
 	resourceName := "sbercloud_cce_namespace.test"
-	randName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	randName := acceptance.RandomAccResourceNameWithDash()
 
 	rc := acceptance.InitResourceCheck(
 		resourceName,
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
@@ -30,7 +29,7 @@ func getPvcResourceFunc(conf *config.Config, state *terraform.ResourceState) (in
 func TestAccCCEPersistentVolumeClaimsV1_basic(t *testing.T) {
 	var pvc persistentvolumeclaims.PersistentVolumeClaim
 	resourceName := "sbercloud_cce_pvc.test"
-	randName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	randName := acceptance.RandomAccResourceNameWithDash()
 
 	rc := acceptance.InitResourceCheck(
 		resourceName,
@@ -72,7 +71,7 @@ func TestAccCCEPersistentVolumeClaimsV1_basic(t *testing.T) {
 func TestAccCCEPersistentVolumeClaimsV1_obs(t *testing.T) {
 	var pvc persistentvolumeclaims.PersistentVolumeClaim
 	resourceName := "sbercloud_cce_pvc.test"
-	randName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	randName := acceptance.RandomAccResourceNameWithDash()
 
 	rc := acceptance.InitResourceCheck(
 		resourceName,
@@ -105,7 +104,7 @@ func TestAccCCEPersistentVolumeClaimsV1_obs(t *testing.T) {
 func TestAccCCEPersistentVolumeClaimsV1_sfs(t *testing.T) {
 	var pvc persistentvolumeclaims.PersistentVolumeClaim
 	resourceName := "sbercloud_cce_pvc.test"
-	randName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	randName := acceptance.RandomAccResourceNameWithDash()
 
 	rc := acceptance.InitResourceCheck(
 		resourceName,
@@ -7,13 +7,12 @@ import (
 	"testing"
 
 	"github.com/hashicorp/go-uuid"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccDataZones_basic(t *testing.T) {
 	var (
-		name  = fmt.Sprintf("acpttest-zone-%s.com.", acctest.RandString(5))
+		name  = fmt.Sprintf("%s.com.", acceptance.RandomAccResourceNameWithDash())
 		rName = "sbercloud_dns_zone.test.0"
 
 		all           = "data.sbercloud_dns_zones.test"
@@ -362,7 +361,7 @@ output "router_id_not_found_validation_pass" {
 
 func TestAccDataZones_public(t *testing.T) {
 	var (
-		name = fmt.Sprintf("acpttest-zone-%s.com.", acctest.RandString(5))
+		name = fmt.Sprintf("%s.com.", acceptance.RandomAccResourceNameWithDash())
 
 		rName = "sbercloud_dns_zone.test.0"
 
@@ -0,0 +1,43 @@
+package ecs
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
+)
+
+func TestAccComputeInstanceRemoteConsoleDataSource_basic(t *testing.T) {
+	rName := acceptance.RandomAccResourceName()
+	dataSourceName := "data.sbercloud_compute_instance_remote_console.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstanceRemoteConsoleDataSource_basic(rName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(dataSourceName, "id", "sbercloud_compute_instance.test", "id"),
+					resource.TestCheckResourceAttr(dataSourceName, "protocol", "vnc"),
+					resource.TestCheckResourceAttr(dataSourceName, "type", "novnc"),
+					resource.TestMatchResourceAttr(dataSourceName, "url", regexp.MustCompile(`^https://`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccComputeInstanceRemoteConsoleDataSource_basic(rName string) string {
+	return fmt.Sprintf(`
+%s
+
+data "sbercloud_compute_instance_remote_console" "test" {
+  instance_id = sbercloud_compute_instance.test.id
+}
+`, testAccComputeInstance_basic(rName))
+}
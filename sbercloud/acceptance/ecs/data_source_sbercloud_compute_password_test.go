@@ -0,0 +1,42 @@
+package ecs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
+)
+
+func TestAccComputePasswordDataSource_basic(t *testing.T) {
+	rName := acceptance.RandomAccResourceName()
+	dataSourceName := "data.sbercloud_compute_password.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputePasswordDataSource_basic(rName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(dataSourceName, "id", "sbercloud_compute_instance.test", "id"),
+					// the password of a Linux instance is not generated by Cloudbase-Init
+					resource.TestCheckResourceAttr(dataSourceName, "encrypted_password", ""),
+					resource.TestCheckResourceAttr(dataSourceName, "password", ""),
+				),
+			},
+		},
+	})
+}
+
+func testAccComputePasswordDataSource_basic(rName string) string {
+	return fmt.Sprintf(`
+%s
+
+data "sbercloud_compute_password" "test" {
+  instance_id = sbercloud_compute_instance.test.id
+}
+`, testAccComputeInstance_basic(rName))
+}
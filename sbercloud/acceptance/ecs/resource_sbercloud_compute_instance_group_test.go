@@ -0,0 +1,133 @@
+package ecs
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+
+	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
+)
+
+func TestAccComputeInstanceGroup_basic(t *testing.T) {
+	rName := acceptance.RandomAccResourceName()
+	resourceName := "sbercloud_compute_instance_group.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstanceGroup_basic(rName, 3),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceGroupExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "instance_count", "3"),
+					resource.TestCheckResourceAttr(resourceName, "instance_ids.#", "3"),
+					resource.TestCheckResourceAttr(resourceName, "instances.#", "3"),
+					resource.TestCheckResourceAttr(resourceName, "instances.0.status", "ACTIVE"),
+					resource.TestCheckResourceAttrSet(resourceName, "instances.0.name"),
+					resource.TestCheckResourceAttrSet(resourceName, "instances.0.access_ip_v4"),
+				),
+			},
+			{
+				Config: testAccComputeInstanceGroup_basic(rName, 5),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceGroupExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "instance_count", "5"),
+					resource.TestCheckResourceAttr(resourceName, "instance_ids.#", "5"),
+					resource.TestCheckResourceAttr(resourceName, "instances.#", "5"),
+				),
+			},
+			{
+				Config: testAccComputeInstanceGroup_basic(rName, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceGroupExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "instance_count", "1"),
+					resource.TestCheckResourceAttr(resourceName, "instance_ids.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "instances.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckComputeInstanceGroupDestroy(s *terraform.State) error {
+	cfg := acceptance.TestAccProvider.Meta().(*config.Config)
+	computeClient, err := cfg.ComputeV1Client(acceptance.SBC_REGION_NAME)
+	if err != nil {
+		return fmt.Errorf("error creating compute client: %s", err)
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "sbercloud_compute_instance_group" {
+			continue
+		}
+
+		count, _ := strconv.Atoi(rs.Primary.Attributes["instance_ids.#"])
+		for i := 0; i < count; i++ {
+			server, err := cloudservers.Get(computeClient, rs.Primary.Attributes[fmt.Sprintf("instance_ids.%d", i)]).Extract()
+			if err == nil && server.Status != "DELETED" {
+				return fmt.Errorf("instance (%s) of the group still exists", server.ID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckComputeInstanceGroupExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		cfg := acceptance.TestAccProvider.Meta().(*config.Config)
+		computeClient, err := cfg.ComputeV1Client(acceptance.SBC_REGION_NAME)
+		if err != nil {
+			return fmt.Errorf("error creating compute client: %s", err)
+		}
+
+		count, _ := strconv.Atoi(rs.Primary.Attributes["instance_ids.#"])
+		for i := 0; i < count; i++ {
+			id := rs.Primary.Attributes[fmt.Sprintf("instance_ids.%d", i)]
+			if _, err := cloudservers.Get(computeClient, id).Extract(); err != nil {
+				return fmt.Errorf("instance (%s) of the group not found: %s", id, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func testAccComputeInstanceGroup_basic(rName string, count int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "sbercloud_compute_instance_group" "test" {
+  name               = "%s"
+  instance_count     = %d
+  batch_size         = 2
+  image_id           = data.sbercloud_images_image.test.id
+  flavor_id          = data.sbercloud_compute_flavors.test.ids[0]
+  security_group_ids = [data.sbercloud_networking_secgroup.test.id]
+  availability_zone  = data.sbercloud_availability_zones.test.names[0]
+
+  network {
+    uuid = data.sbercloud_vpc_subnet.test.id
+  }
+}
+`, testAccCompute_data, rName, count)
+}
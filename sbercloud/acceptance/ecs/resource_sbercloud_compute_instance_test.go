@@ -76,7 +76,7 @@ func TestAccComputeInstance_basic(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 				ImportStateVerifyIgnore: []string{
-					"stop_before_destroy", "delete_eip_on_termination", "data_disks", "metadata", "user_data",
+					"stop_before_destroy", "delete_eip_on_termination", "metadata",
 				},
 			},
 		},
@@ -226,6 +226,233 @@ func TestAccComputeInstance_withEPS(t *testing.T) {
 	})
 }
 
+func TestAccComputeInstance_imageRebuild(t *testing.T) {
+	var instance, rebuilt cloudservers.CloudServer
+
+	rName := acceptance.RandomAccResourceName()
+	resourceName := "sbercloud_compute_instance.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_imageRebuild(rName, "Ubuntu 18.04 server 64bit"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "image_update_policy", "rebuild"),
+					resource.TestCheckResourceAttr(resourceName, "image_name", "Ubuntu 18.04 server 64bit"),
+					resource.TestCheckResourceAttr(resourceName, "status", "ACTIVE"),
+				),
+			},
+			{
+				Config: testAccComputeInstance_imageRebuild(rName, "Ubuntu 20.04 server 64bit"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &rebuilt),
+					resource.TestCheckResourceAttr(resourceName, "image_name", "Ubuntu 20.04 server 64bit"),
+					resource.TestCheckResourceAttr(resourceName, "status", "ACTIVE"),
+					resource.TestCheckResourceAttr(resourceName, "volume_attached.#", "2"),
+					func(_ *terraform.State) error {
+						if rebuilt.ID != instance.ID {
+							return fmt.Errorf("the instance was replaced: %s -> %s", instance.ID, rebuilt.ID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccComputeInstance_dataDisks(t *testing.T) {
+	var instance, updated cloudservers.CloudServer
+
+	rName := acceptance.RandomAccResourceName()
+	resourceName := "sbercloud_compute_instance.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_dataDisks(rName, `
+  data_disks {
+    type = "SAS"
+    size = 10
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "data_disks.#", "1"),
+					resource.TestCheckResourceAttrSet(resourceName, "data_disks.0.id"),
+					resource.TestCheckResourceAttr(resourceName, "volume_attached.#", "2"),
+				),
+			},
+			{
+				Config: testAccComputeInstance_dataDisks(rName, `
+  data_disks {
+    type = "SAS"
+    size = 20
+  }
+  data_disks {
+    type = "SSD"
+    size = 10
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &updated),
+					resource.TestCheckResourceAttr(resourceName, "data_disks.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "data_disks.0.size", "20"),
+					resource.TestCheckResourceAttrSet(resourceName, "data_disks.1.id"),
+					resource.TestCheckResourceAttr(resourceName, "volume_attached.#", "3"),
+					func(_ *terraform.State) error {
+						if updated.ID != instance.ID {
+							return fmt.Errorf("the instance was replaced: %s -> %s", instance.ID, updated.ID)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"delete_disks_on_termination",
+				},
+			},
+			{
+				Config: testAccComputeInstance_dataDisks(rName, `
+  data_disks {
+    type = "SAS"
+    size = 20
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &updated),
+					resource.TestCheckResourceAttr(resourceName, "data_disks.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "volume_attached.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccComputeInstance_networks(t *testing.T) {
+	var instance, updated cloudservers.CloudServer
+
+	rName := acceptance.RandomAccResourceName()
+	resourceName := "sbercloud_compute_instance.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_networks(rName, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "network.#", "1"),
+				),
+			},
+			{
+				Config: testAccComputeInstance_networks(rName, `
+  network {
+    uuid               = data.sbercloud_vpc_subnet.test.id
+    security_group_ids = [sbercloud_networking_secgroup.test.id]
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &updated),
+					resource.TestCheckResourceAttr(resourceName, "network.#", "2"),
+					resource.TestCheckResourceAttrSet(resourceName, "network.1.port"),
+					resource.TestCheckResourceAttrSet(resourceName, "network.1.fixed_ip_v4"),
+					resource.TestCheckResourceAttrPair(resourceName, "network.1.security_group_ids.0",
+						"sbercloud_networking_secgroup.test", "id"),
+					func(_ *terraform.State) error {
+						if updated.ID != instance.ID {
+							return fmt.Errorf("the instance was replaced: %s -> %s", instance.ID, updated.ID)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccComputeInstance_networks(rName, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &updated),
+					resource.TestCheckResourceAttr(resourceName, "network.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccComputeInstance_rootVolumeReplacement(t *testing.T) {
+	var instance, updated cloudservers.CloudServer
+
+	rName := acceptance.RandomAccResourceName()
+	resourceName := "sbercloud_compute_instance.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_rootVolumeReplacement(rName, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "previous_system_disk_id", ""),
+				),
+			},
+			{
+				Config: testAccComputeInstance_rootVolumeReplacement(rName, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &updated),
+					resource.TestCheckResourceAttr(resourceName, "status", "ACTIVE"),
+					resource.TestCheckResourceAttrSet(resourceName, "previous_system_disk_id"),
+					resource.TestCheckResourceAttrSet(resourceName, "system_disk_id"),
+					func(s *terraform.State) error {
+						if updated.ID != instance.ID {
+							return fmt.Errorf("the instance was replaced: %s -> %s", instance.ID, updated.ID)
+						}
+						attrs := s.RootModule().Resources[resourceName].Primary.Attributes
+						if attrs["system_disk_id"] == attrs["previous_system_disk_id"] {
+							return fmt.Errorf("the system disk was not replaced")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccComputeInstance_destroyPolicy(t *testing.T) {
+	var instance cloudservers.CloudServer
+
+	rName := acceptance.RandomAccResourceName()
+	resourceName := "sbercloud_compute_instance.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckComputeInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_destroyPolicy(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "destroy_policy.0.stop_type", "SOFT"),
+					resource.TestCheckResourceAttr(resourceName, "destroy_policy.0.grace_period", "60"),
+					resource.TestCheckResourceAttrPair(resourceName, "destroy_policy.0.vault_id",
+						"sbercloud_cbr_vault.test", "id"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckComputeInstanceDestroy(s *terraform.State) error {
 	cfg := acceptance.TestAccProvider.Meta().(*config.Config)
 	computeClient, err := cfg.ComputeV1Client(acceptance.SBC_REGION_NAME)
@@ -475,3 +702,149 @@ resource "sbercloud_compute_instance" "test" {
 }
 `, testAccCompute_data, rName, epsID)
 }
+
+func testAccComputeInstance_imageRebuild(rName, imageName string) string {
+	return fmt.Sprintf(`
+%s
+
+data "sbercloud_images_image" "rebuild" {
+  name        = "%s"
+  most_recent = true
+}
+
+resource "sbercloud_compute_instance" "test" {
+  name                = "%s"
+  image_id            = data.sbercloud_images_image.rebuild.id
+  image_update_policy = "rebuild"
+  flavor_id           = data.sbercloud_compute_flavors.test.ids[0]
+  security_group_ids  = [data.sbercloud_networking_secgroup.test.id]
+  availability_zone   = data.sbercloud_availability_zones.test.names[0]
+  admin_pass          = "Test@123456"
+  system_disk_type    = "SAS"
+
+  user_data = <<EOF
+#! /bin/bash
+echo user_test > /home/user.txt
+EOF
+
+  network {
+    uuid = data.sbercloud_vpc_subnet.test.id
+  }
+
+  data_disks {
+    type = "SAS"
+    size = "10"
+  }
+}
+`, testAccCompute_data, imageName, rName)
+}
+
+func testAccComputeInstance_dataDisks(rName, dataDisks string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "sbercloud_compute_instance" "test" {
+  name               = "%s"
+  image_id           = data.sbercloud_images_image.test.id
+  flavor_id          = data.sbercloud_compute_flavors.test.ids[0]
+  security_group_ids = [data.sbercloud_networking_secgroup.test.id]
+  availability_zone  = data.sbercloud_availability_zones.test.names[0]
+  system_disk_type   = "SAS"
+  system_disk_size   = 40
+
+  delete_disks_on_termination = true
+%s
+
+  network {
+    uuid = data.sbercloud_vpc_subnet.test.id
+  }
+}
+`, testAccCompute_data, rName, dataDisks)
+}
+
+func testAccComputeInstance_networks(rName, networks string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "sbercloud_networking_secgroup" "test" {
+  name = "%s"
+}
+
+resource "sbercloud_compute_instance" "test" {
+  name               = "%s"
+  image_id           = data.sbercloud_images_image.test.id
+  flavor_id          = data.sbercloud_compute_flavors.test.ids[0]
+  security_group_ids = [data.sbercloud_networking_secgroup.test.id]
+  availability_zone  = data.sbercloud_availability_zones.test.names[0]
+  system_disk_type   = "SAS"
+
+  network {
+    uuid = data.sbercloud_vpc_subnet.test.id
+  }
+%s
+}
+`, testAccCompute_data, rName, rName, networks)
+}
+
+func testAccComputeInstance_rootVolumeReplacement(rName, trigger string) string {
+	replacement := ""
+	if trigger != "" {
+		replacement = fmt.Sprintf(`
+  root_volume_replacement {
+    image_id = data.sbercloud_images_image.test.id
+    trigger  = "%s"
+  }`, trigger)
+	}
+
+	return fmt.Sprintf(`
+%s
+
+resource "sbercloud_compute_instance" "test" {
+  name               = "%s"
+  image_id           = data.sbercloud_images_image.test.id
+  flavor_id          = data.sbercloud_compute_flavors.test.ids[0]
+  security_group_ids = [data.sbercloud_networking_secgroup.test.id]
+  availability_zone  = data.sbercloud_availability_zones.test.names[0]
+  system_disk_type   = "SAS"
+  system_disk_size   = 40
+%s
+
+  network {
+    uuid = data.sbercloud_vpc_subnet.test.id
+  }
+}
+`, testAccCompute_data, rName, replacement)
+}
+
+func testAccComputeInstance_destroyPolicy(rName string) string {
+	return fmt.Sprintf(`
+%[1]s
+
+resource "sbercloud_cbr_vault" "test" {
+  name            = "%[2]s"
+  type            = "server"
+  protection_type = "backup"
+  size            = 100
+}
+
+resource "sbercloud_compute_instance" "test" {
+  name               = "%[2]s"
+  image_id           = data.sbercloud_images_image.test.id
+  flavor_id          = data.sbercloud_compute_flavors.test.ids[0]
+  security_group_ids = [data.sbercloud_networking_secgroup.test.id]
+  availability_zone  = data.sbercloud_availability_zones.test.names[0]
+  system_disk_type   = "SAS"
+  system_disk_size   = 40
+
+  destroy_policy {
+    stop_type    = "SOFT"
+    grace_period = 60
+    vault_id     = sbercloud_cbr_vault.test.id
+  }
+
+  network {
+    uuid = data.sbercloud_vpc_subnet.test.id
+  }
+}
+`, testAccCompute_data, rName)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -12,7 +11,7 @@ import (
 )
 
 func TestAccDataSourceELbCertificateV3_basic(t *testing.T) {
-	name := fmt.Sprintf("cert-%s", acctest.RandString(6))
+	name := acceptance.RandomAccResourceNameWithDash()
 	dataSourceName := "data.sbercloud_elb_certificate.cert_1"
 
 	resource.Test(t, resource.TestCase{
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -16,7 +15,7 @@ import (
 
 func TestAccElbV3Certificate_basic(t *testing.T) {
 	var c certificates.Certificate
-	name := fmt.Sprintf("tf-cert-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_certificate.server"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -54,7 +53,7 @@ func TestAccElbV3Certificate_basic(t *testing.T) {
 
 func TestAccElbV3Certificate_client(t *testing.T) {
 	var c certificates.Certificate
-	name := fmt.Sprintf("tf-cert-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_certificate.client"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -76,7 +75,7 @@ func TestAccElbV3Certificate_client(t *testing.T) {
 
 func TestAccElbV3Certificate_withEpsId(t *testing.T) {
 	var c certificates.Certificate
-	name := fmt.Sprintf("tf-cert-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_certificate.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -16,7 +15,7 @@ import (
 
 func TestAccElbV3IpGroup_basic(t *testing.T) {
 	var c ipgroups.IpGroup
-	name := fmt.Sprintf("tf-acc-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_ipgroup.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -52,7 +51,7 @@ func TestAccElbV3IpGroup_basic(t *testing.T) {
 
 func TestAccElbV3IpGroup_withEpsId(t *testing.T) {
 	var c ipgroups.IpGroup
-	name := fmt.Sprintf("tf-acc-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_ipgroup.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -5,7 +5,6 @@ import (
 	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -17,7 +16,7 @@ import (
 
 func TestAccElbV3L7Policy_basic(t *testing.T) {
 	var l7Policy l7policies.L7Policy
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_l7policy.test"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -24,7 +23,7 @@ func getELBMonitorResourceFunc(cfg *config.Config, state *terraform.ResourceStat
 
 func TestAccElbV3Monitor_basic(t *testing.T) {
 	var monitor monitors.Monitor
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_elb_monitor.monitor_1"
 
 	rc := acceptance.InitResourceCheck(
@@ -4,13 +4,12 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
 )
 
 func TestAccDataSourceLBCertificateV2_basic(t *testing.T) {
-	name := fmt.Sprintf("cert-%s", acctest.RandString(6))
+	name := acceptance.RandomAccResourceNameWithDash()
 	dataSourceName := "data.sbercloud_lb_certificate.cert_1"
 	dc := acceptance.InitDataSourceCheck(dataSourceName)
 
@@ -5,7 +5,6 @@ import (
 	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -15,8 +14,8 @@ import (
 
 func TestAccRdsConfigurationV3_basic(t *testing.T) {
 	var config configurations.Configuration
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
-	updateName := fmt.Sprintf("tf-acc-test-%s-update", acctest.RandString(5))
+	rName := acceptance.RandomAccResourceNameWithDash()
+	updateName := acceptance.RandomAccResourceNameWithDash() + "-update"
 	resourceName := "sbercloud_rds_parametergroup.pg_1"
 
 	resource.ParallelTest(t, resource.TestCase{
@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	"github.com/chnsz/golangsdk"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
@@ -17,7 +16,7 @@ import (
 
 func TestAccRdsInstanceV3_basic(t *testing.T) {
 	var instance instances.RdsInstanceResponse
-	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceType := "sbercloud_rds_instance"
 	resourceName := "sbercloud_rds_instance.test"
 
@@ -70,7 +69,7 @@ func TestAccRdsInstanceV3_basic(t *testing.T) {
 
 func TestAccRdsInstanceV3_withEpsId(t *testing.T) {
 	var instance instances.RdsInstanceResponse
-	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceType := "sbercloud_rds_instance"
 	resourceName := "sbercloud_rds_instance.test"
 
@@ -92,7 +91,7 @@ func TestAccRdsInstanceV3_withEpsId(t *testing.T) {
 
 func TestAccRdsInstanceV3_ha(t *testing.T) {
 	var instance instances.RdsInstanceResponse
-	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceType := "sbercloud_rds_instance"
 	resourceName := "sbercloud_rds_instance.test"
 
@@ -120,6 +119,41 @@ func TestAccRdsInstanceV3_ha(t *testing.T) {
 	})
 }
 
+func TestAccRdsInstanceV3_defaultTags(t *testing.T) {
+	var instance instances.RdsInstanceResponse
+	name := acceptance.RandomAccResourceNameWithDash()
+	resourceType := "sbercloud_rds_instance"
+	resourceName := "sbercloud_rds_instance.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { acceptance.TestAccPreCheck(t) },
+		ProviderFactories: acceptance.TestAccProviderFactories,
+		CheckDestroy:      testAccCheckRdsInstanceV3Destroy(resourceType),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRdsInstanceV3_defaultTags(name, `foo = "bar"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRdsInstanceV3Exists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "tags.%", "3"),
+					resource.TestCheckResourceAttr(resourceName, "tags.owner", "terraform"),
+					resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+					resource.TestCheckResourceAttr(resourceName, "tags.foo", "bar"),
+				),
+			},
+			{
+				// the resource tag overrides the default tag with the same key
+				Config: testAccRdsInstanceV3_defaultTags(name, `env = "test_updated"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRdsInstanceV3Exists(resourceName, &instance),
+					resource.TestCheckResourceAttr(resourceName, "tags.%", "2"),
+					resource.TestCheckResourceAttr(resourceName, "tags.owner", "terraform"),
+					resource.TestCheckResourceAttr(resourceName, "tags.env", "test_updated"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckRdsInstanceV3Destroy(rsType string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		config := acceptance.TestAccProvider.Meta().(*config.Config)
@@ -741,3 +775,46 @@ resource "sbercloud_rds_instance" "test" {
 }
 `, testAccRdsInstance_sqlserver_msdtcHosts_base(name), name)
 }
+
+func testAccRdsInstanceV3_defaultTags(name, tags string) string {
+	return fmt.Sprintf(`
+provider "sbercloud" {
+  default_tags = {
+    owner = "terraform"
+    env   = "test"
+  }
+
+  ignore_tags = ["cost-center"]
+}
+
+%s
+
+resource "sbercloud_rds_instance" "test" {
+  name              = "%s"
+  flavor            = "rds.pg.x1.large.2"
+  availability_zone = [data.sbercloud_availability_zones.test.names[0]]
+  security_group_id = sbercloud_networking_secgroup.test.id
+  subnet_id         = sbercloud_vpc_subnet.test.id
+  vpc_id            = sbercloud_vpc.test.id
+
+  db {
+    password = "Huangwei!120521"
+    type     = "PostgreSQL"
+    version  = "12"
+    port     = 8635
+  }
+  volume {
+    type = "CLOUDSSD"
+    size = 50
+  }
+  backup_strategy {
+    start_time = "08:00-09:00"
+    keep_days  = 1
+  }
+
+  tags = {
+    %s
+  }
+}
+`, testAccRdsInstanceV3_base(name), name, tags)
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/acceptance"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 
 	"github.com/chnsz/golangsdk/openstack/rds/v3/instances"
@@ -13,7 +12,7 @@ import (
 
 func TestAccRdsReadReplicaInstance_basic(t *testing.T) {
 	var replica instances.RdsInstanceResponse
-	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceType := "sbercloud_rds_read_replica_instance"
 	resourceName := "sbercloud_rds_read_replica_instance.test"
 
@@ -60,7 +59,7 @@ func TestAccRdsReadReplicaInstance_basic(t *testing.T) {
 
 func TestAccRdsReadReplicaInstance_withEpsId(t *testing.T) {
 	var replica instances.RdsInstanceResponse
-	name := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(5))
+	name := acceptance.RandomAccResourceNameWithDash()
 	resourceType := "sbercloud_rds_read_replica_instance"
 	resourceName := "sbercloud_rds_read_replica_instance.test"
 
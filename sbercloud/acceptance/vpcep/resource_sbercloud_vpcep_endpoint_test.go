@@ -2,7 +2,6 @@ package vpcep
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -17,7 +16,7 @@ import (
 func TestAccVPCEndpoint_Basic(t *testing.T) {
 	var endpoint endpoints.Endpoint
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(4))
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_vpcep_endpoint.test"
 	rc := acceptance.InitResourceCheck(
 		resourceName,
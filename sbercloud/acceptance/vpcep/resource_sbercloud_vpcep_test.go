@@ -2,7 +2,6 @@ package vpcep
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -17,7 +16,7 @@ import (
 func TestAccVPCEPService_Basic(t *testing.T) {
 	var service services.Service
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(4)) //acceptance.RandomAccResourceNameWithDash()
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_vpcep_service.test"
 
 	rc := acceptance.InitResourceCheck(
@@ -77,7 +76,7 @@ func TestAccVPCEPService_Basic(t *testing.T) {
 func TestAccVPCEPService_enablePolicy(t *testing.T) {
 	var service services.Service
 
-	rName := fmt.Sprintf("tf-acc-test-%s", acctest.RandString(4)) //acceptance.RandomAccResourceNameWithDash()
+	rName := acceptance.RandomAccResourceNameWithDash()
 	resourceName := "sbercloud_vpcep_service.test"
 
 	rc := acceptance.InitResourceCheck(
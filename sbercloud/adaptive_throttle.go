@@ -0,0 +1,276 @@
+package sbercloud
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// throttledErrorCode is the error code returned by the API gateway when the flow control threshold is reached.
+	throttledErrorCode = "APIGW.0308"
+
+	// defaultAdaptiveRate is the rate which is reduced on the first throttled response from a host without any
+	// configured limit. It matches the limit of 100 requests per second of SberCloud API.
+	defaultAdaptiveRate = 100
+	// minAdaptiveRate is the lowest rate the adaptive throttle reduces to.
+	minAdaptiveRate = 1
+	// adaptiveDecreaseFactor is the factor the rate is multiplied by on every throttled response.
+	adaptiveDecreaseFactor = 0.5
+	// adaptiveRecoveryStep is the part of the ceiling rate added back on every recovery step.
+	adaptiveRecoveryStep = 0.1
+	// adaptiveAdjustInterval is the minimum time between two changes of the rate of a host,
+	// so that a batch of concurrent throttled responses reduces the rate only once.
+	adaptiveAdjustInterval = time.Second
+
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+
+	// maxThrottledBodySize is the maximum size of the response body inspected for the throttling error code.
+	maxThrottledBodySize = 64 * 1024
+)
+
+// AdaptiveThrottle reduces the rate of requests to a service endpoint host when it answers with
+// 429 Too Many Requests or the APIGW.0308 error code, and recovers the rate gradually after that.
+// Throttled idempotent requests are retried with a jittered exponential backoff, honouring Retry-After.
+type AdaptiveThrottle struct {
+	// MaxRetries is the maximum number of times a throttled idempotent request is retried.
+	MaxRetries int
+
+	// BaseDelay and MaxDelay bound the exponential backoff between the retries.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostThrottle
+
+	// now is used to mock the clock in unit tests
+	now func() time.Time
+}
+
+// hostThrottle is the adaptive state of a single endpoint host.
+type hostThrottle struct {
+	// limiter is nil while the host is not throttled
+	limiter     *rate.Limiter
+	ceiling     rate.Limit
+	pausedUntil time.Time
+	lastChange  time.Time
+}
+
+// NewAdaptiveThrottle creates an AdaptiveThrottle which retries throttled idempotent requests up to maxRetries times.
+func NewAdaptiveThrottle(maxRetries int) *AdaptiveThrottle {
+	return &AdaptiveThrottle{
+		MaxRetries: maxRetries,
+		BaseDelay:  defaultRetryBaseDelay,
+		MaxDelay:   defaultRetryMaxDelay,
+		hosts:      make(map[string]*hostThrottle),
+		now:        time.Now,
+	}
+}
+
+func (a *AdaptiveThrottle) host(host string) *hostThrottle {
+	host = strings.ToLower(host)
+	h, ok := a.hosts[host]
+	if !ok {
+		h = &hostThrottle{}
+		a.hosts[host] = h
+	}
+	return h
+}
+
+// Wait blocks until the host can be called: it waits for the end of a Retry-After pause
+// and for a token of the reduced rate, if the host is throttled.
+func (a *AdaptiveThrottle) Wait(ctx context.Context, host string) error {
+	a.mu.Lock()
+	h := a.host(host)
+	pause := h.pausedUntil.Sub(a.now())
+	limiter := h.limiter
+	a.mu.Unlock()
+
+	if pause > 0 {
+		if err := sleepWithContext(ctx, pause); err != nil {
+			return err
+		}
+	}
+	if limiter != nil {
+		return limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// Limit returns the current reduced rate of the host, or rate.Inf if the host is not throttled.
+func (a *AdaptiveThrottle) Limit(host string) rate.Limit {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if h := a.host(host); h.limiter != nil {
+		return h.limiter.Limit()
+	}
+	return rate.Inf
+}
+
+// OnThrottled reduces the rate of the host, starting from ceiling on the first throttled response,
+// and pauses the requests to the host for retryAfter, at most for MaxDelay.
+func (a *AdaptiveThrottle) OnThrottled(host string, ceiling rate.Limit, retryAfter time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	h := a.host(host)
+	retryAfter = a.capRetryAfter(retryAfter)
+	if retryAfter > 0 && now.Add(retryAfter).After(h.pausedUntil) {
+		h.pausedUntil = now.Add(retryAfter)
+	}
+
+	if h.limiter == nil {
+		h.ceiling = ceiling
+		h.limiter = rate.NewLimiter(maxLimit(ceiling*adaptiveDecreaseFactor, minAdaptiveRate), 1)
+		h.lastChange = now
+	} else if now.Sub(h.lastChange) >= adaptiveAdjustInterval {
+		h.limiter.SetLimitAt(now, maxLimit(h.limiter.Limit()*adaptiveDecreaseFactor, minAdaptiveRate))
+		h.lastChange = now
+	}
+
+	log.Printf("[WARN] %s is throttled, reducing the rate to %.2f requests per second", host, h.limiter.Limit())
+}
+
+// OnSuccess increases the rate of a throttled host by a step of its ceiling, at most once per adjust interval.
+// The throttle of the host is removed once the rate is back to the ceiling.
+func (a *AdaptiveThrottle) OnSuccess(host string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	h := a.host(host)
+	if h.limiter == nil || now.Sub(h.lastChange) < adaptiveAdjustInterval {
+		return
+	}
+
+	limit := h.limiter.Limit() + h.ceiling*adaptiveRecoveryStep
+	if limit >= h.ceiling {
+		log.Printf("[DEBUG] %s is recovered from throttling", host)
+		h.limiter = nil
+		return
+	}
+
+	h.limiter.SetLimitAt(now, limit)
+	h.lastChange = now
+}
+
+// retryDelay returns the delay before the given retry attempt (starting from 0): a random value between
+// the half and the full exponential backoff, but not less than the Retry-After of the response capped by MaxDelay.
+func (a *AdaptiveThrottle) retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := a.BaseDelay << uint(attempt)
+	if backoff <= 0 || backoff > a.MaxDelay {
+		backoff = a.MaxDelay
+	}
+
+	delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	if retryAfter = a.capRetryAfter(retryAfter); retryAfter > delay {
+		delay = retryAfter
+	}
+	return delay
+}
+
+// capRetryAfter bounds the Retry-After of a response by MaxDelay,
+// so that a server can not pause the requests for longer than the maximum backoff.
+func (a *AdaptiveThrottle) capRetryAfter(retryAfter time.Duration) time.Duration {
+	if retryAfter > a.MaxDelay {
+		return a.MaxDelay
+	}
+	return retryAfter
+}
+
+// isThrottledResponse checks whether the response is a flow control rejection.
+// The body of a 4xx response is inspected for the APIGW.0308 error code and restored afterwards.
+func isThrottledResponse(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode < 400 || resp.StatusCode >= 500 || resp.Body == nil {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxThrottledBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	return bytes.Contains(body, []byte(throttledErrorCode))
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// isRetryableRequest checks whether the request is idempotent and its body can be sent again.
+func isRetryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// cloneRequest returns a copy of the request with a fresh body, to be sent again.
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func maxLimit(a, b rate.Limit) rate.Limit {
+	if a > b {
+		return a
+	}
+	return b
+}
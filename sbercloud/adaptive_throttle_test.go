@@ -0,0 +1,331 @@
+package sbercloud
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newTestAdaptiveThrottle returns a throttle with short delays, to keep the tests fast.
+func newTestAdaptiveThrottle(maxRetries int) *AdaptiveThrottle {
+	throttle := NewAdaptiveThrottle(maxRetries)
+	throttle.BaseDelay = 10 * time.Millisecond
+	throttle.MaxDelay = 50 * time.Millisecond
+	return throttle
+}
+
+// throttlingServer answers with the given throttled responses first and with 200 OK afterwards.
+func throttlingServer(t *testing.T, throttled int, respond func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= int32(throttled) {
+			respond(w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func tooManyRequests(retryAfter string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+}
+
+func TestAdaptiveThrottle_RetriesIdempotentRequest(t *testing.T) {
+	server, calls := throttlingServer(t, 2, tooManyRequests(""))
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(3)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("expected 3 calls, got %d", got)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if limit := transport.Throttle.Limit(host); limit != defaultAdaptiveRate*adaptiveDecreaseFactor {
+		t.Errorf("expected the rate of %s to be reduced to %v, got %v", host, defaultAdaptiveRate*adaptiveDecreaseFactor, limit)
+	}
+}
+
+func TestAdaptiveThrottle_RetryAfter(t *testing.T) {
+	server, calls := throttlingServer(t, 1, tooManyRequests("1"))
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(3)
+	transport.Throttle.MaxDelay = 2 * time.Second
+	client := &http.Client{Transport: transport}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected the retry to wait for Retry-After of 1s, took %s", elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestAdaptiveThrottle_NonIdempotentRequest(t *testing.T) {
+	server, calls := throttlingServer(t, 1, tooManyRequests(""))
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(3)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"name":"test"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected a POST request not to be retried, got %d calls", got)
+	}
+}
+
+func TestAdaptiveThrottle_RetriesExhausted(t *testing.T) {
+	server, calls := throttlingServer(t, 10, tooManyRequests(""))
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(2)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("expected 1 call and 2 retries, got %d calls", got)
+	}
+}
+
+func TestAdaptiveThrottle_ErrorCode(t *testing.T) {
+	server, calls := throttlingServer(t, 1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error_code":"APIGW.0308","error_msg":"The throttling threshold has been reached"}`))
+	})
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(3)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestAdaptiveThrottle_ResendsBody(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		count := len(bodies)
+		mu.Unlock()
+
+		if count == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(3)
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"name":"test"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != `{"name":"test"}` {
+			t.Errorf("unexpected body of call %d: %q", i+1, body)
+		}
+	}
+}
+
+func TestAdaptiveThrottle_DecreaseAndRecover(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttle := NewAdaptiveThrottle(3)
+	throttle.now = func() time.Time { return now }
+	host := "ecs.ru-moscow-1.hc.sbercloud.ru"
+
+	if limit := throttle.Limit(host); limit != rate.Inf {
+		t.Fatalf("expected no throttling before the first throttled response, got %v", limit)
+	}
+
+	throttle.OnThrottled(host, 20, 0)
+	if limit := throttle.Limit(host); limit != 10 {
+		t.Errorf("expected the rate to be halved to 10, got %v", limit)
+	}
+
+	// concurrent throttled responses reduce the rate only once per adjust interval
+	throttle.OnThrottled(host, 20, 0)
+	if limit := throttle.Limit(host); limit != 10 {
+		t.Errorf("expected the rate to stay at 10, got %v", limit)
+	}
+
+	now = now.Add(adaptiveAdjustInterval)
+	throttle.OnThrottled(host, 20, 0)
+	if limit := throttle.Limit(host); limit != 5 {
+		t.Errorf("expected the rate to be halved to 5, got %v", limit)
+	}
+
+	// the rate recovers by 10% of the ceiling per adjust interval
+	throttle.OnSuccess(host)
+	if limit := throttle.Limit(host); limit != 5 {
+		t.Errorf("expected the rate not to recover within the adjust interval, got %v", limit)
+	}
+	for _, expected := range []rate.Limit{7, 9, 11, 13, 15, 17, 19} {
+		now = now.Add(adaptiveAdjustInterval)
+		throttle.OnSuccess(host)
+		if limit := throttle.Limit(host); limit != expected {
+			t.Errorf("expected the rate to recover to %v, got %v", expected, limit)
+		}
+	}
+
+	now = now.Add(adaptiveAdjustInterval)
+	throttle.OnSuccess(host)
+	if limit := throttle.Limit(host); limit != rate.Inf {
+		t.Errorf("expected the throttling to be removed, got %v", limit)
+	}
+}
+
+func TestAdaptiveThrottle_MinRate(t *testing.T) {
+	now := time.Now()
+	throttle := NewAdaptiveThrottle(3)
+	throttle.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		throttle.OnThrottled("vpc", 4, 0)
+		now = now.Add(adaptiveAdjustInterval)
+	}
+	if limit := throttle.Limit("vpc"); limit != minAdaptiveRate {
+		t.Errorf("expected the rate not to fall below %v, got %v", minAdaptiveRate, limit)
+	}
+}
+
+func TestAdaptiveThrottle_RetryDelay(t *testing.T) {
+	throttle := NewAdaptiveThrottle(3)
+	throttle.BaseDelay = 100 * time.Millisecond
+	throttle.MaxDelay = time.Second
+
+	for attempt := 0; attempt < 10; attempt++ {
+		backoff := throttle.BaseDelay << uint(attempt)
+		if backoff > throttle.MaxDelay {
+			backoff = throttle.MaxDelay
+		}
+
+		delay := throttle.retryDelay(attempt, 0)
+		if delay < backoff/2 || delay > backoff {
+			t.Errorf("expected the delay of attempt %d to be between %s and %s, got %s", attempt, backoff/2, backoff, delay)
+		}
+	}
+
+	if delay := throttle.retryDelay(0, 800*time.Millisecond); delay != 800*time.Millisecond {
+		t.Errorf("expected the delay to honour Retry-After of 800ms, got %s", delay)
+	}
+	if delay := throttle.retryDelay(0, time.Hour); delay != throttle.MaxDelay {
+		t.Errorf("expected Retry-After of 1h to be capped by %s, got %s", throttle.MaxDelay, delay)
+	}
+}
+
+func TestAdaptiveThrottle_RetryAfterCapped(t *testing.T) {
+	server, calls := throttlingServer(t, 1, tooManyRequests("3600"))
+	transport := NewRateLimitedTransport(0, nil)
+	transport.Throttle = newTestAdaptiveThrottle(3)
+	client := &http.Client{Transport: transport}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected Retry-After of 1h to be capped by %s, took %s", transport.Throttle.MaxDelay, elapsed)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		" 10 ":                          10 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 00:00:30 GMT": 30 * time.Second,
+		"Sun, 31 Dec 2023 23:59:00 GMT": 0,
+	}
+
+	for header, expected := range testCases {
+		if got := parseRetryAfter(header, now); got != expected {
+			t.Errorf("expected %s for %q, got %s", expected, header, got)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package sbercloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// redactedValue replaces the values of the sensitive fields and headers in the trace
+	redactedValue = "***"
+
+	// maxTraceBodySize is the maximum size of a request or response body written to the trace
+	maxTraceBodySize = 1024 * 1024
+)
+
+var (
+	// sensitiveTraceFields are the sensitive JSON fields, query parameters and headers, normalized by
+	// normalizeTraceKey. The keys which contain any of sensitiveTraceKeyParts are redacted too.
+	sensitiveTraceFields = map[string]bool{
+		"adminpass":       true,
+		"accesskey":       true,
+		"accesskeyid":     true,
+		"secretkey":       true,
+		"secretaccesskey": true,
+		"securitytoken":   true,
+		"privatekey":      true,
+		"userdata":        true,
+		"signature":       true,
+		"cookie":          true,
+		"setcookie":       true,
+	}
+	sensitiveTraceKeyParts = []string{"password", "passwd", "pwd", "secret", "token", "authorization", "credential"}
+
+	// httpTraceFiles keeps the trace file of every path open once, so that all the provider instances
+	// writing to the same file share its lock.
+	httpTraceFiles   = make(map[string]*httpTraceLog)
+	httpTraceFilesMu sync.Mutex
+)
+
+// httpTraceLog writes the trace entries as JSON lines, one entry per line.
+type httpTraceLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *httpTraceLog) write(entry *httpTraceEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err = l.w.Write(append(line, '\n'))
+	return err
+}
+
+// openHTTPTraceFile opens the trace file for appending, creating it if it does not exist.
+func openHTTPTraceFile(path string) (*httpTraceLog, error) {
+	httpTraceFilesMu.Lock()
+	defer httpTraceFilesMu.Unlock()
+
+	if traceLog, ok := httpTraceFiles[path]; ok {
+		return traceLog, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening HTTP trace file %s: %s", path, err)
+	}
+
+	traceLog := &httpTraceLog{w: file}
+	httpTraceFiles[path] = traceLog
+	return traceLog, nil
+}
+
+// httpTraceEntry is a single line of the HTTP trace file.
+type httpTraceEntry struct {
+	Time            string              `json:"time"`
+	DurationMs      int64               `json:"duration_ms"`
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	RequestHeaders  map[string][]string `json:"request_headers,omitempty"`
+	RequestBody     interface{}         `json:"request_body,omitempty"`
+	StatusCode      int                 `json:"status_code,omitempty"`
+	RequestID       string              `json:"request_id,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	ResponseBody    interface{}         `json:"response_body,omitempty"`
+	Error           string              `json:"error,omitempty"`
+}
+
+// HTTPTraceTransport wraps http.RoundTripper and writes every request and response to a trace file,
+// with the sensitive fields and headers redacted.
+type HTTPTraceTransport struct {
+	// Transport is the underlying HTTP transport used to execute requests.
+	// If nil, http.DefaultTransport will be used.
+	Transport http.RoundTripper
+
+	traceLog *httpTraceLog
+}
+
+// NewHTTPTraceTransport creates a transport which writes the trace of every request to the file of path.
+// The file is shared by all the transports created for the same path.
+func NewHTTPTraceTransport(path string, baseTransport http.RoundTripper) (*HTTPTraceTransport, error) {
+	traceLog, err := openHTTPTraceFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseTransport == nil {
+		baseTransport = http.DefaultTransport
+	}
+	return &HTTPTraceTransport{
+		Transport: baseTransport,
+		traceLog:  traceLog,
+	}, nil
+}
+
+// WithTransport returns a copy of the transport which forwards the requests to baseTransport.
+// The copy writes to the same trace file as the original transport.
+func (t *HTTPTraceTransport) WithTransport(baseTransport http.RoundTripper) *HTTPTraceTransport {
+	if baseTransport == nil {
+		baseTransport = http.DefaultTransport
+	}
+
+	return &HTTPTraceTransport{
+		Transport: baseTransport,
+		traceLog:  t.traceLog,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *HTTPTraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	entry := &httpTraceEntry{
+		Time:           start.UTC().Format(time.RFC3339Nano),
+		Method:         req.Method,
+		URL:            redactTraceURL(req.URL),
+		RequestHeaders: redactTraceHeaders(req.Header),
+	}
+
+	if body, err := peekRequestBody(req); err != nil {
+		log.Printf("[WARN] unable to read the request body for HTTP trace: %s", err)
+	} else {
+		entry.RequestBody = redactTraceBody(body, req.Header.Get("Content-Type"))
+	}
+
+	resp, err := t.Transport.RoundTrip(req)
+	entry.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.StatusCode = resp.StatusCode
+		entry.RequestID = resp.Header.Get("X-Request-Id")
+		entry.ResponseHeaders = redactTraceHeaders(resp.Header)
+
+		if body, readErr := peekResponseBody(resp); readErr != nil {
+			log.Printf("[WARN] unable to read the response body for HTTP trace: %s", readErr)
+		} else {
+			entry.ResponseBody = redactTraceBody(body, resp.Header.Get("Content-Type"))
+		}
+	}
+
+	if writeErr := t.traceLog.write(entry); writeErr != nil {
+		log.Printf("[WARN] unable to write HTTP trace: %s", writeErr)
+	}
+	return resp, err
+}
+
+// peekRequestBody returns the body of the request and leaves the request ready to be sent.
+func peekRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(io.LimitReader(body, maxTraceBodySize))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxTraceBodySize))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+	return data, err
+}
+
+// peekResponseBody returns the body of the response and restores it for the caller.
+func peekResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTraceBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return data, err
+}
+
+// normalizeTraceKey lowercases the key and removes the separators, e.g. "admin_pass" and "adminPass" are
+// both normalized to "adminpass".
+func normalizeTraceKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+}
+
+func isSensitiveTraceKey(key string) bool {
+	key = normalizeTraceKey(key)
+	if sensitiveTraceFields[key] {
+		return true
+	}
+	for _, part := range sensitiveTraceKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactTraceHeaders(headers http.Header) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	result := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		if isSensitiveTraceKey(key) {
+			result[key] = []string{redactedValue}
+			continue
+		}
+		result[key] = values
+	}
+	return result
+}
+
+func redactTraceURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+
+	query := redacted.Query()
+	if len(query) == 0 {
+		return redacted.String()
+	}
+	for key := range query {
+		if isSensitiveTraceKey(key) {
+			query.Set(key, redactedValue)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// redactTraceBody returns the JSON body with the values of the sensitive fields redacted.
+// The bodies which are not JSON are not written to the trace, as they can not be redacted reliably.
+func redactTraceBody(body []byte, contentType string) interface{} {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("<%d bytes of %s>", len(body), traceContentType(contentType))
+	}
+	return RedactSensitiveValues(value)
+}
+
+// RedactSensitiveValues replaces the values of the sensitive fields, such as password and admin_pass,
+// in the decoded JSON value with "***". The maps and slices of the value are modified in place.
+func RedactSensitiveValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveTraceKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = RedactSensitiveValues(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = RedactSensitiveValues(item)
+		}
+	}
+	return value
+}
+
+func traceContentType(contentType string) string {
+	if contentType == "" {
+		return "unknown content"
+	}
+	return contentType
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 
+	"github.com/chnsz/golangsdk"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/apig"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/cfw"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/cts"
@@ -26,9 +29,11 @@ import (
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/sfsturbo"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/swr"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/vpn"
+	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/bss"
 	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/cbh"
 	cbr_sbc "github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/cbr"
 	deprecated_sbc "github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/deprecated"
+	ecs_sbc "github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/ecs"
 	ges_sbercloud "github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/ges"
 	lb2 "github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/lb"
 	"github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/rds"
@@ -186,6 +191,20 @@ func Provider() *schema.Provider {
 				RequiredWith: []string{"password", "user_name"},
 			},
 
+			"shared_config_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["shared_config_file"],
+				DefaultFunc: schema.EnvDefaultFunc("SBC_SHARED_CONFIG_FILE", ""),
+			},
+
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["profile"],
+				DefaultFunc: schema.EnvDefaultFunc("SBC_PROFILE", ""),
+			},
+
 			"insecure": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -212,6 +231,37 @@ func Provider() *schema.Provider {
 				Description: descriptions["rate_limit"],
 				DefaultFunc: schema.EnvDefaultFunc("SBC_RATE_LIMIT", 0),
 			},
+			"rate_limits": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: descriptions["rate_limits"],
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"rate_limit_burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  descriptions["rate_limit_burst"],
+				DefaultFunc:  schema.EnvDefaultFunc("SBC_RATE_LIMIT_BURST", 1),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"rate_limit_adaptive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: descriptions["rate_limit_adaptive"],
+				DefaultFunc: schema.EnvDefaultFunc("SBC_RATE_LIMIT_ADAPTIVE", false),
+			},
+			"http_trace_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["http_trace_file"],
+				DefaultFunc: schema.EnvDefaultFunc("SBC_HTTP_TRACE", ""),
+			},
+			"preflight_quota_check": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: descriptions["preflight_quota_check"],
+				DefaultFunc: schema.EnvDefaultFunc("SBC_PREFLIGHT_QUOTA_CHECK", false),
+			},
 			"domain_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -234,6 +284,18 @@ func Provider() *schema.Provider {
 					"OS_PROJECT_DOMAIN_NAME",
 				}, ""),
 			},
+			"default_tags": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: descriptions["default_tags"],
+			},
+			"ignore_tags": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: descriptions["ignore_tags"],
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
@@ -318,10 +380,12 @@ func Provider() *schema.Provider {
 
 			"sbercloud_cdm_flavors": cdm.DataSourceCdmFlavors(),
 
-			"sbercloud_compute_flavors":      ecs.DataSourceEcsFlavors(),
-			"sbercloud_compute_instance":     ecs.DataSourceComputeInstance(),
-			"sbercloud_compute_instances":    ecs.DataSourceComputeInstances(),
-			"sbercloud_compute_servergroups": ecs.DataSourceComputeServerGroups(),
+			"sbercloud_compute_flavors":                 ecs.DataSourceEcsFlavors(),
+			"sbercloud_compute_instance":                ecs.DataSourceComputeInstance(),
+			"sbercloud_compute_instances":               ecs.DataSourceComputeInstances(),
+			"sbercloud_compute_instance_remote_console": ecs_sbc.DataSourceComputeInstanceRemoteConsole(),
+			"sbercloud_compute_password":                ecs_sbc.DataSourceComputePassword(),
+			"sbercloud_compute_servergroups":            ecs.DataSourceComputeServerGroups(),
 
 			"sbercloud_dcs_flavors":        dcs.DataSourceDcsFlavorsV2(),
 			"sbercloud_dcs_accounts":       dcs.DataSourceDcsAccounts(),
@@ -427,6 +491,8 @@ func Provider() *schema.Provider {
 			"sbercloud_obs_buckets":          obs.DataSourceObsBuckets(),
 			"sbercloud_obs_bucket_object":    obs.DataSourceObsBucketObject(),
 
+			"sbercloud_price_estimate": bss.DataSourcePriceEstimate(),
+
 			"sbercloud_rds_pg_plugins":                      rds.DataSourcePgPlugins(),
 			"sbercloud_rds_pg_accounts":                     rds.DataSourcePgAccounts(),
 			"sbercloud_rds_pg_roles":                        rds.DataSourceRdsPgRoles(),
@@ -556,7 +622,8 @@ func Provider() *schema.Provider {
 			// no API
 			//"sbercloud_cloudtable_cluster": cloudtable.ResourceCloudTableCluster(),
 
-			"sbercloud_compute_instance":         ecs.ResourceComputeInstance(),
+			"sbercloud_compute_instance":         ecs_sbc.ResourceComputeInstance(),
+			"sbercloud_compute_instance_group":   ecs_sbc.ResourceComputeInstanceGroup(),
 			"sbercloud_compute_interface_attach": ecs.ResourceComputeInterfaceAttach(),
 			"sbercloud_compute_servergroup":      ecs.ResourceComputeServerGroup(),
 			"sbercloud_compute_eip_associate":    ecs.ResourceComputeEIPAssociate(),
@@ -603,7 +670,7 @@ func Provider() *schema.Provider {
 			"sbercloud_dc_connect_gateway_geip_associate": dc.ResourceDcConnectGatewayGeipAssociate(),
 			"sbercloud_dc_connect_gateway":                dc.ResourceDcConnectGateway(),
 
-			"sbercloud_dds_instance":                   dds.ResourceDdsInstanceV3(), //dds_sbc.ResourceDdsInstanceV3(),
+			"sbercloud_dds_instance":                   dds_sbc.ResourceDdsInstance(),
 			"sbercloud_dds_parameter_template":         dds.ResourceDdsParameterTemplate(),
 			"sbercloud_dds_parameter_template_reset":   dds.ResourceDDSParameterTemplateReset(),
 			"sbercloud_dds_parameter_template_copy":    dds.ResourceDDSParameterTemplateCopy(),
@@ -645,7 +712,8 @@ func Provider() *schema.Provider {
 			"sbercloud_dns_resolver_rule":           dns.ResourceResolverRule(),
 			"sbercloud_dns_resolver_rule_associate": dns.ResourceResolverRuleAssociate(),
 
-			"sbercloud_drs_job": drs.ResourceDrsJob(),
+			"sbercloud_drs_job":         drs.ResourceDrsJob(),
+			"sbercloud_drs_job_compare": drs.ResourceDrsJobCompare(),
 
 			"sbercloud_dws_cluster": dws.ResourceDwsCluster(),
 
@@ -824,6 +892,8 @@ func Provider() *schema.Provider {
 	//	}
 	//	return configureProvider(d, terraformVersion)
 	//}
+	addQuotaPreflightChecks(provider.ResourcesMap)
+
 	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		terraformVersion := provider.TerraformVersion
 		if terraformVersion == "" {
@@ -860,6 +930,10 @@ func init() {
 
 		"account_name": "The name of the Account to login with.",
 
+		"shared_config_file": "The path to the shared config file. If not set, the default is ~/.sbercloud/config.json.",
+
+		"profile": "The profile name as set in the shared config file.",
+
 		"insecure": "Trust self-signed certificates.",
 
 		"endpoints": "The custom endpoints used to override the default endpoint URL.",
@@ -868,6 +942,24 @@ func init() {
 
 		"rate_limit": "The maximum number of requests per second to SberCloud API. " +
 			"Set to 0 (default) for unlimited. Recommended value is 80-90 to stay under SberCloud limit of 100 req/s.",
+
+		"rate_limits": "The maximum number of requests per second to every service endpoint, keyed by service name " +
+			"(for example ecs or vpc) or endpoint host. The default key applies to the services which are not listed.",
+
+		"rate_limit_burst": "The maximum number of requests which can be sent at once by every rate limiter. Defaults to 1.",
+
+		"rate_limit_adaptive": "Whether to reduce the rate of requests to a service which answers with " +
+			"429 Too Many Requests, and to retry the throttled idempotent requests. Defaults to false.",
+
+		"http_trace_file": "The path of the file to write the redacted trace of every HTTP request and response to, " +
+			"one JSON line per request.",
+
+		"preflight_quota_check": "Whether to check the ECS, EVS and EIP quotas of the resources to create during " +
+			"the plan, and to fail the plan if the resources exceed the remaining quotas.",
+
+		"default_tags": "The default tags of resources managed by this provider.",
+
+		"ignore_tags": "The ignored tag keys of resources managed by this provider.",
 	}
 }
 
@@ -896,9 +988,19 @@ func configureProvider(_ context.Context, d *schema.ResourceData, terraformVersi
 		Cloud:               "hc.sbercloud.ru",
 		MaxRetries:          d.Get("max_retries").(int),
 		EnterpriseProjectID: d.Get("enterprise_project_id").(string),
+		SharedConfigFile:    d.Get("shared_config_file").(string),
+		Profile:             d.Get("profile").(string),
 		RegionClient:        true,
 		RegionProjectIDMap:  make(map[string]string),
 		RPLock:              new(sync.Mutex),
+		DefaultTags:         d.Get("default_tags").(map[string]interface{}),
+		IgnoreTags:          d.Get("ignore_tags").([]interface{}),
+	}
+
+	if config.SharedConfigFile != "" || config.Profile != "" {
+		if err := readSharedConfig(&config); err != nil {
+			return nil, diag.FromErr(err)
+		}
 	}
 
 	// get custom endpoints
@@ -908,39 +1010,109 @@ func configureProvider(_ context.Context, d *schema.ResourceData, terraformVersi
 	}
 	config.Endpoints = endpoints
 
-	if err := config.LoadAndValidate(); err != nil {
-		return nil, diag.FromErr(err)
+	var trace *HTTPTraceTransport
+	if traceFile := d.Get("http_trace_file").(string); traceFile != "" {
+		log.Printf("[INFO] Writing HTTP trace to %s", traceFile)
+
+		trace, err = NewHTTPTraceTransport(traceFile, nil)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
 	}
 
 	// Apply rate limiter to HTTP clients if configured
+	var rateLimiter *RateLimitedTransport
 	rateLimit := d.Get("rate_limit").(int)
-	if rateLimit > 0 {
-		log.Printf("[INFO] Configuring rate limiter with %d requests per second", rateLimit)
-
-		// Apply rate limiter to HwClient (main client for most operations)
-		if config.HwClient != nil {
-			originalTransport := config.HwClient.HTTPClient.Transport
-			rateLimitedTransport := NewRateLimitedTransport(rateLimit, originalTransport)
-			config.HwClient.HTTPClient.Transport = rateLimitedTransport
-		}
-
-		// Apply rate limiter to DomainClient (for domain/account operations)
-		if config.DomainClient != nil {
-			originalTransport := config.DomainClient.HTTPClient.Transport
-			rateLimitedTransport := NewRateLimitedTransport(rateLimit, originalTransport)
-			config.DomainClient.HTTPClient.Transport = rateLimitedTransport
+	rateLimitBurst := d.Get("rate_limit_burst").(int)
+	serviceRateLimits, err := flattenProviderRateLimits(d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	adaptiveRateLimit := d.Get("rate_limit_adaptive").(bool)
+	if rateLimit > 0 || len(serviceRateLimits) > 0 || adaptiveRateLimit {
+		log.Printf("[INFO] Configuring rate limiter with %d requests per second, per-service limits: %v, burst: %d, "+
+			"adaptive: %t", rateLimit, serviceRateLimits, rateLimitBurst, adaptiveRateLimit)
+
+		// The token buckets are shared by all the clients
+		rateLimiter = NewServiceRateLimitedTransport(rateLimit, serviceRateLimits, rateLimitBurst, nil)
+		if adaptiveRateLimit {
+			rateLimiter.Throttle = NewAdaptiveThrottle(config.MaxRetries)
 		}
 
 		log.Printf("[INFO] Rate limiter enabled: %d requests per second", rateLimit)
 	}
 
+	if err := config.LoadAndValidate(); err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	if wrapper := newTransportWrapper(trace, rateLimiter); wrapper != nil {
+		applyTransportWrapper(&config, wrapper)
+	}
+
 	if config.HwClient != nil && config.HwClient.ProjectID != "" {
 		config.RegionProjectIDMap[config.Region] = config.HwClient.ProjectID
 	}
 
+	if d.Get("preflight_quota_check").(bool) {
+		quotaPreflights.Store(&config, newQuotaPreflight(&config))
+	}
+	// the flavors, the images and the volume types checked by the compute instances are cached in the metadata of
+	// the configuration
+	ecs_sbc.RegisterComputeInstanceCatalog(&config)
+
 	return &config, nil
 }
 
+// newTransportWrapper returns the wrapper of the transport of the provider clients, or nil if neither the trace nor
+// the rate limiter is configured.
+// The trace is installed under the rate limiter, so that every retry of a throttled request is written.
+func newTransportWrapper(trace *HTTPTraceTransport,
+	rateLimiter *RateLimitedTransport) func(http.RoundTripper) http.RoundTripper {
+	if trace == nil && rateLimiter == nil {
+		return nil
+	}
+
+	return func(transport http.RoundTripper) http.RoundTripper {
+		if trace != nil {
+			transport = trace.WithTransport(transport)
+		}
+		if rateLimiter != nil {
+			transport = rateLimiter.WithTransport(transport)
+		}
+		return transport
+	}
+}
+
+// applyTransportWrapper wraps the transport of every provider client of the config.
+// The service clients are shallow copies of HwClient (main client for most operations) and
+// DomainClient (for domain/account operations), including the clients created later for other regions,
+// so all of them share the token buckets of the rate limiter.
+func applyTransportWrapper(c *config.Config, wrapper func(http.RoundTripper) http.RoundTripper) {
+	for i, client := range []*golangsdk.ProviderClient{c.HwClient, c.DomainClient} {
+		// HwClient and DomainClient may be the same client
+		if client == nil || (i > 0 && client == c.HwClient) {
+			continue
+		}
+		client.HTTPClient.Transport = wrapper(client.HTTPClient.Transport)
+	}
+}
+
+func flattenProviderRateLimits(d *schema.ResourceData) (map[string]int, error) {
+	rateLimits := d.Get("rate_limits").(map[string]interface{})
+	limits := make(map[string]int, len(rateLimits))
+
+	for key, val := range rateLimits {
+		limit := val.(int)
+		if limit < 0 {
+			return nil, fmt.Errorf("the rate limit of %s must be a positive value, got %d", key, limit)
+		}
+		limits[strings.ToLower(strings.TrimSpace(key))] = limit
+	}
+
+	return limits, nil
+}
+
 func flattenProviderEndpoints(d *schema.ResourceData) (map[string]string, error) {
 	endpoints := d.Get("endpoints").(map[string]interface{})
 	epMap := make(map[string]string)
@@ -0,0 +1,228 @@
+package sbercloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dew_sbc "github.com/sbercloud-terraform/terraform-provider-sbercloud/sbercloud/services/dew"
+)
+
+// frameworkProvider serves the ephemeral resources, which are only supported by terraform-plugin-framework. It is
+// muxed with the SDK provider and reuses its configuration: the SDK provider is configured first, and its
+// *config.Config is passed to the ephemeral resources.
+type frameworkProvider struct {
+	sdkProvider *schema.Provider
+}
+
+var _ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+
+// NewFrameworkProvider returns the terraform-plugin-framework provider, which is muxed with sdkProvider.
+func NewFrameworkProvider(sdkProvider *schema.Provider) provider.Provider {
+	return &frameworkProvider{sdkProvider: sdkProvider}
+}
+
+func (p *frameworkProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "sbercloud"
+}
+
+// Schema returns the schema of the SDK provider, as the muxed providers must have identical schemas.
+func (p *frameworkProvider) Schema(ctx context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	sdkResp, err := p.sdkProvider.GRPCProvider().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		resp.Diagnostics.AddError("Error retrieving the provider schema", err.Error())
+		return
+	}
+	if sdkResp.Provider == nil || sdkResp.Provider.Block == nil {
+		resp.Diagnostics.AddError("Error retrieving the provider schema", "the SDK provider has no schema")
+		return
+	}
+
+	attributes, blocks, err := buildFrameworkProviderSchema(sdkResp.Provider.Block)
+	if err != nil {
+		resp.Diagnostics.AddError("Error building the provider schema", err.Error())
+		return
+	}
+	resp.Schema = pschema.Schema{
+		Description:        sdkResp.Provider.Block.Description,
+		DeprecationMessage: buildFrameworkDeprecationMessage(sdkResp.Provider.Block.Deprecated),
+		Attributes:         attributes,
+		Blocks:             blocks,
+	}
+}
+
+func (p *frameworkProvider) Configure(_ context.Context, _ provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	meta := p.sdkProvider.Meta()
+	if meta == nil {
+		resp.Diagnostics.AddError("Provider not configured",
+			"the SDK provider must be configured before the plugin framework provider")
+		return
+	}
+	resp.DataSourceData = meta
+	resp.ResourceData = meta
+	resp.EphemeralResourceData = meta
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		dew_sbc.NewCsmsSecretVersionEphemeralResource,
+		dew_sbc.NewKmsDataKeyEphemeralResource,
+	}
+}
+
+// buildFrameworkDeprecationMessage returns a message for the deprecated attributes, as the protocol only keeps
+// whether the attribute is deprecated.
+func buildFrameworkDeprecationMessage(deprecated bool) string {
+	if deprecated {
+		return "This attribute is deprecated."
+	}
+	return ""
+}
+
+func buildFrameworkProviderSchema(block *tfprotov5.SchemaBlock) (map[string]pschema.Attribute,
+	map[string]pschema.Block, error) {
+	attributes := make(map[string]pschema.Attribute, len(block.Attributes))
+	for _, a := range block.Attributes {
+		attribute, err := buildFrameworkProviderAttribute(a)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error building attribute %s: %s", a.Name, err)
+		}
+		attributes[a.Name] = attribute
+	}
+
+	blocks := make(map[string]pschema.Block, len(block.BlockTypes))
+	for _, b := range block.BlockTypes {
+		nestedAttributes, nestedBlocks, err := buildFrameworkProviderSchema(b.Block)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error building block %s: %s", b.TypeName, err)
+		}
+		nestedObject := pschema.NestedBlockObject{
+			Attributes: nestedAttributes,
+			Blocks:     nestedBlocks,
+		}
+
+		switch b.Nesting {
+		case tfprotov5.SchemaNestedBlockNestingModeList:
+			blocks[b.TypeName] = pschema.ListNestedBlock{
+				NestedObject:       nestedObject,
+				Description:        b.Block.Description,
+				DeprecationMessage: buildFrameworkDeprecationMessage(b.Block.Deprecated),
+			}
+		case tfprotov5.SchemaNestedBlockNestingModeSet:
+			blocks[b.TypeName] = pschema.SetNestedBlock{
+				NestedObject:       nestedObject,
+				Description:        b.Block.Description,
+				DeprecationMessage: buildFrameworkDeprecationMessage(b.Block.Deprecated),
+			}
+		case tfprotov5.SchemaNestedBlockNestingModeSingle:
+			blocks[b.TypeName] = pschema.SingleNestedBlock{
+				Attributes:         nestedAttributes,
+				Blocks:             nestedBlocks,
+				Description:        b.Block.Description,
+				DeprecationMessage: buildFrameworkDeprecationMessage(b.Block.Deprecated),
+			}
+		default:
+			return nil, nil, fmt.Errorf("unsupported nesting mode %s of block %s", b.Nesting, b.TypeName)
+		}
+	}
+	return attributes, blocks, nil
+}
+
+func buildFrameworkProviderAttribute(a *tfprotov5.SchemaAttribute) (pschema.Attribute, error) {
+	deprecationMessage := buildFrameworkDeprecationMessage(a.Deprecated)
+
+	switch {
+	case a.Type.Is(tftypes.String):
+		return pschema.StringAttribute{
+			Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	case a.Type.Is(tftypes.Bool):
+		return pschema.BoolAttribute{
+			Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	case a.Type.Is(tftypes.Number):
+		return pschema.NumberAttribute{
+			Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	}
+
+	elemType, err := buildFrameworkAttrType(a.Type)
+	if err != nil {
+		return nil, err
+	}
+	switch t := elemType.(type) {
+	case types.ListType:
+		return pschema.ListAttribute{
+			ElementType: t.ElemType, Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	case types.SetType:
+		return pschema.SetAttribute{
+			ElementType: t.ElemType, Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	case types.MapType:
+		return pschema.MapAttribute{
+			ElementType: t.ElemType, Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	case types.ObjectType:
+		return pschema.ObjectAttribute{
+			AttributeTypes: t.AttrTypes, Required: a.Required, Optional: a.Optional, Sensitive: a.Sensitive,
+			Description: a.Description, DeprecationMessage: deprecationMessage,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", a.Type)
+}
+
+func buildFrameworkAttrType(t tftypes.Type) (attr.Type, error) {
+	switch {
+	case t.Is(tftypes.String):
+		return types.StringType, nil
+	case t.Is(tftypes.Bool):
+		return types.BoolType, nil
+	case t.Is(tftypes.Number):
+		return types.NumberType, nil
+	case t.Is(tftypes.List{}):
+		elemType, err := buildFrameworkAttrType(t.(tftypes.List).ElementType)
+		return types.ListType{ElemType: elemType}, err
+	case t.Is(tftypes.Set{}):
+		elemType, err := buildFrameworkAttrType(t.(tftypes.Set).ElementType)
+		return types.SetType{ElemType: elemType}, err
+	case t.Is(tftypes.Map{}):
+		elemType, err := buildFrameworkAttrType(t.(tftypes.Map).ElementType)
+		return types.MapType{ElemType: elemType}, err
+	case t.Is(tftypes.Object{}):
+		attrTypes := make(map[string]attr.Type)
+		for name, attrType := range t.(tftypes.Object).AttributeTypes {
+			v, err := buildFrameworkAttrType(attrType)
+			if err != nil {
+				return nil, err
+			}
+			attrTypes[name] = v
+		}
+		return types.ObjectType{AttrTypes: attrTypes}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,110 @@
+package sbercloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+)
+
+func TestFrameworkProvider_muxSchema(t *testing.T) {
+	ctx := context.Background()
+	sdkProvider := Provider()
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, sdkProvider.GRPCProvider,
+		providerserver.NewProtocol5(NewFrameworkProvider(sdkProvider)))
+	if err != nil {
+		t.Fatalf("error creating the mux server: %s", err)
+	}
+
+	// The mux server returns an error diagnostic if the provider schemas of the SDK and framework providers differ.
+	resp, err := muxServer.ProviderServer().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("error retrieving the provider schema: %s", err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Errorf("unexpected error: %s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	for _, name := range []string{"sbercloud_csms_secret_version", "sbercloud_kms_data_key"} {
+		if _, ok := resp.EphemeralResourceSchemas[name]; !ok {
+			t.Errorf("expected the ephemeral resource %s to be served", name)
+		}
+	}
+	if _, ok := resp.ResourceSchemas["sbercloud_compute_instance"]; !ok {
+		t.Errorf("expected the SDK resources to be served")
+	}
+}
+
+func TestFrameworkProvider_schemaMatchesSDK(t *testing.T) {
+	ctx := context.Background()
+	sdkProvider := Provider()
+
+	sdkResp, err := sdkProvider.GRPCProvider().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("error retrieving the SDK provider schema: %s", err)
+	}
+	frameworkServer := providerserver.NewProtocol5(NewFrameworkProvider(sdkProvider))()
+	frameworkResp, err := frameworkServer.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("error retrieving the framework provider schema: %s", err)
+	}
+	for _, d := range frameworkResp.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Fatalf("unexpected error: %s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	compareProviderSchemaBlocks(t, "provider", sdkResp.Provider.Block, frameworkResp.Provider.Block)
+}
+
+func compareProviderSchemaBlocks(t *testing.T, path string, sdkBlock, frameworkBlock *tfprotov5.SchemaBlock) {
+	frameworkAttributes := make(map[string]*tfprotov5.SchemaAttribute, len(frameworkBlock.Attributes))
+	for _, a := range frameworkBlock.Attributes {
+		frameworkAttributes[a.Name] = a
+	}
+	if len(sdkBlock.Attributes) != len(frameworkAttributes) {
+		t.Errorf("%s: expected %d attributes, got %d", path, len(sdkBlock.Attributes), len(frameworkAttributes))
+	}
+	for _, sdkAttribute := range sdkBlock.Attributes {
+		attrPath := path + "." + sdkAttribute.Name
+		frameworkAttribute, ok := frameworkAttributes[sdkAttribute.Name]
+		if !ok {
+			t.Errorf("%s: missing from the framework schema", attrPath)
+			continue
+		}
+		if !sdkAttribute.Type.Equal(frameworkAttribute.Type) {
+			t.Errorf("%s: expected type %s, got %s", attrPath, sdkAttribute.Type, frameworkAttribute.Type)
+		}
+		if sdkAttribute.Required != frameworkAttribute.Required || sdkAttribute.Optional != frameworkAttribute.Optional ||
+			sdkAttribute.Sensitive != frameworkAttribute.Sensitive {
+			t.Errorf("%s: expected required/optional/sensitive %t/%t/%t, got %t/%t/%t", attrPath,
+				sdkAttribute.Required, sdkAttribute.Optional, sdkAttribute.Sensitive,
+				frameworkAttribute.Required, frameworkAttribute.Optional, frameworkAttribute.Sensitive)
+		}
+	}
+
+	frameworkBlocks := make(map[string]*tfprotov5.SchemaNestedBlock, len(frameworkBlock.BlockTypes))
+	for _, b := range frameworkBlock.BlockTypes {
+		frameworkBlocks[b.TypeName] = b
+	}
+	if len(sdkBlock.BlockTypes) != len(frameworkBlocks) {
+		t.Errorf("%s: expected %d blocks, got %d", path, len(sdkBlock.BlockTypes), len(frameworkBlocks))
+	}
+	for _, sdkNestedBlock := range sdkBlock.BlockTypes {
+		blockPath := path + "." + sdkNestedBlock.TypeName
+		frameworkNestedBlock, ok := frameworkBlocks[sdkNestedBlock.TypeName]
+		if !ok {
+			t.Errorf("%s: missing from the framework schema", blockPath)
+			continue
+		}
+		if sdkNestedBlock.Nesting != frameworkNestedBlock.Nesting {
+			t.Errorf("%s: expected nesting mode %s, got %s", blockPath, sdkNestedBlock.Nesting,
+				frameworkNestedBlock.Nesting)
+		}
+		compareProviderSchemaBlocks(t, blockPath, sdkNestedBlock.Block, frameworkNestedBlock.Block)
+	}
+}
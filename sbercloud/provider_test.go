@@ -1,12 +1,19 @@
 package sbercloud
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/chnsz/golangsdk"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
 	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/helper/pathorcontents"
 )
 
@@ -98,3 +105,108 @@ func envVarFile(varName string) (string, error) {
 	}
 	return tmpFile.Name(), nil
 }
+
+// newIdentityStubServer returns a stub of the IAM API answering the requests sent by LoadAndValidate.
+func newIdentityStubServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/projects"):
+			name := r.URL.Query().Get("name")
+			_, _ = fmt.Fprintf(w, `{"projects":[{"id":"%s-project-id","name":"%s"}],"links":{}}`, name, name)
+		case strings.HasSuffix(r.URL.Path, "/domains"):
+			_, _ = w.Write([]byte(`{"domains":[{"id":"test-domain-id","name":"test-account"}],"links":{}}`))
+		default:
+			_, _ = w.Write([]byte(`{"catalog":[],"links":{}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestConfigureProvider_RateLimitedClients(t *testing.T) {
+	server, requests := newIdentityStubServer(t)
+
+	raw := map[string]interface{}{
+		"region":      "ru-moscow-1",
+		"access_key":  "TEST_ACCESS_KEY",
+		"secret_key":  "TEST_SECRET_KEY",
+		"auth_url":    server.URL + "/v3",
+		"max_retries": 0,
+		"rate_limit":  80,
+		"rate_limits": map[string]interface{}{
+			"ecs": 20,
+		},
+		"rate_limit_adaptive": true,
+		"endpoints": map[string]interface{}{
+			"iam": server.URL + "/v3",
+		},
+	}
+	d := schema.TestResourceDataRaw(t, Provider().Schema, raw)
+
+	meta, diags := configureProvider(context.Background(), d, "1.5.0")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	cfg := meta.(*config.Config)
+	if atomic.LoadInt32(requests) == 0 {
+		t.Fatal("expected LoadAndValidate to call the IAM stub")
+	}
+
+	hwTransport, ok := cfg.HwClient.HTTPClient.Transport.(*RateLimitedTransport)
+	if !ok {
+		t.Fatalf("expected HwClient to use RateLimitedTransport, got %T", cfg.HwClient.HTTPClient.Transport)
+	}
+	if hwTransport.Limiter == nil || hwTransport.ServiceLimiter == nil || hwTransport.Throttle == nil {
+		t.Fatalf("expected the global, per-service and adaptive limiters to be set, got %+v", hwTransport)
+	}
+
+	clients := map[string]*golangsdk.ProviderClient{
+		"HwClient":     cfg.HwClient,
+		"DomainClient": cfg.DomainClient,
+	}
+	serviceClients := []struct {
+		service string
+		region  string
+	}{
+		{"ecs", "ru-moscow-1"},
+		{"vpc", "ru-moscow-1"},
+		// the clients of other regions are created lazily and query their project IDs first
+		{"ecs", "ru-kazan-1"},
+		{"rds", "ru-kazan-1"},
+		// the clients of global services use DomainClient
+		{"iam", "ru-moscow-1"},
+		{"bss", "ru-moscow-1"},
+	}
+	for _, sc := range serviceClients {
+		client, err := cfg.NewServiceClient(sc.service, sc.region)
+		if err != nil {
+			t.Fatalf("error creating %s client in %s: %s", sc.service, sc.region, err)
+		}
+		clients[fmt.Sprintf("%s client in %s", sc.service, sc.region)] = client.ProviderClient
+	}
+	if projectID := cfg.RegionProjectIDMap["ru-kazan-1"]; projectID != "ru-kazan-1-project-id" {
+		t.Errorf("expected the project ID of ru-kazan-1 to be loaded, got %q", projectID)
+	}
+
+	for name, client := range clients {
+		transport, ok := client.HTTPClient.Transport.(*RateLimitedTransport)
+		if !ok {
+			t.Errorf("expected %s to use RateLimitedTransport, got %T", name, client.HTTPClient.Transport)
+			continue
+		}
+		if _, nested := transport.Transport.(*RateLimitedTransport); nested {
+			t.Errorf("expected the transport of %s not to be rate limited twice", name)
+		}
+		if transport.Limiter != hwTransport.Limiter || transport.ServiceLimiter != hwTransport.ServiceLimiter ||
+			transport.Throttle != hwTransport.Throttle {
+			t.Errorf("expected %s to share the limiters of HwClient", name)
+		}
+	}
+}
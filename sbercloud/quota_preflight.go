@@ -0,0 +1,423 @@
+package sbercloud
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// The quotas checked before the resources are created
+const (
+	quotaInstances = "instances"
+	quotaCores     = "cores"
+	quotaRAM       = "ram"
+	quotaVolumes   = "volumes"
+	quotaGigabytes = "gigabytes"
+	quotaPublicIPs = "public_ips"
+)
+
+var (
+	quotaDescriptions = map[string]string{
+		quotaInstances: "ECS instances",
+		quotaCores:     "ECS vCPUs",
+		quotaRAM:       "ECS memory (MB)",
+		quotaVolumes:   "EVS volumes",
+		quotaGigabytes: "EVS capacity (GB)",
+		quotaPublicIPs: "EIPs",
+	}
+
+	// quotaServices are the services whose quota APIs return the quotas
+	quotaServices = map[string]string{
+		quotaInstances: "ecs",
+		quotaCores:     "ecs",
+		quotaRAM:       "ecs",
+		quotaVolumes:   "evs",
+		quotaGigabytes: "evs",
+		quotaPublicIPs: "vpc",
+	}
+
+	// quotaPreflightRequests build the quotas requested by the plan of every checked resource
+	quotaPreflightRequests = map[string]quotaRequestFunc{
+		"sbercloud_compute_instance": computeInstanceQuotaRequest,
+		"sbercloud_evs_volume":       evsVolumeQuotaRequest,
+		"sbercloud_vpc_eip":          vpcEipQuotaRequest,
+		"sbercloud_cce_node_pool":    cceNodePoolQuotaRequest,
+	}
+
+	// quotaPreflights keeps the quota pre-flight check of every provider configuration which enables it
+	quotaPreflights sync.Map
+)
+
+type quotaUsage struct {
+	// Limit is negative if the quota is unlimited
+	Limit int
+	Used  int
+}
+
+type flavorSize struct {
+	VCPUs int
+	RAM   int
+}
+
+type quotaRequestFunc func(d *schema.ResourceDiff, q *quotaPreflight, region string) map[string]int
+
+// quotaPreflight checks the quotas requested by the resources of a plan against the remaining quotas.
+// The quotas are queried once, and the requested quotas of all the resources planned by the provider
+// are summed up, so that the plan fails if the resources would exceed the quota together.
+type quotaPreflight struct {
+	mu sync.Mutex
+
+	fetchQuotas  func(region, service string) (map[string]quotaUsage, error)
+	fetchFlavors func(region string) (map[string]flavorSize, error)
+
+	quotas    map[string]map[string]quotaUsage
+	flavors   map[string]map[string]flavorSize
+	requested map[string]int
+}
+
+func newQuotaPreflight(cfg *config.Config) *quotaPreflight {
+	return &quotaPreflight{
+		fetchQuotas: func(region, service string) (map[string]quotaUsage, error) {
+			return fetchServiceQuotas(cfg, region, service)
+		},
+		fetchFlavors: func(region string) (map[string]flavorSize, error) {
+			return fetchComputeFlavors(cfg, region)
+		},
+		quotas:    make(map[string]map[string]quotaUsage),
+		flavors:   make(map[string]map[string]flavorSize),
+		requested: make(map[string]int),
+	}
+}
+
+// flavor returns the size of the ECS flavor, ok is false if the flavor can not be found.
+func (q *quotaPreflight) flavor(region, flavorID string) (flavorSize, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	flavors, ok := q.flavors[region]
+	if !ok {
+		var err error
+		flavors, err = q.fetchFlavors(region)
+		if err != nil {
+			log.Printf("[WARN] unable to query the ECS flavors for the quota pre-flight check: %s", err)
+		}
+		q.flavors[region] = flavors
+	}
+
+	size, ok := flavors[flavorID]
+	return size, ok
+}
+
+// quota returns the quota usage of the region, ok is false if the quota can not be queried.
+// The caller must hold the lock.
+func (q *quotaPreflight) quota(region, name string) (quotaUsage, bool) {
+	key := region + "/" + quotaServices[name]
+	quotas, ok := q.quotas[key]
+	if !ok {
+		var err error
+		quotas, err = q.fetchQuotas(region, quotaServices[name])
+		if err != nil {
+			log.Printf("[WARN] unable to query the %s quotas for the quota pre-flight check: %s", quotaServices[name], err)
+		}
+		q.quotas[key] = quotas
+	}
+
+	usage, ok := quotas[name]
+	return usage, ok
+}
+
+// check adds the requested quotas to the quotas requested by the plan so far, and returns an error listing the
+// requested and remaining quotas if any of them is exceeded. The quotas which can not be queried are not checked.
+func (q *quotaPreflight) check(region string, request map[string]int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	names := make([]string, 0, len(request))
+	for name, amount := range request {
+		if amount > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	totals := make(map[string]int, len(names))
+	exceeded := make([]string, 0)
+	for _, name := range names {
+		key := region + "/" + name
+		totals[key] = q.requested[key] + request[name]
+
+		usage, ok := q.quota(region, name)
+		if !ok || usage.Limit < 0 {
+			continue
+		}
+		remaining := usage.Limit - usage.Used
+		if remaining < 0 {
+			remaining = 0
+		}
+		if totals[key] > remaining {
+			exceeded = append(exceeded, fmt.Sprintf("%s: requested %d, remaining %d of %d",
+				quotaDescriptions[name], totals[key], remaining, usage.Limit))
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("the resources of this plan exceed the quotas of region %s:\n  %s", region,
+			strings.Join(exceeded, "\n  "))
+	}
+	for key, total := range totals {
+		q.requested[key] = total
+	}
+	return nil
+}
+
+// quotaPreflightCheck returns the CustomizeDiff function which checks the quotas requested by the resource,
+// if the quota pre-flight check is enabled in the provider configuration.
+func quotaPreflightCheck(buildRequest quotaRequestFunc) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		cfg, ok := meta.(*config.Config)
+		if !ok {
+			return nil
+		}
+		v, ok := quotaPreflights.Load(cfg)
+		if !ok {
+			return nil
+		}
+		q := v.(*quotaPreflight)
+
+		region := cfg.Region
+		if v, ok := d.Get("region").(string); ok && v != "" {
+			region = v
+		}
+		return q.check(region, buildRequest(d, q, region))
+	}
+}
+
+// addQuotaPreflightChecks adds the quota pre-flight check to the resources which consume the checked quotas.
+func addQuotaPreflightChecks(resources map[string]*schema.Resource) {
+	for name, buildRequest := range quotaPreflightRequests {
+		r, ok := resources[name]
+		if !ok {
+			continue
+		}
+
+		if r.CustomizeDiff == nil {
+			r.CustomizeDiff = quotaPreflightCheck(buildRequest)
+		} else {
+			r.CustomizeDiff = customdiff.Sequence(r.CustomizeDiff, quotaPreflightCheck(buildRequest))
+		}
+	}
+}
+
+// addFlavorQuotaRequest adds the vCPUs and memory of count instances of the flavor, if it is known.
+func addFlavorQuotaRequest(request map[string]int, d *schema.ResourceDiff, q *quotaPreflight, region string,
+	count int) {
+	if !d.NewValueKnown("flavor_id") {
+		return
+	}
+	flavorID := d.Get("flavor_id").(string)
+	if flavorID == "" {
+		return
+	}
+
+	size, ok := q.flavor(region, flavorID)
+	if !ok {
+		log.Printf("[WARN] the flavor %s is not found, its vCPUs and memory are not checked against the quotas",
+			flavorID)
+		return
+	}
+	request[quotaCores] += count * size.VCPUs
+	request[quotaRAM] += count * size.RAM
+}
+
+// sumVolumeSizes returns the number and total size of the volumes of the list attribute.
+func sumVolumeSizes(d *schema.ResourceDiff, key string) (count, size int) {
+	volumes, _ := d.Get(key).([]interface{})
+	for _, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		count++
+		size += volume["size"].(int)
+	}
+	return count, size
+}
+
+func computeInstanceQuotaRequest(d *schema.ResourceDiff, q *quotaPreflight, region string) map[string]int {
+	if d.Id() != "" {
+		return nil
+	}
+
+	dataDisks, dataDisksSize := sumVolumeSizes(d, "data_disks")
+	request := map[string]int{
+		quotaInstances: 1,
+		quotaVolumes:   1 + dataDisks,
+		quotaGigabytes: d.Get("system_disk_size").(int) + dataDisksSize,
+	}
+	addFlavorQuotaRequest(request, d, q, region, 1)
+	return request
+}
+
+func evsVolumeQuotaRequest(d *schema.ResourceDiff, _ *quotaPreflight, _ string) map[string]int {
+	if d.Id() == "" {
+		return map[string]int{
+			quotaVolumes:   1,
+			quotaGigabytes: d.Get("size").(int),
+		}
+	}
+
+	// the volumes can be expanded
+	oldSize, newSize := d.GetChange("size")
+	return map[string]int{
+		quotaGigabytes: newSize.(int) - oldSize.(int),
+	}
+}
+
+func vpcEipQuotaRequest(d *schema.ResourceDiff, _ *quotaPreflight, _ string) map[string]int {
+	if d.Id() != "" {
+		return nil
+	}
+	return map[string]int{quotaPublicIPs: 1}
+}
+
+func cceNodePoolQuotaRequest(d *schema.ResourceDiff, q *quotaPreflight, region string) map[string]int {
+	oldCount, newCount := d.GetChange("initial_node_count")
+	nodes := newCount.(int)
+	if d.Id() != "" {
+		// the node pool is scaled out
+		nodes -= oldCount.(int)
+	}
+	if nodes <= 0 {
+		return nil
+	}
+
+	_, rootVolumeSize := sumVolumeSizes(d, "root_volume")
+	dataVolumes, dataVolumesSize := sumVolumeSizes(d, "data_volumes")
+	request := map[string]int{
+		quotaInstances: nodes,
+		quotaVolumes:   nodes * (1 + dataVolumes),
+		quotaGigabytes: nodes * (rootVolumeSize + dataVolumesSize),
+	}
+	addFlavorQuotaRequest(request, d, q, region, nodes)
+	return request
+}
+
+func doQuotaRequest(cfg *config.Config, service, region, path string) (interface{}, error) {
+	client, err := cfg.NewServiceClient(service, region)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s client: %s", strings.ToUpper(service), err)
+	}
+
+	requestPath := client.Endpoint + strings.ReplaceAll(path, "{project_id}", client.ProjectID)
+	requestOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		MoreHeaders:      map[string]string{"Content-Type": "application/json"},
+	}
+	resp, err := client.Request("GET", requestPath, &requestOpt)
+	if err != nil {
+		return nil, err
+	}
+	return utils.FlattenResponse(resp)
+}
+
+// quotaNumber returns the number of the response, the APIs return some numbers as strings.
+// The default value is returned if the number is missing or invalid.
+func quotaNumber(expression string, resp interface{}, defaultValue int) int {
+	switch v := utils.PathSearch(expression, resp, nil).(type) {
+	case float64:
+		return int(v)
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// @API ECS GET /v1/{project_id}/cloudservers/limits
+// @API EVS GET /v2/{project_id}/os-quota-sets/{project_id}
+// @API VPC GET /v1/{project_id}/quotas
+func fetchServiceQuotas(cfg *config.Config, region, service string) (map[string]quotaUsage, error) {
+	switch service {
+	case "ecs":
+		resp, err := doQuotaRequest(cfg, service, region, "v1/{project_id}/cloudservers/limits")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]quotaUsage{
+			quotaInstances: {
+				Limit: quotaNumber("absolute.maxTotalInstances", resp, -1),
+				Used:  quotaNumber("absolute.totalInstancesUsed", resp, 0),
+			},
+			quotaCores: {
+				Limit: quotaNumber("absolute.maxTotalCores", resp, -1),
+				Used:  quotaNumber("absolute.totalCoresUsed", resp, 0),
+			},
+			quotaRAM: {
+				Limit: quotaNumber("absolute.maxTotalRAMSize", resp, -1),
+				Used:  quotaNumber("absolute.totalRAMUsed", resp, 0),
+			},
+		}, nil
+	case "evs":
+		resp, err := doQuotaRequest(cfg, service, region, "v2/{project_id}/os-quota-sets/{project_id}?usage=True")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]quotaUsage{
+			quotaVolumes: {
+				Limit: quotaNumber("quota_set.volumes.limit", resp, -1),
+				Used: quotaNumber("quota_set.volumes.in_use", resp, 0) +
+					quotaNumber("quota_set.volumes.reserved", resp, 0),
+			},
+			quotaGigabytes: {
+				Limit: quotaNumber("quota_set.gigabytes.limit", resp, -1),
+				Used: quotaNumber("quota_set.gigabytes.in_use", resp, 0) +
+					quotaNumber("quota_set.gigabytes.reserved", resp, 0),
+			},
+		}, nil
+	case "vpc":
+		resp, err := doQuotaRequest(cfg, service, region, "v1/{project_id}/quotas?type=publicIp")
+		if err != nil {
+			return nil, err
+		}
+		publicIP := utils.PathSearch("quotas.resources[?type=='publicIp']|[0]", resp, nil)
+		if publicIP == nil {
+			return nil, fmt.Errorf("the publicIp quota is not found")
+		}
+		return map[string]quotaUsage{
+			quotaPublicIPs: {
+				Limit: quotaNumber("quota", publicIP, -1),
+				Used:  quotaNumber("used", publicIP, 0),
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("the quotas of service %s are not supported", service)
+}
+
+// @API ECS GET /v1/{project_id}/cloudservers/flavors
+func fetchComputeFlavors(cfg *config.Config, region string) (map[string]flavorSize, error) {
+	resp, err := doQuotaRequest(cfg, "ecs", region, "v1/{project_id}/cloudservers/flavors")
+	if err != nil {
+		return nil, err
+	}
+
+	flavors := utils.PathSearch("flavors", resp, make([]interface{}, 0)).([]interface{})
+	result := make(map[string]flavorSize, len(flavors))
+	for _, flavor := range flavors {
+		result[fmt.Sprint(utils.PathSearch("id", flavor, ""))] = flavorSize{
+			VCPUs: quotaNumber("vcpus", flavor, 0),
+			RAM:   quotaNumber("ram", flavor, 0),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,176 @@
+package sbercloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+func newTestQuotaPreflight(quotas map[string]quotaUsage, flavors map[string]flavorSize) (*quotaPreflight, *int) {
+	var fetches int
+	q := newQuotaPreflight(nil)
+	q.fetchQuotas = func(_, service string) (map[string]quotaUsage, error) {
+		fetches++
+		result := make(map[string]quotaUsage)
+		for name, usage := range quotas {
+			if quotaServices[name] == service {
+				result[name] = usage
+			}
+		}
+		return result, nil
+	}
+	q.fetchFlavors = func(_ string) (map[string]flavorSize, error) {
+		return flavors, nil
+	}
+	return q, &fetches
+}
+
+func TestQuotaPreflight_AggregatesRequests(t *testing.T) {
+	q, fetches := newTestQuotaPreflight(map[string]quotaUsage{
+		quotaCores:     {Limit: 20, Used: 12},
+		quotaGigabytes: {Limit: 1000, Used: 100},
+	}, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := q.check("ru-moscow-1", map[string]int{quotaCores: 4, quotaGigabytes: 100}); err != nil {
+			t.Fatalf("unexpected error for request %d: %s", i+1, err)
+		}
+	}
+
+	err := q.check("ru-moscow-1", map[string]int{quotaCores: 4, quotaGigabytes: 100})
+	if err == nil {
+		t.Fatal("expected the third request to exceed the vCPUs quota")
+	}
+	for _, expected := range []string{"ru-moscow-1", "ECS vCPUs: requested 12, remaining 8 of 20"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got: %s", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), "EVS") {
+		t.Errorf("expected only the exceeded quotas in the error, got: %s", err)
+	}
+
+	// the rejected request is not added to the requested quotas
+	if err := q.check("ru-moscow-1", map[string]int{quotaGigabytes: 700}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	// the quotas of a service are queried once per region
+	if *fetches != 2 {
+		t.Errorf("expected the ECS and EVS quotas to be queried once, got %d queries", *fetches)
+	}
+
+	// the requested quotas are summed up per region
+	if err := q.check("ru-kazan-1", map[string]int{quotaCores: 8}); err != nil {
+		t.Errorf("unexpected error for another region: %s", err)
+	}
+}
+
+func TestQuotaPreflight_UncheckedQuotas(t *testing.T) {
+	q, _ := newTestQuotaPreflight(map[string]quotaUsage{
+		quotaInstances: {Limit: -1, Used: 100},
+	}, nil)
+	q.fetchQuotas = func(_, service string) (map[string]quotaUsage, error) {
+		if service == "ecs" {
+			return map[string]quotaUsage{quotaInstances: {Limit: -1, Used: 100}}, nil
+		}
+		return nil, fmt.Errorf("access denied")
+	}
+
+	// the unlimited quotas and the quotas which can not be queried are not checked
+	request := map[string]int{quotaInstances: 1000, quotaVolumes: 1000, quotaPublicIPs: 1000}
+	if err := q.check("ru-moscow-1", request); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestQuotaPreflightCheck_Resources(t *testing.T) {
+	resources := Provider().ResourcesMap
+	meta := &config.Config{Region: "ru-moscow-1"}
+
+	testCases := []struct {
+		resource string
+		config   map[string]interface{}
+		quotas   map[string]quotaUsage
+		expected string
+	}{
+		{
+			resource: "sbercloud_compute_instance",
+			config: map[string]interface{}{
+				"name":             "test",
+				"flavor_id":        "s6.large.2",
+				"image_id":         "ad091b52-742f-469e-8f3c-fd81cadf0743",
+				"system_disk_size": 40,
+				"data_disks": []interface{}{
+					map[string]interface{}{"type": "SSD", "size": 100},
+				},
+			},
+			quotas:   map[string]quotaUsage{quotaCores: {Limit: 10, Used: 9}, quotaGigabytes: {Limit: 200, Used: 100}},
+			expected: "ECS vCPUs: requested 2, remaining 1 of 10\n  EVS capacity (GB): requested 140, remaining 100 of 200",
+		},
+		{
+			resource: "sbercloud_evs_volume",
+			config: map[string]interface{}{
+				"name":              "test",
+				"volume_type":       "SSD",
+				"availability_zone": "ru-moscow-1a",
+				"size":              500,
+			},
+			quotas:   map[string]quotaUsage{quotaVolumes: {Limit: 10, Used: 10}},
+			expected: "EVS volumes: requested 1, remaining 0 of 10",
+		},
+		{
+			resource: "sbercloud_vpc_eip",
+			config: map[string]interface{}{
+				"publicip": []interface{}{
+					map[string]interface{}{"type": "5_bgp"},
+				},
+			},
+			quotas:   map[string]quotaUsage{quotaPublicIPs: {Limit: 5, Used: 5}},
+			expected: "EIPs: requested 1, remaining 0 of 5",
+		},
+		{
+			resource: "sbercloud_cce_node_pool",
+			config: map[string]interface{}{
+				"name":               "test",
+				"flavor_id":          "s6.large.2",
+				"initial_node_count": 3,
+				"root_volume": []interface{}{
+					map[string]interface{}{"volumetype": "SSD", "size": 40},
+				},
+				"data_volumes": []interface{}{
+					map[string]interface{}{"volumetype": "SSD", "size": 100},
+				},
+			},
+			quotas:   map[string]quotaUsage{quotaRAM: {Limit: 16384, Used: 0}, quotaVolumes: {Limit: 5, Used: 0}},
+			expected: "ECS memory (MB): requested 24576, remaining 16384 of 16384\n  EVS volumes: requested 6, remaining 5 of 5",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.resource, func(t *testing.T) {
+			r, ok := resources[tc.resource]
+			if !ok {
+				t.Fatalf("resource %s is not registered", tc.resource)
+			}
+			cfg := terraform.NewResourceConfigRaw(tc.config)
+
+			// the check is skipped if it is not enabled
+			if _, err := r.SimpleDiff(context.Background(), nil, cfg, meta); err != nil {
+				t.Fatalf("unexpected error without the quota check: %s", err)
+			}
+
+			q, _ := newTestQuotaPreflight(tc.quotas, map[string]flavorSize{"s6.large.2": {VCPUs: 2, RAM: 8192}})
+			quotaPreflights.Store(meta, q)
+			defer quotaPreflights.Delete(meta)
+
+			_, err := r.SimpleDiff(context.Background(), nil, cfg, meta)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected the error to contain %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -1,11 +1,19 @@
 package sbercloud
 
 import (
+	"io"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
+// defaultServiceRateLimitKey is the key of the rate_limits map which applies to the services that are not listed.
+const defaultServiceRateLimitKey = "default"
+
 // RateLimitedTransport wraps http.RoundTripper with rate limiting functionality.
 // It uses the token bucket algorithm to limit the number of HTTP requests per second.
 type RateLimitedTransport struct {
@@ -13,14 +21,77 @@ type RateLimitedTransport struct {
 	// If nil, http.DefaultTransport will be used.
 	Transport http.RoundTripper
 
-	// Limiter controls the rate of requests.
-	// If nil, no rate limiting is applied.
+	// Limiter controls the overall rate of requests.
+	// If nil, no global rate limiting is applied.
 	Limiter *rate.Limiter
+
+	// ServiceLimiter controls the rate of requests to every service endpoint host.
+	// If nil, no per-service rate limiting is applied.
+	ServiceLimiter *ServiceRateLimiter
+
+	// Throttle reduces the rate of requests to the hosts which answer with 429 Too Many Requests
+	// and retries the throttled idempotent requests.
+	// If nil, the throttled responses are returned as is.
+	Throttle *AdaptiveThrottle
 }
 
 // RoundTrip implements the http.RoundTripper interface.
 // It applies rate limiting before forwarding the request to the underlying transport.
 func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(req); err != nil {
+			return nil, err
+		}
+
+		// Proceed with the actual HTTP request
+		resp, err := t.Transport.RoundTrip(req)
+		if err != nil || t.Throttle == nil {
+			return resp, err
+		}
+
+		host := req.URL.Host
+		if !isThrottledResponse(resp) {
+			t.Throttle.OnSuccess(host)
+			return resp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		t.Throttle.OnThrottled(host, t.ceiling(host), retryAfter)
+		if attempt >= t.Throttle.MaxRetries || !isRetryableRequest(req) {
+			return resp, nil
+		}
+
+		retryReq, cloneErr := cloneRequest(req)
+		if cloneErr != nil {
+			log.Printf("[WARN] unable to retry the throttled request %s %s: %s", req.Method, req.URL, cloneErr)
+			return resp, nil
+		}
+
+		// Drain the body, so that the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		delay := t.Throttle.retryDelay(attempt, retryAfter)
+		log.Printf("[DEBUG] request %s %s is throttled, retry number %d in %s", req.Method, req.URL, attempt+1, delay)
+		if err := sleepWithContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		req = retryReq
+	}
+}
+
+// wait blocks until all the token buckets which apply to the request permit it.
+func (t *RateLimitedTransport) wait(req *http.Request) error {
+	// Wait for the bucket of the service first, so that a busy service does not hold
+	// the tokens of the global bucket while it is waiting for its own ones.
+	if t.ServiceLimiter != nil {
+		if limiter := t.ServiceLimiter.Limiter(req.URL.Host); limiter != nil {
+			if err := limiter.Wait(req.Context()); err != nil {
+				return err
+			}
+		}
+	}
+
 	// If limiter is configured, wait for permission to proceed
 	if t.Limiter != nil {
 		// Wait blocks until limiter permits one event or context is done.
@@ -28,12 +99,43 @@ func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, err
 		err := t.Limiter.Wait(req.Context())
 		if err != nil {
 			// Return error if context was cancelled or deadline exceeded
-			return nil, err
+			return err
 		}
 	}
 
-	// Proceed with the actual HTTP request
-	return t.Transport.RoundTrip(req)
+	if t.Throttle != nil {
+		return t.Throttle.Wait(req.Context(), req.URL.Host)
+	}
+	return nil
+}
+
+// ceiling returns the configured rate of the host, which is the rate the adaptive throttle starts from
+// and recovers to.
+func (t *RateLimitedTransport) ceiling(host string) rate.Limit {
+	if t.ServiceLimiter != nil {
+		if limiter := t.ServiceLimiter.Limiter(host); limiter != nil {
+			return limiter.Limit()
+		}
+	}
+	if t.Limiter != nil {
+		return t.Limiter.Limit()
+	}
+	return defaultAdaptiveRate
+}
+
+// WithTransport returns a copy of the transport which forwards the requests to baseTransport.
+// The copy shares the token buckets with the original transport.
+func (t *RateLimitedTransport) WithTransport(baseTransport http.RoundTripper) *RateLimitedTransport {
+	if baseTransport == nil {
+		baseTransport = http.DefaultTransport
+	}
+
+	return &RateLimitedTransport{
+		Transport:      baseTransport,
+		Limiter:        t.Limiter,
+		ServiceLimiter: t.ServiceLimiter,
+		Throttle:       t.Throttle,
+	}
 }
 
 // NewRateLimitedTransport creates a new rate-limited HTTP transport.
@@ -53,10 +155,35 @@ func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, err
 //	transport := NewRateLimitedTransport(80, nil)
 //	client := &http.Client{Transport: transport}
 func NewRateLimitedTransport(rateLimit int, baseTransport http.RoundTripper) *RateLimitedTransport {
+	// burst size = 1 to prevent concurrent requests that could trigger 429 errors
+	// This ensures only one request can proceed at a time, preventing API rate limit bursts
+	return NewServiceRateLimitedTransport(rateLimit, nil, 1, baseTransport)
+}
+
+// NewServiceRateLimitedTransport creates a new rate-limited HTTP transport with per-service token buckets.
+//
+// Parameters:
+//   - rateLimit: maximum requests per second for all services together (0 = unlimited)
+//   - serviceLimits: maximum requests per second keyed by service name or endpoint host (nil = no per-service limits)
+//   - burst: maximum number of requests which can be sent at once by every bucket (values below 1 are treated as 1)
+//   - baseTransport: underlying transport to wrap (nil = http.DefaultTransport)
+//
+// A request has to get a token from the bucket of its service endpoint host first, and then from the global bucket.
+//
+// Example:
+//
+//	// Limit ECS to 20 and VPC to 50 requests per second, all other services to 80 requests per second each
+//	transport := NewServiceRateLimitedTransport(0, map[string]int{"ecs": 20, "vpc": 50, "default": 80}, 1, nil)
+//	client := &http.Client{Transport: transport}
+func NewServiceRateLimitedTransport(rateLimit int, serviceLimits map[string]int, burst int,
+	baseTransport http.RoundTripper) *RateLimitedTransport {
 	// Use default transport if none provided
 	if baseTransport == nil {
 		baseTransport = http.DefaultTransport
 	}
+	if burst < 1 {
+		burst = 1
+	}
 
 	transport := &RateLimitedTransport{
 		Transport: baseTransport,
@@ -65,10 +192,83 @@ func NewRateLimitedTransport(rateLimit int, baseTransport http.RoundTripper) *Ra
 	// Only create limiter if rate limit is specified and greater than 0
 	if rateLimit > 0 {
 		// rate.Limit(rateLimit) = requests per second
-		// burst size = 1 to prevent concurrent requests that could trigger 429 errors
-		// This ensures only one request can proceed at a time, preventing API rate limit bursts
-		transport.Limiter = rate.NewLimiter(rate.Limit(rateLimit), 1)
+		transport.Limiter = rate.NewLimiter(rate.Limit(rateLimit), burst)
+	}
+
+	if len(serviceLimits) > 0 {
+		transport.ServiceLimiter = NewServiceRateLimiter(serviceLimits, burst)
 	}
 
 	return transport
 }
+
+// ServiceRateLimiter keeps a separate token bucket for every service endpoint host,
+// so that the requests to a slow or busy service do not throttle the requests to the other ones.
+type ServiceRateLimiter struct {
+	// Limits is the maximum number of requests per second, keyed by service name (for example "ecs")
+	// or by endpoint host (for example "ecs.ru-moscow-1.hc.sbercloud.ru").
+	// The "default" key applies to the services which are not listed.
+	Limits map[string]int
+
+	// Burst is the bucket size of every token bucket.
+	Burst int
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+}
+
+// NewServiceRateLimiter creates a ServiceRateLimiter with the given limits and bucket size.
+func NewServiceRateLimiter(limits map[string]int, burst int) *ServiceRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &ServiceRateLimiter{
+		Limits:  limits,
+		Burst:   burst,
+		buckets: make(map[string]*rate.Limiter),
+	}
+}
+
+// Limiter returns the token bucket of the endpoint host, creating it on first use.
+// It returns nil if no limit applies to the host.
+func (l *ServiceRateLimiter) Limiter(host string) *rate.Limiter {
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limiter, ok := l.buckets[host]; ok {
+		return limiter
+	}
+
+	var limiter *rate.Limiter
+	if limit := l.limitOf(host); limit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(limit), l.Burst)
+	}
+	// Remember the hosts without limit too, to skip the lookup next time.
+	l.buckets[host] = limiter
+
+	return limiter
+}
+
+// limitOf looks up the limit of the host by the full host name, then by the service name
+// and finally falls back to the default limit.
+func (l *ServiceRateLimiter) limitOf(host string) int {
+	if limit, ok := l.Limits[host]; ok {
+		return limit
+	}
+	if limit, ok := l.Limits[serviceNameOfHost(host)]; ok {
+		return limit
+	}
+	return l.Limits[defaultServiceRateLimitKey]
+}
+
+// serviceNameOfHost returns the service name of an endpoint host, which is the first label of the host name,
+// e.g. "ecs" for "ecs.ru-moscow-1.hc.sbercloud.ru:443".
+func serviceNameOfHost(host string) string {
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.SplitN(host, ".", 2)[0]
+}
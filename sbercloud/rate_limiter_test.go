@@ -222,3 +222,203 @@ func TestRateLimitedTransport_ConcurrentRequests(t *testing.T) {
 		t.Errorf("Concurrent requests completed too quickly: %v", duration)
 	}
 }
+
+// roundTripFunc is a stub transport which answers the requests without network access.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func okRoundTripper() http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	})
+}
+
+func TestServiceNameOfHost(t *testing.T) {
+	testCases := map[string]string{
+		"ecs.ru-moscow-1.hc.sbercloud.ru":     "ecs",
+		"ecs.ru-moscow-1.hc.sbercloud.ru:443": "ecs",
+		"vpc.ru-moscow-1.hc.sbercloud.ru":     "vpc",
+		"er.ru-moscow-1.hc.cloud.ru":          "er",
+		"localhost:8080":                      "localhost",
+	}
+
+	for host, expected := range testCases {
+		if got := serviceNameOfHost(host); got != expected {
+			t.Errorf("expected service name %q for host %q, got %q", expected, host, got)
+		}
+	}
+}
+
+func TestServiceRateLimiter_Limits(t *testing.T) {
+	limiter := NewServiceRateLimiter(map[string]int{
+		"ecs":                             20,
+		"vpc":                             50,
+		"dns.ru-moscow-1.hc.sbercloud.ru": 5,
+		"iam":                             0,
+		"default":                         80,
+	}, 3)
+
+	testCases := []struct {
+		host     string
+		expected float64
+		noLimit  bool
+	}{
+		{host: "ecs.ru-moscow-1.hc.sbercloud.ru", expected: 20},
+		{host: "ecs.ru-kazan-1.hc.sbercloud.ru", expected: 20},
+		{host: "VPC.ru-moscow-1.hc.sbercloud.ru", expected: 50},
+		{host: "dns.ru-moscow-1.hc.sbercloud.ru", expected: 5},
+		{host: "dns.ru-kazan-1.hc.sbercloud.ru", expected: 80},
+		{host: "rds.ru-moscow-1.hc.sbercloud.ru", expected: 80},
+		{host: "iam.ru-moscow-1.hc.sbercloud.ru", noLimit: true},
+	}
+
+	for _, tc := range testCases {
+		l := limiter.Limiter(tc.host)
+		if tc.noLimit {
+			if l != nil {
+				t.Errorf("expected no limiter for %s, got %v req/s", tc.host, l.Limit())
+			}
+			continue
+		}
+
+		if l == nil {
+			t.Errorf("expected a limiter for %s", tc.host)
+			continue
+		}
+		if float64(l.Limit()) != tc.expected {
+			t.Errorf("expected %v req/s for %s, got %v", tc.expected, tc.host, l.Limit())
+		}
+		if l.Burst() != 3 {
+			t.Errorf("expected burst 3 for %s, got %d", tc.host, l.Burst())
+		}
+	}
+
+	// The same host always gets the same bucket, the hosts of the same service get their own ones.
+	if limiter.Limiter("ecs.ru-moscow-1.hc.sbercloud.ru") != limiter.Limiter("ecs.ru-moscow-1.hc.sbercloud.ru") {
+		t.Error("Expected the same bucket for the same host")
+	}
+	if limiter.Limiter("ecs.ru-moscow-1.hc.sbercloud.ru") == limiter.Limiter("ecs.ru-kazan-1.hc.sbercloud.ru") {
+		t.Error("Expected separate buckets for different hosts")
+	}
+}
+
+func TestServiceRateLimitedTransport_IndependentBuckets(t *testing.T) {
+	// Test that a slow service does not throttle the requests to the other services
+	testCases := []struct {
+		name        string
+		host        string
+		requests    int
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		// 2 req/s, burst 1: 0 + 4*500ms = 2s
+		{name: "throttled", host: "ecs.ru-moscow-1.hc.sbercloud.ru", requests: 5, minDuration: 1500 * time.Millisecond},
+		// 100 req/s, burst 1: 0 + 19*10ms = 190ms
+		{name: "listed", host: "vpc.ru-moscow-1.hc.sbercloud.ru", requests: 20, maxDuration: time.Second},
+		// not listed, no default limit
+		{name: "unlisted", host: "dns.ru-moscow-1.hc.sbercloud.ru", requests: 20, maxDuration: 500 * time.Millisecond},
+	}
+
+	transport := NewServiceRateLimitedTransport(0, map[string]int{"ecs": 2, "vpc": 100}, 1, okRoundTripper())
+	client := &http.Client{Transport: transport}
+
+	durations := make([]time.Duration, len(testCases))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for i, tc := range testCases {
+		for j := 0; j < tc.requests; j++ {
+			wg.Add(1)
+			go func(i int, url string) {
+				defer wg.Done()
+				resp, err := client.Get(url)
+				if err != nil {
+					t.Errorf("Request to %s failed: %v", url, err)
+					return
+				}
+				resp.Body.Close()
+
+				mu.Lock()
+				if elapsed := time.Since(start); elapsed > durations[i] {
+					durations[i] = elapsed
+				}
+				mu.Unlock()
+			}(i, "https://"+tc.host+"/v1/resources")
+		}
+	}
+	wg.Wait()
+
+	for i, tc := range testCases {
+		if tc.minDuration > 0 && durations[i] < tc.minDuration {
+			t.Errorf("%s: requests completed too quickly: %v (expected >= %v)", tc.name, durations[i], tc.minDuration)
+		}
+		if tc.maxDuration > 0 && durations[i] > tc.maxDuration {
+			t.Errorf("%s: requests took too long: %v (expected <= %v)", tc.name, durations[i], tc.maxDuration)
+		}
+	}
+}
+
+func TestServiceRateLimitedTransport_GlobalLimit(t *testing.T) {
+	// Test that the global limit still applies on top of the per-service limits
+	transport := NewServiceRateLimitedTransport(5, map[string]int{"default": 100}, 1, okRoundTripper())
+	client := &http.Client{Transport: transport}
+
+	start := time.Now()
+	hosts := []string{"ecs", "vpc", "dns", "rds", "evs"}
+	for i := 0; i < 10; i++ {
+		resp, err := client.Get("https://" + hosts[i%len(hosts)] + ".ru-moscow-1.hc.sbercloud.ru/")
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	// 5 req/s globally, burst 1: 0 + 9*200ms = 1.8s
+	if duration := time.Since(start); duration < 1500*time.Millisecond {
+		t.Errorf("Requests completed too quickly: %v (expected >= 1.5s)", duration)
+	}
+}
+
+func TestServiceRateLimitedTransport_Burst(t *testing.T) {
+	// Test that the configured burst lets the first requests pass at once
+	transport := NewServiceRateLimitedTransport(0, map[string]int{"ecs": 1}, 5, okRoundTripper())
+	client := &http.Client{Transport: transport}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get("https://ecs.ru-moscow-1.hc.sbercloud.ru/")
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	if duration := time.Since(start); duration > 500*time.Millisecond {
+		t.Errorf("Burst requests took too long: %v (expected <= 500ms)", duration)
+	}
+}
+
+func TestRateLimitedTransport_WithTransport(t *testing.T) {
+	// Test that the copies of a transport share the token buckets
+	transport := NewServiceRateLimitedTransport(10, map[string]int{"ecs": 1}, 1, nil)
+	base := okRoundTripper()
+	copied := transport.WithTransport(base)
+
+	if copied.Limiter != transport.Limiter {
+		t.Error("Expected the global limiter to be shared")
+	}
+	if copied.ServiceLimiter != transport.ServiceLimiter {
+		t.Error("Expected the service limiter to be shared")
+	}
+	if copied.Transport == nil || copied == transport {
+		t.Error("Expected a new transport wrapping the base transport")
+	}
+}
@@ -0,0 +1,502 @@
+package bss
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+const (
+	// measureGB and measureMbps are the BSS measurement units of the volume and bandwidth sizes
+	measureGB   = 17
+	measureMbps = 15
+	// measureHour is the BSS measurement unit of the usage of pay-per-use resources
+	measureHour = 4
+
+	periodTypeMonth = 2
+	periodTypeYear  = 3
+)
+
+// @API BSS POST /v2/bills/ratings/on-demand-resources
+// @API BSS POST /v2/bills/ratings/period-resources/subscribe-rate
+func DataSourcePriceEstimate() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePriceEstimateRead,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"charging_mode": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"prePaid", "postPaid"}, false),
+			},
+			"period_unit": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"period"},
+				ValidateFunc: validation.StringInSlice([]string{"month", "year"}, false),
+			},
+			"period": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{"period_unit"},
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"compute_instances": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     priceEstimateInstanceSchema(),
+			},
+			"cce_nodes": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     priceEstimateInstanceSchema(),
+			},
+			"volumes": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"volume_type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"quantity": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  1,
+						},
+					},
+				},
+			},
+			"bandwidths": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"size": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"resource_spec": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "19_bgp",
+						},
+						"quantity": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  1,
+						},
+					},
+				},
+			},
+			"rds_instances": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"flavor": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"volume_type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"volume_size": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"quantity": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  1,
+						},
+					},
+				},
+			},
+			"currency": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"total_price": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"total_official_price": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"items": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cloud_service_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource_spec": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"quantity": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"price": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"official_price": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func priceEstimateInstanceSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"flavor_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"os_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "linux",
+				ValidateFunc: validation.StringInSlice([]string{"linux", "windows"}, false),
+			},
+			"availability_zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"system_disk_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"system_disk_size": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  40,
+			},
+			"data_disks": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+					},
+				},
+			},
+			"quantity": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  1,
+			},
+		},
+	}
+}
+
+// priceEstimateProduct is a single product of the inquiry, the id is the path of its specification in the
+// configuration, e.g. compute_instances.0.data_disks.1, and is used to match the prices in the response.
+type priceEstimateProduct struct {
+	id               string
+	cloudServiceType string
+	resourceType     string
+	resourceSpec     string
+	availabilityZone string
+	resourceSize     int
+	sizeMeasureID    int
+	quantity         int
+}
+
+func buildInstanceProducts(prefix string, instances []interface{}) []priceEstimateProduct {
+	products := make([]priceEstimateProduct, 0)
+	for i, v := range instances {
+		instance := v.(map[string]interface{})
+		id := fmt.Sprintf("%s.%d", prefix, i)
+		az := instance["availability_zone"].(string)
+		quantity := instance["quantity"].(int)
+
+		osSuffix := "linux"
+		if instance["os_type"].(string) == "windows" {
+			osSuffix = "win"
+		}
+		products = append(products, priceEstimateProduct{
+			id:               id,
+			cloudServiceType: "hws.service.type.ec2",
+			resourceType:     "hws.resource.type.vm",
+			resourceSpec:     fmt.Sprintf("%s.%s", instance["flavor_id"], osSuffix),
+			availabilityZone: az,
+			quantity:         quantity,
+		})
+
+		if diskType := instance["system_disk_type"].(string); diskType != "" {
+			products = append(products, buildVolumeProduct(id+".system_disk", diskType,
+				instance["system_disk_size"].(int), az, quantity))
+		}
+		for j, disk := range instance["data_disks"].([]interface{}) {
+			dataDisk := disk.(map[string]interface{})
+			products = append(products, buildVolumeProduct(fmt.Sprintf("%s.data_disks.%d", id, j),
+				dataDisk["type"].(string), dataDisk["size"].(int), az, quantity))
+		}
+	}
+	return products
+}
+
+func buildVolumeProduct(id, volumeType string, size int, az string, quantity int) priceEstimateProduct {
+	return priceEstimateProduct{
+		id:               id,
+		cloudServiceType: "hws.service.type.ebs",
+		resourceType:     "hws.resource.type.volume",
+		resourceSpec:     volumeType,
+		availabilityZone: az,
+		resourceSize:     size,
+		sizeMeasureID:    measureGB,
+		quantity:         quantity,
+	}
+}
+
+func buildPriceEstimateProducts(d *schema.ResourceData) []priceEstimateProduct {
+	products := buildInstanceProducts("compute_instances", d.Get("compute_instances").([]interface{}))
+	products = append(products, buildInstanceProducts("cce_nodes", d.Get("cce_nodes").([]interface{}))...)
+
+	for i, v := range d.Get("volumes").([]interface{}) {
+		volume := v.(map[string]interface{})
+		products = append(products, buildVolumeProduct(fmt.Sprintf("volumes.%d", i), volume["volume_type"].(string),
+			volume["size"].(int), volume["availability_zone"].(string), volume["quantity"].(int)))
+	}
+
+	for i, v := range d.Get("bandwidths").([]interface{}) {
+		bandwidth := v.(map[string]interface{})
+		products = append(products, priceEstimateProduct{
+			id:               fmt.Sprintf("bandwidths.%d", i),
+			cloudServiceType: "hws.service.type.vpc",
+			resourceType:     "hws.resource.type.bandwidth",
+			resourceSpec:     bandwidth["resource_spec"].(string),
+			resourceSize:     bandwidth["size"].(int),
+			sizeMeasureID:    measureMbps,
+			quantity:         bandwidth["quantity"].(int),
+		})
+	}
+
+	for i, v := range d.Get("rds_instances").([]interface{}) {
+		instance := v.(map[string]interface{})
+		id := fmt.Sprintf("rds_instances.%d", i)
+		az := instance["availability_zone"].(string)
+		quantity := instance["quantity"].(int)
+		products = append(products,
+			priceEstimateProduct{
+				id:               id,
+				cloudServiceType: "hws.service.type.rds",
+				resourceType:     "hws.resource.type.rds.vm",
+				resourceSpec:     instance["flavor"].(string),
+				availabilityZone: az,
+				quantity:         quantity,
+			},
+			priceEstimateProduct{
+				id:               id + ".volume",
+				cloudServiceType: "hws.service.type.rds",
+				resourceType:     "hws.resource.type.rds.volume",
+				resourceSpec:     instance["volume_type"].(string),
+				availabilityZone: az,
+				resourceSize:     instance["volume_size"].(int),
+				sizeMeasureID:    measureGB,
+				quantity:         quantity,
+			},
+		)
+	}
+	return products
+}
+
+func buildPriceEstimateBodyParams(d *schema.ResourceData, products []priceEstimateProduct, region,
+	projectId string) map[string]interface{} {
+	isPrePaid := d.Get("charging_mode").(string) == "prePaid"
+	periodType := periodTypeMonth
+	if d.Get("period_unit").(string) == "year" {
+		periodType = periodTypeYear
+	}
+
+	productInfos := make([]map[string]interface{}, len(products))
+	for i, product := range products {
+		productInfo := map[string]interface{}{
+			"id":                 product.id,
+			"cloud_service_type": product.cloudServiceType,
+			"resource_type":      product.resourceType,
+			"resource_spec":      product.resourceSpec,
+			"region":             region,
+			"available_zone":     utils.ValueIgnoreEmpty(product.availabilityZone),
+			"resource_size":      utils.ValueIgnoreEmpty(product.resourceSize),
+			"size_measure_id":    utils.ValueIgnoreEmpty(product.sizeMeasureID),
+			"subscription_num":   product.quantity,
+		}
+		if isPrePaid {
+			productInfo["period_type"] = periodType
+			productInfo["period_num"] = d.Get("period")
+		} else {
+			productInfo["usage_factor"] = "Duration"
+			productInfo["usage_value"] = 1
+			productInfo["usage_measure_id"] = measureHour
+		}
+		productInfos[i] = utils.RemoveNil(productInfo)
+	}
+
+	return map[string]interface{}{
+		"project_id":    projectId,
+		"product_infos": productInfos,
+	}
+}
+
+func dataSourcePriceEstimateRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+
+	isPrePaid := d.Get("charging_mode").(string) == "prePaid"
+	if isPrePaid && d.Get("period").(int) == 0 {
+		return diag.Errorf("period and period_unit are required when charging_mode is prePaid")
+	}
+
+	products := buildPriceEstimateProducts(d)
+	if len(products) == 0 {
+		return diag.Errorf("at least one resource specification is required to estimate the price")
+	}
+
+	client, err := cfg.NewServiceClient("bssv2", region)
+	if err != nil {
+		return diag.Errorf("error creating BSS v2 client: %s", err)
+	}
+
+	inquiryHttpUrl := "bills/ratings/on-demand-resources"
+	if isPrePaid {
+		inquiryHttpUrl = "bills/ratings/period-resources/subscribe-rate"
+	}
+	inquiryOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		MoreHeaders:      map[string]string{"Content-Type": "application/json"},
+		JSONBody:         buildPriceEstimateBodyParams(d, products, region, cfg.GetProjectID(region)),
+	}
+	inquiryResp, err := client.Request("POST", client.Endpoint+inquiryHttpUrl, &inquiryOpt)
+	if err != nil {
+		return diag.Errorf("error querying the price: %s", err)
+	}
+
+	inquiryRespBody, err := utils.FlattenResponse(inquiryResp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var totalPrice, totalOfficialPrice interface{}
+	var prices, officialPrices map[string]interface{}
+	if isPrePaid {
+		totalPrice, totalOfficialPrice, prices, officialPrices = flattenPeriodRatingResults(inquiryRespBody)
+	} else {
+		totalPrice = utils.PathSearch("amount", inquiryRespBody, nil)
+		totalOfficialPrice = utils.PathSearch("official_website_amount", inquiryRespBody, nil)
+		prices = flattenProductRatingResults(inquiryRespBody, "product_rating_results", "amount")
+		officialPrices = flattenProductRatingResults(inquiryRespBody, "product_rating_results",
+			"official_website_amount")
+	}
+
+	items := make([]interface{}, len(products))
+	for i, product := range products {
+		items[i] = map[string]interface{}{
+			"id":                 product.id,
+			"cloud_service_type": product.cloudServiceType,
+			"resource_type":      product.resourceType,
+			"resource_spec":      product.resourceSpec,
+			"quantity":           product.quantity,
+			"price":              prices[product.id],
+			"official_price":     officialPrices[product.id],
+		}
+	}
+
+	dataSourceId, err := uuid.GenerateUUID()
+	if err != nil {
+		return diag.Errorf("unable to generate ID: %s", err)
+	}
+	d.SetId(dataSourceId)
+
+	mErr := multierror.Append(nil,
+		d.Set("region", region),
+		d.Set("currency", utils.PathSearch("currency", inquiryRespBody, nil)),
+		d.Set("total_price", totalPrice),
+		d.Set("total_official_price", totalOfficialPrice),
+		d.Set("items", items),
+	)
+	return diag.FromErr(mErr.ErrorOrNil())
+}
+
+// flattenPeriodRatingResults returns the prices of the best offer of the yearly/monthly inquiry,
+// or the official website prices if there are no discounts.
+func flattenPeriodRatingResults(resp interface{}) (totalPrice, totalOfficialPrice interface{},
+	prices, officialPrices map[string]interface{}) {
+	officialResult := utils.PathSearch("official_website_rating_result", resp, nil)
+	totalOfficialPrice = utils.PathSearch("official_website_amount", officialResult, nil)
+	officialPrices = flattenProductRatingResults(officialResult, "product_rating_results",
+		"official_website_amount")
+
+	bestOffer := utils.PathSearch("optional_discount_rating_results[?best_offer==`1`]|[0]", resp, nil)
+	if bestOffer == nil {
+		return totalOfficialPrice, totalOfficialPrice, officialPrices, officialPrices
+	}
+	totalPrice = utils.PathSearch("amount", bestOffer, nil)
+	prices = flattenProductRatingResults(bestOffer, "product_rating_results", "amount")
+	return totalPrice, totalOfficialPrice, prices, officialPrices
+}
+
+func flattenProductRatingResults(resp interface{}, path, amountKey string) map[string]interface{} {
+	results := utils.PathSearch(path, resp, make([]interface{}, 0)).([]interface{})
+	prices := make(map[string]interface{}, len(results))
+	for _, v := range results {
+		prices[fmt.Sprint(utils.PathSearch("id", v, nil))] = utils.PathSearch(amountKey, v, nil)
+	}
+	return prices
+}
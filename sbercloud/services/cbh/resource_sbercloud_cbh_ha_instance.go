@@ -120,10 +120,24 @@ func ResourceCBHHAInstance() *schema.Resource {
 				Description: `Specifies the availability zone name of the slave instance.`,
 			},
 			"password": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"password", "password_wo"},
+				Description:  `Specifies the password for logging in to the management console.`,
+			},
+			"password_wo": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				Sensitive:   true,
-				Description: `Specifies the password for logging in to the management console.`,
+				WriteOnly:   true,
+				Description: `Specifies the write-only password for logging in to the management console.`,
+			},
+			"password_wo_version": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{"password_wo"},
+				Description:  `Specifies the version of the write-only password, change it to update the password.`,
 			},
 			"charging_mode": {
 				Type:        schema.TypeString,
@@ -356,7 +370,7 @@ func buildCreateHAInstanceBodyParam(d *schema.ResourceData, region string, epsId
 	bodyParam := map[string]interface{}{
 		"specification":           d.Get("flavor_id"),
 		"instance_name":           d.Get("name"),
-		"password":                d.Get("password"),
+		"password":                getCBHInstancePassword(d),
 		"region":                  region,
 		"availability_zone":       d.Get("master_availability_zone"),
 		"slave_availability_zone": d.Get("slave_availability_zone"),
@@ -712,7 +726,7 @@ func resourceHAInstanceUpdate(ctx context.Context, d *schema.ResourceData, meta
 		}
 	}
 
-	if d.HasChanges("password") {
+	if d.HasChanges("password", "password_wo_version") {
 		if err = updateHAInstancePassword(client, d, masterId); err != nil {
 			return diag.FromErr(err)
 		}
@@ -960,7 +974,7 @@ func updateHAInstancePassword(client *golangsdk.ServiceClient, d *schema.Resourc
 
 func buildUpdateHAInstanceAdminPasswordParams(d *schema.ResourceData, masterId string) map[string]interface{} {
 	return map[string]interface{}{
-		"new_password": d.Get("password").(string),
+		"new_password": getCBHInstancePassword(d),
 		"server_id":    masterId,
 	}
 }
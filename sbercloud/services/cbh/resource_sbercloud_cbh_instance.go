@@ -105,10 +105,24 @@ func ResourceCBHInstance() *schema.Resource {
 				Description: `Specifies the availability zone name.`,
 			},
 			"password": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ExactlyOneOf: []string{"password", "password_wo"},
+				Description:  `Specifies the password for logging in to the management console.`,
+			},
+			"password_wo": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				Sensitive:   true,
-				Description: `Specifies the password for logging in to the management console.`,
+				WriteOnly:   true,
+				Description: `Specifies the write-only password for logging in to the management console.`,
+			},
+			"password_wo_version": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{"password_wo"},
+				Description:  `Specifies the version of the write-only password, change it to update the password.`,
 			},
 			"charging_mode": {
 				Type:     schema.TypeString,
@@ -303,7 +317,7 @@ func buildCreateCBHInstanceBodyParam(d *schema.ResourceData, region string, epsI
 	bodyParam := map[string]interface{}{
 		"specification":         d.Get("flavor_id"),
 		"instance_name":         d.Get("name"),
-		"password":              d.Get("password"),
+		"password":              getCBHInstancePassword(d),
 		"region":                region,
 		"availability_zone":     d.Get("availability_zone"),
 		"charging_mode":         buildCreateChargingModeParam(d),
@@ -710,7 +724,7 @@ func resourceCBHInstanceUpdate(ctx context.Context, d *schema.ResourceData, meta
 		}
 	}
 
-	if d.HasChanges("password") {
+	if d.HasChanges("password", "password_wo_version") {
 		if err = updatePassword(client, d); err != nil {
 			return diag.FromErr(err)
 		}
@@ -1060,9 +1074,18 @@ func updatePassword(client *golangsdk.ServiceClient, d *schema.ResourceData) err
 	return nil
 }
 
+// getCBHInstancePassword returns the write-only password from the configuration if it is specified,
+// otherwise the password which is saved in the state.
+func getCBHInstancePassword(d *schema.ResourceData) string {
+	if v, ok := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "password_wo").(string); ok && v != "" {
+		return v
+	}
+	return d.Get("password").(string)
+}
+
 func buildUpdateAdminPasswordParams(d *schema.ResourceData) map[string]interface{} {
 	return map[string]interface{}{
-		"new_password": d.Get("password"),
+		"new_password": getCBHInstancePassword(d),
 		"server_id":    d.Id(),
 	}
 }
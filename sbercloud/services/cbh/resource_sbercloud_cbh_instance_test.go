@@ -0,0 +1,108 @@
+package cbh
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+// buildTestConfig returns the configuration of the resource, the attributes which are not in raw are null.
+func buildTestConfig(t *testing.T, res *schema.Resource, raw map[string]interface{}) (cty.Value,
+	*terraform.ResourceConfig) {
+	coreSchema := res.CoreConfigSchema()
+	b, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatalf("error marshaling the configuration: %s", err)
+	}
+	configVal, err := ctyjson.Unmarshal(b, coreSchema.ImpliedType())
+	if err != nil {
+		t.Fatalf("error building the configuration: %s", err)
+	}
+	return configVal, terraform.NewResourceConfigShimmed(configVal, coreSchema)
+}
+
+// planTestConfig plans the configuration against the state which was created from the same configuration.
+func planTestConfig(t *testing.T, res *schema.Resource, raw map[string]interface{}) *terraform.InstanceDiff {
+	configVal, cfg := buildTestConfig(t, res, raw)
+	if diags := res.Validate(cfg); diags.HasError() {
+		t.Fatalf("the configuration is invalid: %v", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, res.Schema, raw)
+	d.SetId("instance-id")
+	// the computed tags are always read back into the state
+	if _, ok := res.Schema["tags"]; ok {
+		if err := d.Set("tags", map[string]interface{}{}); err != nil {
+			t.Fatalf("error setting the tags: %s", err)
+		}
+	}
+	state := d.State()
+	state.RawConfig = configVal
+
+	diff, err := res.SimpleDiff(context.Background(), state, cfg, &config.Config{})
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	return diff
+}
+
+func TestResourceCBHInstance_passwordNoDiff(t *testing.T) {
+	resources := map[string]struct {
+		res *schema.Resource
+		raw map[string]interface{}
+	}{
+		"sbercloud_cbh_instance": {
+			res: ResourceCBHInstance(),
+			raw: map[string]interface{}{
+				"availability_zone": "ru-moscow-1a",
+			},
+		},
+		"sbercloud_cbh_ha_instance": {
+			res: ResourceCBHHAInstance(),
+			raw: map[string]interface{}{
+				"master_availability_zone": "ru-moscow-1a",
+				"slave_availability_zone":  "ru-moscow-1b",
+			},
+		},
+	}
+
+	for name, tc := range resources {
+		raw := map[string]interface{}{
+			"name":              "cbh-test",
+			"flavor_id":         "cbh.basic.10",
+			"vpc_id":            "vpc-id",
+			"subnet_id":         "subnet-id",
+			"security_group_id": "secgroup-id",
+			"password":          "Test@123456",
+			"charging_mode":     "prePaid",
+			"period_unit":       "month",
+			"period":            1,
+		}
+		for k, v := range tc.raw {
+			raw[k] = v
+		}
+
+		if diff := planTestConfig(t, tc.res, raw); diff != nil && !diff.Empty() {
+			t.Fatalf("%s: the configuration with password should not produce a diff, got %#v", name,
+				diff.Attributes)
+		}
+
+		delete(raw, "password")
+		if _, cfg := buildTestConfig(t, tc.res, raw); !tc.res.Validate(cfg).HasError() {
+			t.Fatalf("%s: the configuration without password and password_wo should be invalid", name)
+		}
+
+		raw["password"] = "Test@123456"
+		raw["password_wo"] = "Test@654321"
+		if _, cfg := buildTestConfig(t, tc.res, raw); !tc.res.Validate(cfg).HasError() {
+			t.Fatalf("%s: the configuration with both password and password_wo should be invalid", name)
+		}
+	}
+}
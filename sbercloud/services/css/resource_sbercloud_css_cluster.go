@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -56,14 +57,28 @@ var cssClusterSchema = map[string]*schema.Schema{
 		Type:     schema.TypeString,
 		Required: true,
 	},
+	"engine_upgrade": engineUpgradeSchema(),
 	"security_mode": {
 		Type:     schema.TypeBool,
 		Optional: true,
 	},
 	"password": {
-		Type:      schema.TypeString,
-		Sensitive: true,
-		Optional:  true,
+		Type:          schema.TypeString,
+		Sensitive:     true,
+		Optional:      true,
+		ConflictsWith: []string{"password_wo"},
+	},
+	"password_wo": {
+		Type:          schema.TypeString,
+		Sensitive:     true,
+		Optional:      true,
+		WriteOnly:     true,
+		ConflictsWith: []string{"password"},
+	},
+	"password_wo_version": {
+		Type:         schema.TypeInt,
+		Optional:     true,
+		RequiredWith: []string{"password_wo"},
 	},
 	"https_enabled": {
 		Type:         schema.TypeBool,
@@ -167,10 +182,9 @@ var cssClusterSchema = map[string]*schema.Schema{
 		},
 	},
 	"public_access": {
-		Type:         schema.TypeList,
-		Optional:     true,
-		MaxItems:     1,
-		RequiredWith: []string{"password"},
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
 				"bandwidth": {
@@ -212,10 +226,9 @@ var cssClusterSchema = map[string]*schema.Schema{
 		},
 	},
 	"kibana_public_access": {
-		Type:         schema.TypeList,
-		Optional:     true,
-		MaxItems:     1,
-		RequiredWith: []string{"password"},
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
 				"bandwidth": { // Mbit/s
@@ -244,7 +257,8 @@ var cssClusterSchema = map[string]*schema.Schema{
 		Optional: true,
 		Computed: true,
 	},
-	// charging_mode, period_unit and period only support changing post-paid to pre-paid billing mode.
+	// charging_mode can only be changed from postPaid to prePaid, period_unit and period are used by the change.
+	// There is no API to change a prePaid cluster to postPaid, so postPaid is only set by omitting the argument.
 	"charging_mode": {
 		Type:     schema.TypeString,
 		Optional: true,
@@ -267,6 +281,14 @@ var cssClusterSchema = map[string]*schema.Schema{
 		RequiredWith: []string{"period_unit"},
 	},
 	"auto_renew": common.SchemaAutoRenewUpdatable(nil),
+	// auto_pay only applies to the orders created later, so changing it does not recreate the cluster.
+	"auto_pay": {
+		Type:     schema.TypeString,
+		Optional: true,
+		ValidateFunc: validation.StringInSlice([]string{
+			"true", "false",
+		}, false),
+	},
 	"expect_node_num": {
 		Type:       schema.TypeInt,
 		Optional:   true,
@@ -433,7 +455,7 @@ var cssClusterSchema = map[string]*schema.Schema{
 	},
 }
 
-var clusterNonUpdatableParams = []string{"engine_version", "availability_zone"}
+var clusterNonUpdatableParams = []string{"availability_zone"}
 
 // @API CSS POST /v1.0/{project_id}/clusters/{cluster_id}/role_extend
 // @API CSS POST /v1.0/{project_id}/clusters
@@ -469,6 +491,10 @@ var clusterNonUpdatableParams = []string{"engine_version", "availability_zone"}
 // @API CSS POST /v1.0/{project_id}/cluster/{cluster_id}/period
 // @API CSS POST /v1.0/extend/{project_id}/clusters/{cluster_id}/role/shrink
 // @API CSS POST /v1.0/{project_id}/clusters/{cluster_id}/node/offline
+// @API CSS GET /v1.0/{project_id}/clusters/{cluster_id}/target/{upgrade_type}/images
+// @API CSS POST /v1.0/{project_id}/clusters/{cluster_id}/inst-type/{inst_type}/image/upgrade
+// @API CSS GET /v1.0/{project_id}/clusters/{cluster_id}/upgrade/detail
+// @API CSS PUT /v1.0/{project_id}/clusters/{cluster_id}/upgrade/{action_id}/retry
 // @API BSS GET /v2/orders/customer-orders/details/{order_id}
 // @API BSS POST /v2/orders/suscriptions/resources/query
 // @API BSS POST /v2/orders/subscriptions/resources/autorenew/{instance_id}
@@ -489,7 +515,13 @@ func ResourceCssCluster() *schema.Resource {
 			Delete: schema.DefaultTimeout(60 * time.Minute),
 		},
 
-		CustomizeDiff: config.FlexibleForceNew(clusterNonUpdatableParams, cssClusterSchema),
+		CustomizeDiff: customdiff.All(
+			config.FlexibleForceNew(clusterNonUpdatableParams, cssClusterSchema),
+			checkEngineUpgrade,
+			checkChargingModeChange,
+			checkPublicAccessPassword,
+			config.MergeDefaultTags(),
+		),
 
 		Schema: cssClusterSchema,
 	}
@@ -709,7 +741,7 @@ func buildClusterCreateParameters(d *schema.ResourceData, conf *config.Config) (
 
 	securityMode := d.Get("security_mode").(bool)
 	if securityMode {
-		adminPassword := d.Get("password").(string)
+		adminPassword := getAdminPassword(d)
 		if adminPassword == "" {
 			return nil, fmt.Errorf("administrator password is required in security mode")
 		}
@@ -760,7 +792,7 @@ func buildClusterCreateParameters(d *schema.ResourceData, conf *config.Config) (
 	if payModel, ok := d.GetOk("period_unit"); ok && d.Get("charging_mode").(string) != "postPaid" {
 		payInfo := map[string]interface{}{
 			"period":    d.Get("period"),
-			"isAutoPay": 1,
+			"isAutoPay": buildClusterIsAutoPay(d),
 		}
 
 		if payModel == "month" {
@@ -854,6 +886,7 @@ func resourceCssClusterRead(_ context.Context, d *schema.ResourceData, meta inte
 		d.Set("updated_at", utils.PathSearch("updated", clusterDetail, nil)),
 		d.Set("bandwidth_resource_id", utils.PathSearch("bandwidthResourceId", clusterDetail, nil)),
 		d.Set("is_period", utils.PathSearch("period", clusterDetail, nil)),
+		d.Set("charging_mode", flattenChargingMode(clusterDetail)),
 		d.Set("backup_available", utils.PathSearch("backupAvailable", clusterDetail, nil)),
 		d.Set("disk_encrypted", utils.PathSearch("diskEncrypted", clusterDetail, nil)),
 	)
@@ -1153,6 +1186,14 @@ func resourceCssClusterUpdate(ctx context.Context, d *schema.ResourceData, meta
 		return diag.Errorf("error creating CSS V1 client: %s", err)
 	}
 
+	// upgrade the engine before changing the nodes, the new nodes are created with the image of the engine version
+	if d.HasChange("engine_version") {
+		err = upgradeClusterEngine(ctx, d, client)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	nodeConfigChanges := []string{
 		"ess_node_config",
 		"master_node_config",
@@ -1214,17 +1255,17 @@ func resourceCssClusterUpdate(ctx context.Context, d *schema.ResourceData, meta
 		}
 	}
 
-	//if d.HasChanges("charging_mode", "auto_renew") {
-	//	bssClient, err := cfg.BssV2Client(region)
-	//	if err != nil {
-	//		return diag.Errorf("error creating BSS V2 client: %s", err)
-	//	}
-	//
-	//	err = updateChangingModeOrAutoRenew(ctx, d, client, bssClient)
-	//	if err != nil {
-	//		return diag.FromErr(err)
-	//	}
-	//}
+	if d.HasChanges("charging_mode", "auto_renew") {
+		bssClient, err := cfg.BssV2Client(region)
+		if err != nil {
+			return diag.Errorf("error creating BSS V2 client: %s", err)
+		}
+
+		err = updateChargingModeOrAutoRenew(ctx, d, client, bssClient)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	if d.HasChange("enterprise_project_id") {
 		migrateOpts := config.MigrateResourceOpts{
@@ -1243,7 +1284,7 @@ func resourceCssClusterUpdate(ctx context.Context, d *schema.ResourceData, meta
 
 func updateInSafeMode(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient) error {
 	// reset admin pasword
-	if d.HasChange("password") {
+	if d.HasChanges("password", "password_wo_version") {
 		err := updateAdminPassword(ctx, d, client)
 		if err != nil {
 			return err
@@ -2507,7 +2548,7 @@ func addMastersOrClients(ctx context.Context, d *schema.ResourceData,
 }
 
 func updateSafeMode(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient) error {
-	adminPassword := d.Get("password").(string)
+	adminPassword := getAdminPassword(d)
 	if d.Get("security_mode").(bool) && adminPassword == "" {
 		return fmt.Errorf("administrator password is required when security mode changes")
 	}
@@ -2549,8 +2590,35 @@ func buildUpdateSafeModeParams(d *schema.ResourceData, adminPassword string) map
 	return body
 }
 
+// getAdminPassword returns the write-only administrator password from the configuration if it is specified,
+// otherwise the password which is saved in the state.
+func getAdminPassword(d *schema.ResourceData) string {
+	if v, ok := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "password_wo").(string); ok && v != "" {
+		return v
+	}
+	return d.Get("password").(string)
+}
+
+// checkPublicAccessPassword requires the administrator password for the public access, as RequiredWith can not
+// accept either password or password_wo.
+func checkPublicAccessPassword(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil
+	}
+	if !rawConfig.GetAttr("password").IsNull() || !rawConfig.GetAttr("password_wo").IsNull() {
+		return nil
+	}
+	for _, key := range []string{"public_access", "kibana_public_access"} {
+		if v := rawConfig.GetAttr(key); !v.IsNull() && (!v.IsKnown() || v.LengthInt() > 0) {
+			return fmt.Errorf("password or password_wo must be specified when %s is specified", key)
+		}
+	}
+	return nil
+}
+
 func updateAdminPassword(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient) error {
-	adminPassword := d.Get("password").(string)
+	adminPassword := getAdminPassword(d)
 	if adminPassword == "" {
 		return fmt.Errorf("administrator password is required when security mode changes")
 	}
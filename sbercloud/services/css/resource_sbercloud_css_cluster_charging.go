@@ -0,0 +1,130 @@
+package css
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/common"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// checkChargingModeChange rejects the billing changes which the cluster does not support before they are applied.
+func checkChargingModeChange(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("charging_mode") {
+		return nil
+	}
+	oldMode, _ := d.GetChange("charging_mode")
+	// charging_mode is computed, it is empty in the state of the clusters which are not read after this is supported.
+	// The new value is always prePaid, as postPaid can not be set in the configuration.
+	if oldMode.(string) == "" {
+		return nil
+	}
+	if d.Get("period_unit").(string) == "" {
+		return fmt.Errorf("period and period_unit must be specified to change the charging mode to prePaid")
+	}
+	return nil
+}
+
+func flattenChargingMode(clusterDetail interface{}) string {
+	if utils.PathSearch("period", clusterDetail, false).(bool) {
+		return "prePaid"
+	}
+	return "postPaid"
+}
+
+// buildClusterIsAutoPay returns whether the order is paid automatically, which is the default.
+func buildClusterIsAutoPay(d *schema.ResourceData) int {
+	if common.GetAutoPay(d) == "false" {
+		return 0
+	}
+	return 1
+}
+
+func buildClusterToPeriodBodyParams(d *schema.ResourceData) map[string]interface{} {
+	bodyParams := map[string]interface{}{
+		"periodNum": d.Get("period"),
+		"isAutoPay": buildClusterIsAutoPay(d),
+	}
+	if d.Get("period_unit").(string) == "month" {
+		bodyParams["periodType"] = 2
+	} else {
+		bodyParams["periodType"] = 3
+	}
+	if d.Get("auto_renew").(string) == "true" {
+		bodyParams["isAutoRenew"] = 1
+	}
+	return bodyParams
+}
+
+func updateChargingModeOrAutoRenew(ctx context.Context, d *schema.ResourceData,
+	client, bssClient *golangsdk.ServiceClient) error {
+	clusterId := d.Id()
+	if d.HasChange("charging_mode") && !d.Get("is_period").(bool) {
+		// the order creates the subscription with the period and the auto-renew
+		return updateClusterToPeriod(ctx, d, client, bssClient)
+	}
+
+	if d.HasChange("auto_renew") && d.Get("charging_mode").(string) == "prePaid" {
+		if err := common.UpdateAutoRenew(bssClient, d.Get("auto_renew").(string), clusterId); err != nil {
+			return fmt.Errorf("error updating the auto-renew of CSS cluster (%s): %s", clusterId, err)
+		}
+	}
+	return nil
+}
+
+func updateClusterToPeriod(ctx context.Context, d *schema.ResourceData,
+	client, bssClient *golangsdk.ServiceClient) error {
+	clusterId := d.Id()
+	updatePath := client.Endpoint + "v1.0/{project_id}/cluster/{cluster_id}/period"
+	updatePath = strings.ReplaceAll(updatePath, "{project_id}", client.ProjectID)
+	updatePath = strings.ReplaceAll(updatePath, "{cluster_id}", clusterId)
+	updateOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		MoreHeaders:      map[string]string{"Content-Type": "application/json"},
+		JSONBody:         buildClusterToPeriodBodyParams(d),
+	}
+	resp, err := client.Request("POST", updatePath, &updateOpt)
+	if err != nil {
+		return fmt.Errorf("error changing the charging mode of CSS cluster (%s) to prePaid: %s", clusterId, err)
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return err
+	}
+	orderId := utils.PathSearch("orderId", respBody, "").(string)
+	if orderId == "" {
+		return fmt.Errorf("unable to find the order ID of changing the charging mode of CSS cluster (%s)", clusterId)
+	}
+
+	err = common.WaitOrderComplete(ctx, bssClient, orderId, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return err
+	}
+
+	// the cluster is reported as prePaid a while after the order is paid
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"postPaid"},
+		Target:  []string{"prePaid"},
+		Refresh: func() (interface{}, string, error) {
+			clusterDetail, err := getClusterDetails(client, clusterId)
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			return clusterDetail, flattenChargingMode(clusterDetail), nil
+		},
+		Timeout:      d.Timeout(schema.TimeoutUpdate),
+		Delay:        5 * time.Second,
+		PollInterval: 10 * time.Second,
+	}
+	if _, err = stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error waiting for the charging mode of CSS cluster (%s) to be changed: %s", clusterId, err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package css
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+func TestGetClusterUpgradeImageId(t *testing.T) {
+	images := []interface{}{
+		map[string]interface{}{
+			"id": "image-7.10.2-old", "datastoreType": "elasticsearch", "datastoreVersion": "7.10.2",
+			"priority": float64(1),
+		},
+		map[string]interface{}{
+			"id": "image-7.10.2", "datastoreType": "elasticsearch", "datastoreVersion": "7.10.2",
+			"priority": float64(2),
+		},
+		map[string]interface{}{
+			"id": "image-7.9.3", "datastoreType": "elasticsearch", "datastoreVersion": "7.9.3",
+			"priority": float64(1),
+		},
+		map[string]interface{}{
+			"id": "image-opensearch", "datastoreType": "opensearch", "datastoreVersion": "1.3.6",
+			"priority": float64(1),
+		},
+	}
+
+	imageId, err := getClusterUpgradeImageId(images, "elasticsearch", "7.10.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if imageId != "image-7.10.2" {
+		t.Errorf("expected the image with the highest priority, got %q", imageId)
+	}
+
+	_, err = getClusterUpgradeImageId(images, "elasticsearch", "8.0.0")
+	if err == nil || !strings.Contains(err.Error(), "the available versions are: 7.10.2, 7.9.3") {
+		t.Errorf("expected the error to list the available versions, got: %v", err)
+	}
+}
+
+func TestFlattenClusterUpgradeProgress(t *testing.T) {
+	if progress := flattenClusterUpgradeProgress(nil); progress != "" {
+		t.Errorf("expected no progress, got %q", progress)
+	}
+
+	progress := flattenClusterUpgradeProgress(map[string]interface{}{
+		"status":            "FAILED",
+		"totalNodes":        "3",
+		"completedNodes":    "1",
+		"currentNodeName":   "css-test-ess-esn-2-1",
+		"currentNodeDetail": "the indices are not backed up",
+	})
+	expected := "; 1 of 3 nodes are upgraded, the upgrade of node css-test-ess-esn-2-1 failed: " +
+		"the indices are not backed up"
+	if progress != expected {
+		t.Errorf("expected %q, got %q", expected, progress)
+	}
+}
+
+func TestClusterUpgradeTaskRefreshFunc(t *testing.T) {
+	responses := []string{
+		`{}`,
+		`{"detailList": [{"id": "task-old", "status": "SUCCESS"}]}`,
+		`{"detailList": [{"id": "task-new", "status": "RUNNING"}, {"id": "task-old", "status": "SUCCESS"}]}`,
+	}
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, responses[calls])
+		calls++
+	}))
+	defer server.Close()
+	client := &golangsdk.ServiceClient{
+		ProviderClient: &golangsdk.ProviderClient{},
+		Endpoint:       server.URL + "/",
+	}
+
+	refresh := clusterUpgradeTaskRefreshFunc(client, "cluster-id", []string{"task-old"})
+	for i, expected := range []string{"PENDING", "PENDING", "CREATED"} {
+		result, state, err := refresh()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result == nil {
+			t.Fatalf("expected a result of the refresh %d", i)
+		}
+		if state != expected {
+			t.Errorf("expected the state %s of the refresh %d, got %s", expected, i, state)
+		}
+		if state == "CREATED" && result != "task-new" {
+			t.Errorf("expected the task task-new, got %v", result)
+		}
+	}
+}
+
+func TestBuildClusterToPeriodBodyParams(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "month",
+			raw:  map[string]interface{}{"charging_mode": "prePaid", "period_unit": "month", "period": 3},
+			expected: map[string]interface{}{
+				"periodNum": 3, "periodType": 2, "isAutoPay": 1,
+			},
+		},
+		{
+			name: "year with auto-renew",
+			raw: map[string]interface{}{
+				"charging_mode": "prePaid", "period_unit": "year", "period": 1, "auto_renew": "true",
+			},
+			expected: map[string]interface{}{
+				"periodNum": 1, "periodType": 3, "isAutoPay": 1, "isAutoRenew": 1,
+			},
+		},
+		{
+			name: "without auto-pay",
+			raw: map[string]interface{}{
+				"charging_mode": "prePaid", "period_unit": "month", "period": 1, "auto_pay": "false",
+			},
+			expected: map[string]interface{}{
+				"periodNum": 1, "periodType": 2, "isAutoPay": 0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, ResourceCssCluster().Schema, tc.raw)
+			if params := buildClusterToPeriodBodyParams(d); !reflect.DeepEqual(params, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, params)
+			}
+		})
+	}
+}
+
+func TestCssClusterChargingModeValidation(t *testing.T) {
+	validate := ResourceCssCluster().Schema["charging_mode"].ValidateFunc
+	if _, errs := validate("prePaid", "charging_mode"); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	// a prePaid cluster can not be changed to postPaid
+	if _, errs := validate("postPaid", "charging_mode"); len(errs) == 0 {
+		t.Errorf("expected postPaid to be rejected")
+	}
+}
+
+func TestFlattenChargingMode(t *testing.T) {
+	if mode := flattenChargingMode(map[string]interface{}{"period": true}); mode != "prePaid" {
+		t.Errorf("expected prePaid, got %s", mode)
+	}
+	if mode := flattenChargingMode(map[string]interface{}{"period": false}); mode != "postPaid" {
+		t.Errorf("expected postPaid, got %s", mode)
+	}
+	if mode := flattenChargingMode(map[string]interface{}{}); mode != "postPaid" {
+		t.Errorf("expected postPaid, got %s", mode)
+	}
+}
+
+func TestCheckPublicAccessPassword(t *testing.T) {
+	cases := map[string]struct {
+		raw       map[string]interface{}
+		expectErr bool
+	}{
+		"no public access": {
+			raw: map[string]interface{}{},
+		},
+		"public access without password": {
+			raw: map[string]interface{}{
+				"public_access": []interface{}{map[string]interface{}{"bandwidth": 5, "whitelist_enabled": false}},
+			},
+			expectErr: true,
+		},
+		"kibana public access without password": {
+			raw: map[string]interface{}{
+				"kibana_public_access": []interface{}{map[string]interface{}{"bandwidth": 5, "whitelist_enabled": false}},
+			},
+			expectErr: true,
+		},
+		"public access with password": {
+			raw: map[string]interface{}{
+				"password":      "Test@123456",
+				"public_access": []interface{}{map[string]interface{}{"bandwidth": 5, "whitelist_enabled": false}},
+			},
+		},
+		"public access with write-only password": {
+			raw: map[string]interface{}{
+				"password_wo":   "Test@123456",
+				"public_access": []interface{}{map[string]interface{}{"bandwidth": 5, "whitelist_enabled": false}},
+			},
+		},
+	}
+
+	res := ResourceCssCluster()
+	coreSchema := res.CoreConfigSchema()
+	for name, tc := range cases {
+		tc.raw["name"] = "css-test"
+		tc.raw["engine_version"] = "7.10.2"
+		b, err := json.Marshal(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: error marshaling the configuration: %s", name, err)
+		}
+		configVal, err := ctyjson.Unmarshal(b, coreSchema.ImpliedType())
+		if err != nil {
+			t.Fatalf("%s: error building the configuration: %s", name, err)
+		}
+
+		state := &terraform.InstanceState{RawConfig: configVal}
+		_, err = res.SimpleDiff(context.Background(), state, terraform.NewResourceConfigShimmed(configVal, coreSchema),
+			&config.Config{})
+		if tc.expectErr != (err != nil) {
+			t.Fatalf("%s: expected error %v, got %v", name, tc.expectErr, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package css
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+func engineUpgradeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"agency": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"upgrade_type": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "cross",
+					ValidateFunc: validation.StringInSlice([]string{"same", "cross"}, false),
+				},
+				"indices_backup_check": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+				"cluster_load_check": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  true,
+				},
+			},
+		},
+	}
+}
+
+// checkEngineUpgrade requires the upgrade settings when the engine version of an existing cluster is changed.
+func checkEngineUpgrade(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("engine_version") {
+		return nil
+	}
+	if rawList := d.Get("engine_upgrade").([]interface{}); len(rawList) == 0 || rawList[0] == nil {
+		oldVersion, newVersion := d.GetChange("engine_version")
+		return fmt.Errorf("engine_upgrade must be specified to upgrade the engine from %v to %v", oldVersion,
+			newVersion)
+	}
+	return nil
+}
+
+// upgradeClusterEngine upgrades the nodes of the cluster one by one to the image of the engine version.
+func upgradeClusterEngine(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient) error {
+	clusterId := d.Id()
+	rawList := d.Get("engine_upgrade").([]interface{})
+	if len(rawList) == 0 || rawList[0] == nil {
+		oldVersion, newVersion := d.GetChange("engine_version")
+		return fmt.Errorf("engine_upgrade must be specified to upgrade the engine of CSS cluster (%s) from %v to %v",
+			clusterId, oldVersion, newVersion)
+	}
+	upgrade := rawList[0].(map[string]interface{})
+	upgradeType := upgrade["upgrade_type"].(string)
+
+	images, err := listClusterUpgradeImages(client, clusterId, upgradeType)
+	if err != nil {
+		return err
+	}
+	imageId, err := getClusterUpgradeImageId(images, d.Get("engine_type").(string), d.Get("engine_version").(string))
+	if err != nil {
+		return fmt.Errorf("error upgrading the engine of CSS cluster (%s): %s", clusterId, err)
+	}
+
+	// the cluster can not be upgraded until the other operations complete
+	err = checkClusterOperationResult(ctx, client, clusterId, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return err
+	}
+	previousTaskIds, err := listClusterUpgradeTaskIds(client, clusterId)
+	if err != nil {
+		return fmt.Errorf("error retrieving the upgrade tasks of CSS cluster (%s): %s", clusterId, err)
+	}
+
+	upgradePath := client.Endpoint + "v1.0/{project_id}/clusters/{cluster_id}/inst-type/{inst_type}/image/upgrade"
+	upgradePath = strings.ReplaceAll(upgradePath, "{project_id}", client.ProjectID)
+	upgradePath = strings.ReplaceAll(upgradePath, "{cluster_id}", clusterId)
+	// all the nodes of the cluster are upgraded
+	upgradePath = strings.ReplaceAll(upgradePath, "{inst_type}", "all")
+	upgradeOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"target_image_id":      imageId,
+			"upgrade_type":         upgradeType,
+			"agency":               upgrade["agency"],
+			"indices_backup_check": upgrade["indices_backup_check"],
+			"cluster_load_check":   upgrade["cluster_load_check"],
+		},
+	}
+	_, err = client.Request("POST", upgradePath, &upgradeOpt)
+	if err != nil {
+		return fmt.Errorf("error upgrading the engine of CSS cluster (%s): %s", clusterId, err)
+	}
+
+	taskId, err := waitForClusterUpgradeTask(ctx, client, clusterId, previousTaskIds, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return err
+	}
+
+	err = waitForClusterUpgradeCompleted(ctx, client, clusterId, taskId, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		// the nodes which are not upgraded keep the previous version
+		if abortErr := abortClusterUpgrade(client, clusterId, taskId); abortErr != nil {
+			return fmt.Errorf("%s, and error aborting the upgrade task: %s", err, abortErr)
+		}
+		return err
+	}
+
+	return checkClusterOperationResult(ctx, client, clusterId, d.Timeout(schema.TimeoutUpdate))
+}
+
+func listClusterUpgradeImages(client *golangsdk.ServiceClient, clusterId, upgradeType string) ([]interface{}, error) {
+	listPath := client.Endpoint + "v1.0/{project_id}/clusters/{cluster_id}/target/{upgrade_type}/images"
+	listPath = strings.ReplaceAll(listPath, "{project_id}", client.ProjectID)
+	listPath = strings.ReplaceAll(listPath, "{cluster_id}", clusterId)
+	listPath = strings.ReplaceAll(listPath, "{upgrade_type}", upgradeType)
+	resp, err := client.Request("GET", listPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the upgrade images of CSS cluster (%s): %s", clusterId, err)
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	return utils.PathSearch("imageInfoList", respBody, make([]interface{}, 0)).([]interface{}), nil
+}
+
+// getClusterUpgradeImageId returns the image of the engine version with the highest priority.
+func getClusterUpgradeImageId(images []interface{}, engineType, engineVersion string) (string, error) {
+	var imageId string
+	var priority float64
+	versions := make([]string, 0, len(images))
+	for _, image := range images {
+		imageType := utils.PathSearch("datastoreType", image, "").(string)
+		imageVersion := utils.PathSearch("datastoreVersion", image, "").(string)
+		if imageType != engineType {
+			continue
+		}
+		versions = append(versions, imageVersion)
+		if imageVersion != engineVersion {
+			continue
+		}
+		if p := utils.PathSearch("priority", image, float64(0)).(float64); imageId == "" || p > priority {
+			imageId = utils.PathSearch("id", image, "").(string)
+			priority = p
+		}
+	}
+	if imageId == "" {
+		sort.Strings(versions)
+		return "", fmt.Errorf("the cluster can not be upgraded to %s %s, the available versions are: %s",
+			engineType, engineVersion, strings.Join(utils.RemoveDuplicateElem(versions), ", "))
+	}
+	return imageId, nil
+}
+
+func getClusterUpgradeDetail(client *golangsdk.ServiceClient, clusterId, expression string) (interface{}, error) {
+	getPath := client.Endpoint + "v1.0/{project_id}/clusters/{cluster_id}/upgrade/detail"
+	getPath = strings.ReplaceAll(getPath, "{project_id}", client.ProjectID)
+	getPath = strings.ReplaceAll(getPath, "{cluster_id}", clusterId)
+	resp, err := client.Request("GET", getPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	detail := utils.PathSearch(expression, respBody, nil)
+	if detail == nil {
+		return nil, golangsdk.ErrDefault404{}
+	}
+	return detail, nil
+}
+
+func listClusterUpgradeTaskIds(client *golangsdk.ServiceClient, clusterId string) ([]string, error) {
+	taskIds, err := getClusterUpgradeDetail(client, clusterId, "detailList[].id")
+	if err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return utils.ExpandToStringList(taskIds.([]interface{})), nil
+}
+
+// waitForClusterUpgradeTask returns the ID of the upgrade task created by the upgrade request. The task is not listed
+// right after the request, so the upgrade details are polled until a task which is not in previousTaskIds is listed.
+func waitForClusterUpgradeTask(ctx context.Context, client *golangsdk.ServiceClient, clusterId string,
+	previousTaskIds []string, timeout time.Duration) (string, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending:      []string{"PENDING"},
+		Target:       []string{"CREATED"},
+		Refresh:      clusterUpgradeTaskRefreshFunc(client, clusterId, previousTaskIds),
+		Timeout:      timeout,
+		Delay:        5 * time.Second,
+		PollInterval: 5 * time.Second,
+	}
+	taskId, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error waiting for the upgrade task of CSS cluster (%s) to be created: %s", clusterId, err)
+	}
+	return taskId.(string), nil
+}
+
+func clusterUpgradeTaskRefreshFunc(client *golangsdk.ServiceClient, clusterId string,
+	previousTaskIds []string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		taskIds, err := listClusterUpgradeTaskIds(client, clusterId)
+		if err != nil {
+			return nil, "ERROR", err
+		}
+		for _, taskId := range taskIds {
+			if !utils.StrSliceContains(previousTaskIds, taskId) {
+				return taskId, "CREATED", nil
+			}
+		}
+		return taskIds, "PENDING", nil
+	}
+}
+
+func waitForClusterUpgradeCompleted(ctx context.Context, client *golangsdk.ServiceClient, clusterId, taskId string,
+	timeout time.Duration) error {
+	var detail interface{}
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"RUNNING"},
+		Target:  []string{"SUCCESS"},
+		Refresh: func() (interface{}, string, error) {
+			var err error
+			detail, err = getClusterUpgradeDetail(client, clusterId, fmt.Sprintf("detailList[?id=='%s']|[0]", taskId))
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			return detail, utils.PathSearch("status", detail, "").(string), nil
+		},
+		Timeout:      timeout,
+		Delay:        30 * time.Second,
+		PollInterval: 30 * time.Second,
+	}
+	_, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for the engine of CSS cluster (%s) to be upgraded: %s%s", clusterId, err,
+			flattenClusterUpgradeProgress(detail))
+	}
+	return nil
+}
+
+// flattenClusterUpgradeProgress returns the progress of the nodes of the upgrade task, to be appended to the error.
+func flattenClusterUpgradeProgress(detail interface{}) string {
+	if detail == nil {
+		return ""
+	}
+	progress := fmt.Sprintf("; %v of %v nodes are upgraded",
+		utils.PathSearch("completedNodes", detail, "0"), utils.PathSearch("totalNodes", detail, "unknown"))
+	if node := utils.PathSearch("currentNodeName", detail, "").(string); node != "" {
+		progress += fmt.Sprintf(", the upgrade of node %s failed", node)
+	}
+	if nodeDetail := utils.PathSearch("currentNodeDetail", detail, "").(string); nodeDetail != "" {
+		progress += ": " + nodeDetail
+	}
+	return progress
+}
+
+func abortClusterUpgrade(client *golangsdk.ServiceClient, clusterId, taskId string) error {
+	retryPath := client.Endpoint + "v1.0/{project_id}/clusters/{cluster_id}/upgrade/{action_id}/retry"
+	retryPath = strings.ReplaceAll(retryPath, "{project_id}", client.ProjectID)
+	retryPath = strings.ReplaceAll(retryPath, "{cluster_id}", clusterId)
+	retryPath = strings.ReplaceAll(retryPath, "{action_id}", taskId)
+	retryOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"retry_mode": "abort",
+		},
+	}
+	_, err := client.Request("PUT", retryPath, &retryOpt)
+	return err
+}
@@ -0,0 +1,68 @@
+package dds
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dds_huawei "github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/dds"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// ResourceDdsInstance is the SberCloud DDS instance resource. It wraps the huaweicloud resource and adds the
+// write-only administrator password, which is sent to the API but never saved in the state.
+func ResourceDdsInstance() *schema.Resource {
+	r := dds_huawei.ResourceDdsInstanceV3()
+
+	r.Schema["password"].ConflictsWith = []string{"password_wo"}
+	r.Schema["password_wo"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Sensitive:     true,
+		WriteOnly:     true,
+		ConflictsWith: []string{"password"},
+	}
+	r.Schema["password_wo_version"] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		RequiredWith: []string{"password_wo"},
+	}
+
+	create := r.CreateContext
+	r.CreateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		return withDdsInstancePassword(d, func() diag.Diagnostics {
+			return create(ctx, d, meta)
+		})
+	}
+
+	update := r.UpdateContext
+	r.UpdateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		return withDdsInstancePassword(d, func() diag.Diagnostics {
+			return update(ctx, d, meta)
+		})
+	}
+
+	return r
+}
+
+// withDdsInstancePassword runs the huaweicloud creation or update with password set to the write-only password, as
+// they read the password from password. The password of an existing instance is only reset when password_wo_version
+// changes, and password is cleared again before the state is saved.
+func withDdsInstancePassword(d *schema.ResourceData, fn func() diag.Diagnostics) diag.Diagnostics {
+	password, ok := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "password_wo").(string)
+	if !ok || password == "" {
+		return fn()
+	}
+
+	if d.Id() != "" && !d.HasChange("password_wo_version") {
+		// keep the current password, it must not be reset when password is removed from the configuration
+		oldPassword, _ := d.GetChange("password")
+		password = oldPassword.(string)
+	}
+	if err := d.Set("password", password); err != nil {
+		return diag.FromErr(err)
+	}
+	diags := fn()
+	return append(diags, diag.FromErr(d.Set("password", nil))...)
+}
@@ -0,0 +1,76 @@
+package dds
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestWithDdsInstancePassword(t *testing.T) {
+	r := ResourceDdsInstance()
+	if err := r.InternalValidate(nil, true); err != nil {
+		t.Fatalf("the schema is invalid: %s", err)
+	}
+
+	rawConfig := cty.ObjectVal(map[string]cty.Value{"password_wo": cty.StringVal("Test@123")})
+	testCases := []struct {
+		name     string
+		state    *terraform.InstanceState
+		diff     map[string]*terraform.ResourceAttrDiff
+		expected string
+	}{
+		{
+			name:     "create",
+			expected: "Test@123",
+		},
+		{
+			name: "update without a new version",
+			state: &terraform.InstanceState{
+				ID:         "instance-id",
+				Attributes: map[string]string{"id": "instance-id", "password_wo_version": "1"},
+			},
+		},
+		{
+			name: "update with a new version",
+			state: &terraform.InstanceState{
+				ID:         "instance-id",
+				Attributes: map[string]string{"id": "instance-id", "password_wo_version": "1"},
+			},
+			diff: map[string]*terraform.ResourceAttrDiff{
+				"password_wo_version": {Old: "1", New: "2"},
+			},
+			expected: "Test@123",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := schema.InternalMap(r.Schema).Data(tc.state, &terraform.InstanceDiff{
+				Attributes: tc.diff,
+				RawConfig:  rawConfig,
+			})
+			if err != nil {
+				t.Fatalf("error building the resource data: %s", err)
+			}
+
+			var password string
+			diags := withDdsInstancePassword(d, func() diag.Diagnostics {
+				password = d.Get("password").(string)
+				d.SetId("instance-id")
+				return nil
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if password != tc.expected {
+				t.Errorf("expected the password %q, got %q", tc.expected, password)
+			}
+			if saved := d.State().Attributes["password"]; saved != "" {
+				t.Errorf("expected the write-only password not to be saved in the state, got %q", saved)
+			}
+		})
+	}
+}
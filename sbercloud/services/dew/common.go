@@ -0,0 +1,34 @@
+package dew
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+// configureEphemeralResource returns the provider configuration passed to the ephemeral resource. It returns nil if
+// the provider is not configured yet, as the ephemeral resources are also configured during the validation.
+func configureEphemeralResource(req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) *config.Config {
+	if req.ProviderData == nil {
+		return nil
+	}
+	cfg, ok := req.ProviderData.(*config.Config)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data",
+			fmt.Sprintf("expected *config.Config, got %T", req.ProviderData))
+		return nil
+	}
+	return cfg
+}
+
+// getEphemeralResourceRegion returns the region of the ephemeral resource, or the region of the provider if the
+// region is not specified.
+func getEphemeralResourceRegion(cfg *config.Config, region types.String) string {
+	if v := region.ValueString(); v != "" {
+		return v
+	}
+	return cfg.Region
+}
@@ -0,0 +1,159 @@
+package dew
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/csms/v1/secrets"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+var _ ephemeral.EphemeralResourceWithConfigure = &csmsSecretVersionEphemeralResource{}
+
+// csmsSecretVersionEphemeralResource reads a version of a CSMS secret. The secret is only kept in memory while
+// Terraform runs, it is never saved in the plan or state.
+//
+// @API DEW GET /v1/{project_id}/secrets/{secret_name}/versions
+// @API DEW GET /v1/{project_id}/secrets/{secret_name}/versions/{version_id}
+type csmsSecretVersionEphemeralResource struct {
+	cfg *config.Config
+}
+
+type csmsSecretVersionModel struct {
+	Region     types.String `tfsdk:"region"`
+	SecretName types.String `tfsdk:"secret_name"`
+	Version    types.String `tfsdk:"version"`
+	SecretText types.String `tfsdk:"secret_text"`
+	KmsKeyId   types.String `tfsdk:"kms_key_id"`
+	Status     types.List   `tfsdk:"status"`
+	CreatedAt  types.String `tfsdk:"created_at"`
+}
+
+func NewCsmsSecretVersionEphemeralResource() ephemeral.EphemeralResource {
+	return &csmsSecretVersionEphemeralResource{}
+}
+
+func (r *csmsSecretVersionEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest,
+	resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_csms_secret_version"
+}
+
+func (r *csmsSecretVersionEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest,
+	resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads a version of a CSMS secret without saving it in the plan or state.",
+		Attributes: map[string]schema.Attribute{
+			"region": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The region in which to query the secret.",
+			},
+			"secret_name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the secret.",
+			},
+			"version": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The version of the secret, the latest version is read if it is not specified.",
+			},
+			"secret_text": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The plaintext of the secret version.",
+			},
+			"kms_key_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the KMS key used to encrypt the secret.",
+			},
+			"status": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The status of the secret version.",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The creation time of the secret version.",
+			},
+		},
+	}
+}
+
+func (r *csmsSecretVersionEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest,
+	resp *ephemeral.ConfigureResponse) {
+	r.cfg = configureEphemeralResource(req, resp)
+}
+
+func (r *csmsSecretVersionEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest,
+	resp *ephemeral.OpenResponse) {
+	if r.cfg == nil {
+		resp.Diagnostics.AddError("Provider not configured", "the provider must be configured to read the secret")
+		return
+	}
+	var data csmsSecretVersionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	region := getEphemeralResourceRegion(r.cfg, data.Region)
+	client, err := r.cfg.KmsV1Client(region)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating KMS client", err.Error())
+		return
+	}
+
+	secretName := data.SecretName.ValueString()
+	versionId := data.Version.ValueString()
+	if versionId == "" {
+		versionId, err = getLatestCsmsSecretVersionId(client, secretName)
+		if err != nil {
+			resp.Diagnostics.AddError("Error retrieving CSMS secret versions", err.Error())
+			return
+		}
+	}
+	version, err := secrets.ShowSecretVersion(client, secretName, versionId)
+	if err != nil {
+		resp.Diagnostics.AddError("Error retrieving CSMS secret version",
+			fmt.Sprintf("error retrieving version (%s) of CSMS secret (%s): %s", versionId, secretName, err))
+		return
+	}
+
+	metadata := version.VersionMetadata
+	status, diags := types.ListValueFrom(ctx, types.StringType, metadata.VersionStages)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Region = types.StringValue(region)
+	data.Version = types.StringValue(metadata.ID)
+	data.SecretText = types.StringValue(version.SecretString)
+	data.KmsKeyId = types.StringValue(metadata.KmsKeyID)
+	data.Status = status
+	data.CreatedAt = types.StringValue(time.Unix(int64(metadata.CreateTime)/1000, 0).UTC().
+		Format("2006-01-02 15:04:05 MST"))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+func getLatestCsmsSecretVersionId(client *golangsdk.ServiceClient, secretName string) (string, error) {
+	versions, err := secrets.ListSecretVersions(client, secretName)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("CSMS secret (%s) has no versions", secretName)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].CreateTime > versions[j].CreateTime
+	})
+	return versions[0].ID, nil
+}
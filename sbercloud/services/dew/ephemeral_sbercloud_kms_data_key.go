@@ -0,0 +1,117 @@
+package dew
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/chnsz/golangsdk/openstack/kms/v1/keys"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+var _ ephemeral.EphemeralResourceWithConfigure = &kmsDataKeyEphemeralResource{}
+
+// kmsDataKeyEphemeralResource creates a data key encrypted by a KMS key. Unlike the sbercloud_kms_data_key data
+// source, the plaintext of the data key is never saved in the plan or state.
+//
+// @API DEW POST /v1.0/{project_id}/kms/create-datakey
+type kmsDataKeyEphemeralResource struct {
+	cfg *config.Config
+}
+
+type kmsDataKeyModel struct {
+	Region            types.String `tfsdk:"region"`
+	KeyId             types.String `tfsdk:"key_id"`
+	EncryptionContext types.String `tfsdk:"encryption_context"`
+	DatakeyLength     types.String `tfsdk:"datakey_length"`
+	PlainText         types.String `tfsdk:"plain_text"`
+	CipherText        types.String `tfsdk:"cipher_text"`
+}
+
+func NewKmsDataKeyEphemeralResource() ephemeral.EphemeralResource {
+	return &kmsDataKeyEphemeralResource{}
+}
+
+func (r *kmsDataKeyEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest,
+	resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kms_data_key"
+}
+
+func (r *kmsDataKeyEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest,
+	resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Creates a data key encrypted by a KMS key without saving it in the plan or state.",
+		Attributes: map[string]schema.Attribute{
+			"region": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The region in which to create the data key.",
+			},
+			"key_id": schema.StringAttribute{
+				Required:    true,
+				Description: "The ID of the KMS key used to encrypt the data key.",
+			},
+			"encryption_context": schema.StringAttribute{
+				Optional:    true,
+				Description: "The key-value pairs used to encrypt the data key, in JSON format.",
+			},
+			"datakey_length": schema.StringAttribute{
+				Required:    true,
+				Description: "The number of bits of the data key.",
+			},
+			"plain_text": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The plaintext of the data key, in hexadecimal format.",
+			},
+			"cipher_text": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ciphertext of the data key, in hexadecimal format.",
+			},
+		},
+	}
+}
+
+func (r *kmsDataKeyEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest,
+	resp *ephemeral.ConfigureResponse) {
+	r.cfg = configureEphemeralResource(req, resp)
+}
+
+func (r *kmsDataKeyEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest,
+	resp *ephemeral.OpenResponse) {
+	if r.cfg == nil {
+		resp.Diagnostics.AddError("Provider not configured", "the provider must be configured to create a data key")
+		return
+	}
+	var data kmsDataKeyModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	region := getEphemeralResourceRegion(r.cfg, data.Region)
+	client, err := r.cfg.KmsKeyV1Client(region)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating KMS key client", err.Error())
+		return
+	}
+
+	opts := &keys.DataEncryptOpts{
+		KeyID:             data.KeyId.ValueString(),
+		EncryptionContext: data.EncryptionContext.ValueString(),
+		DatakeyLength:     data.DatakeyLength.ValueString(),
+	}
+	dataKey, err := keys.DataEncryptGet(client, opts).ExtractDataKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating KMS data key", err.Error())
+		return
+	}
+
+	data.Region = types.StringValue(region)
+	data.PlainText = types.StringValue(dataKey.PlainText)
+	data.CipherText = types.StringValue(dataKey.CipherText)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
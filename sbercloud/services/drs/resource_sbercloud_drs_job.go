@@ -151,31 +151,43 @@ func ResourceDrsJob() *schema.Resource {
 			"limit_speed": {
 				Type:     schema.TypeList,
 				Optional: true,
-				ForceNew: true,
 				MaxItems: 3,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"speed": {
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 
 						"start_time": {
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 
 						"end_time": {
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 					},
 				},
 			},
 
+			"action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"pause", "resume", "stop", "switchover"}, false),
+			},
+
+			"pause_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"target", "all"}, false),
+			},
+
+			"databases": databasesSchema(),
+
+			"tables": tablesSchema(),
+
 			"tags": common.TagsForceNewSchema(),
 
 			"force_destroy": {
@@ -203,9 +215,33 @@ func ResourceDrsJob() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+
+			"progress": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"full": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"incremental": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"delay_seconds": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
 			Delete: schema.DefaultTimeout(10 * time.Minute),
 		},
 	}
@@ -316,11 +352,21 @@ func resourceDrsJobCreate(ctx context.Context, d *schema.ResourceData, meta inte
 		return diag.Errorf("Error creating DRS v3 client, error=%s", err)
 	}
 
+	clientV5, err := config.DrsV5Client(region)
+	if err != nil {
+		return diag.Errorf("Error creating DRS v5 client, error=%s", err)
+	}
+
 	opts, err := buildCreateParamter(d, client.ProjectID, config.GetEnterpriseProjectID(d))
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	dbObjectParams, err := buildDbObjectParams(d.Get("databases").([]interface{}), d.Get("tables").(*schema.Set).List())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	rst, err := jobs.Create(client, *opts)
 	if err != nil {
 		return fmtp.DiagErrorf("Error creating DRS job: %s", err)
@@ -347,31 +393,20 @@ func resourceDrsJobCreate(ctx context.Context, d *schema.ResourceData, meta inte
 
 	//configTransSpeed
 	if v, ok := d.GetOk("limit_speed"); ok {
-		configRaw := v.([]interface{})
-		speedLimits := make([]jobs.SpeedLimitInfo, len(configRaw))
-		for i, v := range configRaw {
-			tmp := v.(map[string]interface{})
-			speedLimits[i] = jobs.SpeedLimitInfo{
-				Speed: tmp["speed"].(string),
-				Begin: tmp["begin_time"].(string),
-				End:   tmp["end_time"].(string),
-			}
+		err = limitJobSpeed(client, jobId, v.([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
 		}
-		_, err = jobs.LimitSpeed(client, jobs.BatchLimitSpeedReq{
-			SpeedLimits: []jobs.LimitSpeedReq{
-				{
-					JobId:      jobId,
-					SpeedLimit: speedLimits,
-				},
-			},
-		})
+	}
 
+	if dbObjectParams != nil {
+		err = updateJobDbObject(clientV5, jobId, dbObjectParams)
 		if err != nil {
-			return fmtp.DiagErrorf("Limit speed of job=%s failed, error: %s", jobId, err)
+			return diag.FromErr(err)
 		}
 	}
 
-	err = preCheck(ctx, client, jobId, d.Timeout(schema.TimeoutCreate))
+	err = preCheck(ctx, client, jobId, "forStartJob", d.Timeout(schema.TimeoutCreate))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -394,6 +429,13 @@ func resourceDrsJobCreate(ctx context.Context, d *schema.ResourceData, meta inte
 	if err != nil {
 		return diag.FromErr(err)
 	}
+
+	if v, ok := d.GetOk("action"); ok {
+		err = doJobAction(ctx, d, client, clientV5, v.(string), d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return resourceDrsJobRead(ctx, d, meta)
 }
 
@@ -438,10 +480,27 @@ func resourceDrsJobRead(_ context.Context, d *schema.ResourceData, meta interfac
 		d.Set("multi_write", detail.MultiWrite),
 		d.Set("created_at", detail.CreateTime),
 		d.Set("status", detail.Status),
+		d.Set("limit_speed", flattenLimitSpeedInfos(detail.SpeedLimit)),
 		setDbInfoToState(d, detail.SourceEndpoint, "source_db"),
 		setDbInfoToState(d, detail.TargetEndpoint, "destination_db"),
 	)
 
+	progress, delay := getJobProgress(client, d.Id(), detail.Status)
+	mErr = multierror.Append(mErr,
+		d.Set("progress", progress),
+		d.Set("delay_seconds", delay),
+	)
+
+	var databases, tables []interface{}
+	if detail.ObjectSwitch {
+		databases, tables = flattenJobObjects(detail.ObjectInfos, d.Get("databases").([]interface{}),
+			d.Get("tables").(*schema.Set).List())
+	}
+	mErr = multierror.Append(mErr,
+		d.Set("databases", databases),
+		d.Set("tables", tables),
+	)
+
 	if mErr.ErrorOrNil() != nil {
 		return fmtp.DiagErrorf("Error setting DRS job fields: %s", mErr)
 	}
@@ -468,19 +527,49 @@ func resourceDrsJobUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 		return nil
 	}
 
-	updateParams := jobs.UpdateReq{
-		Jobs: []jobs.UpdateJobReq{
-			{
-				JobId:       d.Id(),
-				Name:        d.Get("name").(string),
-				Description: d.Get("description").(string),
+	clientV5, err := config.DrsV5Client(region)
+	if err != nil {
+		return diag.Errorf("Error creating DRS v5 client, error: %s", err)
+	}
+
+	if d.HasChanges("name", "description") {
+		updateParams := jobs.UpdateReq{
+			Jobs: []jobs.UpdateJobReq{
+				{
+					JobId:       d.Id(),
+					Name:        d.Get("name").(string),
+					Description: d.Get("description").(string),
+				},
 			},
-		},
+		}
+
+		_, err = jobs.Update(client, updateParams)
+		if err != nil {
+			return fmtp.DiagErrorf("Update job=%s failed,error: %s", d.Id(), err)
+		}
 	}
 
-	_, err = jobs.Update(client, updateParams)
-	if err != nil {
-		return fmtp.DiagErrorf("Update job=%s failed,error: %s", d.Id(), err)
+	if d.HasChange("limit_speed") {
+		err = limitJobSpeed(client, d.Id(), d.Get("limit_speed").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChanges("databases", "tables") {
+		err = updateJobObjects(ctx, d, client, clientV5, detail.Status)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("action") {
+		if v, ok := d.GetOk("action"); ok {
+			err = doJobAction(ctx, d, client, clientV5, v.(string), d.Timeout(schema.TimeoutUpdate))
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
 	}
 
 	return resourceDrsJobRead(ctx, d, meta)
@@ -559,6 +648,21 @@ func waitingforJobStatus(ctx context.Context, client *golangsdk.ServiceClient, i
 	case "terminate":
 		pending = []string{"RELEASE_RESOURCE_STARTED"}
 		target = []string{"RELEASE_RESOURCE_COMPLETE"}
+	case "pause":
+		pending = transferringStatuses
+		target = []string{"PAUSING"}
+	case "resume":
+		pending = []string{"PAUSING", "STARTJOBING"}
+		target = transferringStatuses
+	case "stop":
+		pending = append([]string{"PAUSING", "FULL_TRANSFER_FAILED", "INCRE_TRANSFER_FAILED",
+			"RELEASE_RESOURCE_STARTED"}, transferringStatuses...)
+		target = []string{"RELEASE_RESOURCE_COMPLETE"}
+	case "restart":
+		pending = []string{"STARTJOBING", "WAITING_FOR_START", "CHILD_TRANSFER_STARTING", "CHILD_TRANSFER_STARTED",
+			"CHILD_TRANSFER_COMPLETE", "RELEASE_CHILD_TRANSFER_STARTED"}
+		target = []string{"FULL_TRANSFER_STARTED", "FULL_TRANSFER_COMPLETE", "INCRE_TRANSFER_STARTED",
+			"RELEASE_CHILD_TRANSFER_COMPLETE"}
 	}
 
 	stateConf := &resource.StateChangeConf{
@@ -569,7 +673,10 @@ func waitingforJobStatus(ctx context.Context, client *golangsdk.ServiceClient, i
 			if err != nil {
 				return nil, "", err
 			}
-			if resp.Count == 0 || resp.Results[0].ErrorCode != "" {
+			if len(resp.Results) == 0 {
+				return resp, "failed", fmtp.Errorf("job (%s) not found", id)
+			}
+			if resp.Results[0].ErrorCode != "" {
 				return resp, "failed", fmtp.Errorf("%s: %s", resp.Results[0].ErrorCode, resp.Results[0].ErrorMessage)
 			}
 
@@ -668,13 +775,16 @@ func buildDbConfigParamter(d *schema.ResourceData, dbType, projectId string) (*j
 	return &configs, nil
 }
 
+// jobNotFoundErrCodes are the error codes returned when the job does not exist.
+var jobNotFoundErrCodes = []string{"DRS.M00289", "DRS.M05004"}
+
 func parseDrsJobErrorToError404(respErr error) error {
 	var apiError jobs.JobDetailResp
 
 	if errCode, ok := respErr.(golangsdk.ErrDefault400); ok {
 		pErr := json.Unmarshal(errCode.Body, &apiError)
-		if pErr == nil &&
-			(apiError.Results[0].ErrorCode == "DRS.M00289" || apiError.Results[0].ErrorCode == "DRS.M05004") {
+		if pErr == nil && len(apiError.Results) > 0 &&
+			utils.StrSliceContains(jobNotFoundErrCodes, apiError.Results[0].ErrorCode) {
 			return golangsdk.ErrDefault404(errCode)
 		}
 	}
@@ -782,12 +892,13 @@ func reUpdateJob(client *golangsdk.ServiceClient, jobId string, opts jobs.Create
 	return nil
 }
 
-func preCheck(ctx context.Context, client *golangsdk.ServiceClient, jobId string, timeout time.Duration) error {
+func preCheck(ctx context.Context, client *golangsdk.ServiceClient, jobId, precheckMode string,
+	timeout time.Duration) error {
 	_, err := jobs.PreCheckJobs(client, jobs.BatchPrecheckReq{
 		Jobs: []jobs.PreCheckInfo{
 			{
 				JobId:        jobId,
-				PrecheckMode: "forStartJob",
+				PrecheckMode: precheckMode,
 			},
 		},
 	})
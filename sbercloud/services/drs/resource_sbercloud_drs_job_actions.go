@@ -0,0 +1,237 @@
+package drs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/drs/v3/jobs"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// transferringStatuses are the statuses in which the job is transferring the data.
+var transferringStatuses = []string{"FULL_TRANSFER_STARTED", "FULL_TRANSFER_COMPLETE", "INCRE_TRANSFER_STARTED"}
+
+// jobActionStatuses are the statuses in which the actions of the job can be executed.
+var jobActionStatuses = map[string][]string{
+	"pause":      transferringStatuses,
+	"resume":     {"PAUSING"},
+	"stop":       append([]string{"PAUSING", "FULL_TRANSFER_FAILED", "INCRE_TRANSFER_FAILED"}, transferringStatuses...),
+	"switchover": {"INCRE_TRANSFER_STARTED"},
+}
+
+var jobActionDescriptions = map[string]string{
+	"pause":      "paused",
+	"resume":     "resumed",
+	"stop":       "stopped",
+	"switchover": "switched over",
+}
+
+func buildLimitSpeedInfos(rawList []interface{}) []jobs.SpeedLimitInfo {
+	speedLimits := make([]jobs.SpeedLimitInfo, len(rawList))
+	for i, v := range rawList {
+		raw := v.(map[string]interface{})
+		speedLimits[i] = jobs.SpeedLimitInfo{
+			Speed: raw["speed"].(string),
+			Begin: raw["start_time"].(string),
+			End:   raw["end_time"].(string),
+		}
+	}
+	return speedLimits
+}
+
+func flattenLimitSpeedInfos(speedLimits []jobs.SpeedLimitInfo) []interface{} {
+	if len(speedLimits) == 0 {
+		return nil
+	}
+	result := make([]interface{}, len(speedLimits))
+	for i, v := range speedLimits {
+		result[i] = map[string]interface{}{
+			"speed":      v.Speed,
+			"start_time": v.Begin,
+			"end_time":   v.End,
+		}
+	}
+	return result
+}
+
+// limitJobSpeed replaces the speed limits of the job, the job is not limited if the list is empty.
+func limitJobSpeed(client *golangsdk.ServiceClient, jobId string, rawList []interface{}) error {
+	_, err := jobs.LimitSpeed(client, jobs.BatchLimitSpeedReq{
+		SpeedLimits: []jobs.LimitSpeedReq{
+			{
+				JobId:      jobId,
+				SpeedLimit: buildLimitSpeedInfos(rawList),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("limit speed of job (%s) failed, error: %s", jobId, err)
+	}
+	return nil
+}
+
+// executeJobAction executes the action of the job through the job action API of DRS v5.
+func executeJobAction(client *golangsdk.ServiceClient, jobId, action string, params map[string]interface{}) error {
+	actionPath := client.Endpoint + "v5/{project_id}/jobs/{job_id}/action"
+	actionPath = strings.ReplaceAll(actionPath, "{project_id}", client.ProjectID)
+	actionPath = strings.ReplaceAll(actionPath, "{job_id}", jobId)
+	actionOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"job": map[string]interface{}{
+				"action_name":   action,
+				"action_params": params,
+			},
+		},
+	}
+	resp, err := client.Request("POST", actionPath, &actionOpt)
+	if err != nil {
+		return fmt.Errorf("error executing action (%s) of job (%s): %s", action, jobId, err)
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return err
+	}
+	if status := utils.PathSearch("status", respBody, "").(string); status != "success" {
+		return fmt.Errorf("error executing action (%s) of job (%s): status (%s)", action, jobId, status)
+	}
+	return nil
+}
+
+// doJobAction executes the action of the job and waits for the job to reach the target status of the action.
+func doJobAction(ctx context.Context, d *schema.ResourceData, client, clientV5 *golangsdk.ServiceClient,
+	action string, timeout time.Duration) error {
+	resp, err := jobs.Status(client, jobs.QueryJobReq{Jobs: []string{d.Id()}})
+	if err != nil {
+		return fmt.Errorf("error retrieving the status of job (%s): %s", d.Id(), err)
+	}
+	if len(resp.Results) == 0 {
+		return fmt.Errorf("job (%s) not found", d.Id())
+	}
+	if resp.Results[0].ErrorCode != "" {
+		return fmt.Errorf("error retrieving the status of job (%s): %s: %s", d.Id(), resp.Results[0].ErrorCode,
+			resp.Results[0].ErrorMessage)
+	}
+	status := resp.Results[0].Status
+	if !utils.StrSliceContains(jobActionStatuses[action], status) {
+		return fmt.Errorf("the job (%s) can not be %s in status (%s)", d.Id(), jobActionDescriptions[action], status)
+	}
+
+	switch action {
+	case "pause":
+		err = executeJobAction(clientV5, d.Id(), "stop", map[string]interface{}{
+			"pause_mode": utils.ValueIgnoreEmpty(d.Get("pause_mode")),
+		})
+	case "resume":
+		err = executeJobAction(clientV5, d.Id(), "restart", map[string]interface{}{})
+	case "stop":
+		dErr := jobs.Delete(client, jobs.BatchDeleteJobReq{
+			Jobs: []jobs.DeleteJobReq{
+				{
+					DeleteType: jobs.DeleteTypeTerminate,
+					JobId:      d.Id(),
+				},
+			},
+		})
+		if dErr.Err != nil {
+			err = fmt.Errorf("error stopping job (%s): %s", d.Id(), dErr.Err)
+		}
+	case "switchover":
+		if d.Get("type").(string) != "cloudDataGuard" {
+			return fmt.Errorf("only the disaster recovery job can be switched over")
+		}
+		job, err := getDisasterRecoveryJob(client, d.Id())
+		if err != nil {
+			return err
+		}
+		if err = switchoverJob(client, d.Id()); err != nil {
+			return fmt.Errorf("error switching over job (%s): %s", d.Id(), err)
+		}
+		return waitingForJobSwitchover(ctx, client, d.Id(), job.JobDirection, timeout)
+	}
+	if err != nil {
+		return err
+	}
+	return waitingforJobStatus(ctx, client, d.Id(), action, timeout)
+}
+
+// switchoverJob switches the primary and the standby databases of the disaster recovery job.
+func switchoverJob(client *golangsdk.ServiceClient, jobId string) error {
+	switchoverPath := client.Endpoint + "v3/{project_id}/jobs/batch-switchover"
+	switchoverPath = strings.ReplaceAll(switchoverPath, "{project_id}", client.ProjectID)
+	switchoverOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"jobs": []string{jobId},
+		},
+	}
+	_, err := client.Request("POST", switchoverPath, &switchoverOpt)
+	return err
+}
+
+// waitingForJobSwitchover waits for the switchover of the disaster recovery job. The switchover can not be waited for
+// with waitingforJobStatus: the status of the job stays INCRE_TRANSFER_STARTED before, during and after the
+// switchover, only the current action in the job list shows that the switchover is in progress.
+func waitingForJobSwitchover(ctx context.Context, client *golangsdk.ServiceClient, jobId, direction string,
+	timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending:      []string{"PENDING", "SWITCH_OVER"},
+		Target:       []string{"COMPLETED"},
+		Refresh:      jobSwitchoverRefreshFunc(client, jobId, direction),
+		Timeout:      timeout,
+		Delay:        10 * time.Second,
+		PollInterval: 10 * time.Second,
+	}
+	_, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for DRS job (%s) to be switched over: %s", jobId, err)
+	}
+	return nil
+}
+
+// jobSwitchoverRefreshFunc returns the state of the switchover. The current action of the job may be empty until the
+// switchover starts, and it is empty again once the switchover is complete, so the switchover is complete when the
+// current action is empty after SWITCH_OVER has been seen, or when the direction of the job has changed.
+func jobSwitchoverRefreshFunc(client *golangsdk.ServiceClient, jobId, direction string) resource.StateRefreshFunc {
+	switchoverSeen := false
+	return func() (interface{}, string, error) {
+		job, err := getDisasterRecoveryJob(client, jobId)
+		if err != nil {
+			return nil, "ERROR", err
+		}
+
+		switch {
+		case job.JobAction.CurrentAction == "SWITCH_OVER":
+			switchoverSeen = true
+			return job, "SWITCH_OVER", nil
+		case switchoverSeen || (direction != "" && job.JobDirection != direction):
+			return job, "COMPLETED", nil
+		}
+		return job, "PENDING", nil
+	}
+}
+
+// getDisasterRecoveryJob returns the disaster recovery job from the job list, which is filtered by name and also
+// matches the job ID.
+func getDisasterRecoveryJob(client *golangsdk.ServiceClient, jobId string) (*jobs.JobInfo, error) {
+	resp, err := jobs.List(client, jobs.ListJobsReq{
+		CurPage:   1,
+		PerPage:   1,
+		Name:      jobId,
+		DbUseType: "cloudDataGuard",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving job (%s) from the job list: %s", jobId, err)
+	}
+	if len(resp.Jobs) == 0 {
+		return nil, fmt.Errorf("job (%s) not found in the job list", jobId)
+	}
+	return &resp.Jobs[0], nil
+}
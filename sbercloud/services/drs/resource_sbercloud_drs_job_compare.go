@@ -0,0 +1,341 @@
+package drs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/common"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// compareTypes are the comparison types and the parameters which create and query the comparison of the type.
+var compareTypes = map[string]string{
+	"object": "object_level_compare",
+	"line":   "line_compare",
+}
+
+// ResourceDrsJobCompare compares the objects or the rows of the source and the destination databases of a DRS job.
+//
+// @API DRS POST /v3/{project_id}/jobs/create-compare-task
+// @API DRS POST /v3/{project_id}/jobs/query-compare-result
+func ResourceDrsJobCompare() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDrsJobCompareCreate,
+		ReadContext:   resourceDrsJobCompareRead,
+		DeleteContext: resourceDrsJobCompareDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"job_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"compare_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"object", "line"}, false),
+			},
+			"triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"difference_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"results": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"object_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"database": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"table": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"target_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"difference_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"consistent": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func buildCreateCompareTaskBodyParams(jobId, compareType string) map[string]interface{} {
+	return map[string]interface{}{
+		"job_id":                  jobId,
+		compareTypes[compareType]: true,
+	}
+}
+
+func resourceDrsJobCompareCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	client, err := cfg.DrsV3Client(region)
+	if err != nil {
+		return diag.Errorf("error creating DRS v3 client: %s", err)
+	}
+
+	jobId := d.Get("job_id").(string)
+	compareType := d.Get("compare_type").(string)
+	createPath := client.Endpoint + "v3/{project_id}/jobs/create-compare-task"
+	createPath = strings.ReplaceAll(createPath, "{project_id}", client.ProjectID)
+	createOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody:         buildCreateCompareTaskBodyParams(jobId, compareType),
+	}
+	resp, err := client.Request("POST", createPath, &createOpt)
+	if err != nil {
+		return diag.Errorf("error creating the comparison of DRS job (%s): %s", jobId, err)
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if errCode := utils.PathSearch("error_code", respBody, "").(string); errCode != "" {
+		return diag.Errorf("error creating the comparison of DRS job (%s): %s: %s", jobId, errCode,
+			utils.PathSearch("error_msg", respBody, ""))
+	}
+	compareId := utils.PathSearch(compareTypes[compareType]+"_id", respBody, "").(string)
+	if compareId == "" {
+		return diag.Errorf("unable to find the ID of the comparison in the API response")
+	}
+	d.SetId(compareId)
+
+	stateConf := &resource.StateChangeConf{
+		// the comparison may not be listed right after it is created
+		Pending: []string{"", "RUNNING", "WAITING_FOR_RUNNING"},
+		Target:  []string{"SUCCESSFUL"},
+		Refresh: func() (interface{}, string, error) {
+			result, err := queryCompareResult(client, jobId, compareType, compareId)
+			if err != nil {
+				return nil, "", err
+			}
+			status := flattenCompareTaskStatus(result, compareId)
+			if status == "FAILED" || status == "CANCELLED" {
+				return result, status, fmt.Errorf("the comparison is %s", strings.ToLower(status))
+			}
+			return result, status, nil
+		},
+		Timeout:      d.Timeout(schema.TimeoutCreate),
+		Delay:        10 * time.Second,
+		PollInterval: 10 * time.Second,
+	}
+	if _, err = stateConf.WaitForStateContext(ctx); err != nil {
+		return diag.Errorf("error waiting for the comparison (%s) of DRS job (%s) to complete: %s", compareId, jobId,
+			err)
+	}
+
+	return resourceDrsJobCompareRead(ctx, d, meta)
+}
+
+// compareResultPageSize is the maximum number of the comparison tasks and the table results returned in one page.
+const compareResultPageSize = 1000
+
+// queryCompareResult queries the comparison tasks of the job and the results of the comparison. The comparison tasks
+// and the table results are paged, all pages are queried and merged into the response of the first page.
+func queryCompareResult(client *golangsdk.ServiceClient, jobId, compareType, compareId string) (interface{}, error) {
+	var result map[string]interface{}
+	for page := 1; ; page++ {
+		respBody, err := queryCompareResultPage(client, jobId, compareType, compareId, page)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = respBody
+		} else {
+			mergeCompareResultPage(result, respBody)
+		}
+		if !isCompareResultPageFull(respBody) {
+			return result, nil
+		}
+	}
+}
+
+func queryCompareResultPage(client *golangsdk.ServiceClient, jobId, compareType, compareId string,
+	page int) (map[string]interface{}, error) {
+	queryPath := client.Endpoint + "v3/{project_id}/jobs/query-compare-result"
+	queryPath = strings.ReplaceAll(queryPath, "{project_id}", client.ProjectID)
+	queryOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"job_id":                          jobId,
+			compareTypes[compareType] + "_id": compareId,
+			"current_page":                    page,
+			"per_page":                        compareResultPageSize,
+		},
+	}
+	resp, err := client.Request("POST", queryPath, &queryOpt)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if errCode := utils.PathSearch("error_code", respBody, "").(string); errCode != "" {
+		return nil, fmt.Errorf("%s: %s", errCode, utils.PathSearch("error_msg", respBody, ""))
+	}
+	result, ok := respBody.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected comparison result: %v", respBody)
+	}
+	return result, nil
+}
+
+// isCompareResultPageFull returns whether the comparison tasks or the table results of the page are full, in which
+// case the next page is queried.
+func isCompareResultPageFull(page interface{}) bool {
+	tasks := utils.PathSearch("compare_task_list_result.compare_task_list", page, make([]interface{}, 0)).([]interface{})
+	if len(tasks) >= compareResultPageSize {
+		return true
+	}
+	lines := utils.PathSearch("line_compare_results", page, make([]interface{}, 0)).([]interface{})
+	for _, line := range lines {
+		details := utils.PathSearch("line_compare_details", line, make([]interface{}, 0)).([]interface{})
+		if len(details) >= compareResultPageSize {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCompareResultPage appends the comparison tasks and the table results of the page to the result.
+func mergeCompareResultPage(result, page map[string]interface{}) {
+	tasks := utils.PathSearch("compare_task_list_result.compare_task_list", page, make([]interface{}, 0)).([]interface{})
+	if taskList, ok := result["compare_task_list_result"].(map[string]interface{}); ok && len(tasks) > 0 {
+		taskList["compare_task_list"] = append(utils.PathSearch("compare_task_list", taskList,
+			make([]interface{}, 0)).([]interface{}), tasks...)
+	}
+
+	lines := utils.PathSearch("line_compare_results", page, make([]interface{}, 0)).([]interface{})
+	if len(lines) > 0 {
+		result["line_compare_results"] = append(utils.PathSearch("line_compare_results", result,
+			make([]interface{}, 0)).([]interface{}), lines...)
+	}
+}
+
+func flattenCompareTaskStatus(result interface{}, compareId string) string {
+	path := fmt.Sprintf("compare_task_list_result.compare_task_list[?compare_task_id=='%s']|[0].status", compareId)
+	return utils.PathSearch(path, result, "").(string)
+}
+
+// flattenCompareResults returns the results of the objects or the tables, and the total number of the differences.
+func flattenCompareResults(result interface{}, compareType string) ([]interface{}, int) {
+	results := make([]interface{}, 0)
+	total := 0
+	if compareType == "object" {
+		items := utils.PathSearch("object_level_compare_results", result, make([]interface{}, 0)).([]interface{})
+		for _, item := range items {
+			difference := int(utils.PathSearch("difference_count", item, float64(0)).(float64))
+			total += difference
+			results = append(results, map[string]interface{}{
+				"object_type":      utils.PathSearch("object_type", item, nil),
+				"source_count":     int(utils.PathSearch("source_count", item, float64(0)).(float64)),
+				"target_count":     int(utils.PathSearch("target_count", item, float64(0)).(float64)),
+				"difference_count": difference,
+				"consistent":       utils.PathSearch("object_compare_result", item, "").(string) == "CONSISTENT",
+			})
+		}
+		return results, total
+	}
+
+	items := utils.PathSearch("line_compare_results[].line_compare_details[]", result,
+		make([]interface{}, 0)).([]interface{})
+	for _, item := range items {
+		difference := int(utils.PathSearch("difference_row_num", item, float64(0)).(float64))
+		total += difference
+		results = append(results, map[string]interface{}{
+			"object_type":      "table",
+			"database":         utils.PathSearch("source_db_name", item, nil),
+			"table":            utils.PathSearch("source_table_name", item, nil),
+			"source_count":     int(utils.PathSearch("source_row_num", item, float64(0)).(float64)),
+			"target_count":     int(utils.PathSearch("target_row_num", item, float64(0)).(float64)),
+			"difference_count": difference,
+			"consistent":       utils.PathSearch("line_compare_result", item, "").(string) == "CONSISTENT",
+		})
+	}
+	return results, total
+}
+
+func resourceDrsJobCompareRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	client, err := cfg.DrsV3Client(region)
+	if err != nil {
+		return diag.Errorf("error creating DRS v3 client: %s", err)
+	}
+
+	compareType := d.Get("compare_type").(string)
+	result, err := queryCompareResult(client, d.Get("job_id").(string), compareType, d.Id())
+	if err != nil {
+		return common.CheckDeletedDiag(d, common.ConvertExpected400ErrInto404Err(err, "error_code", jobNotFoundErrCodes...),
+			"error retrieving the comparison of DRS job")
+	}
+
+	results, total := flattenCompareResults(result, compareType)
+	mErr := multierror.Append(nil,
+		d.Set("region", region),
+		d.Set("status", flattenCompareTaskStatus(result, d.Id())),
+		d.Set("difference_count", total),
+		d.Set("results", results),
+	)
+	return diag.FromErr(mErr.ErrorOrNil())
+}
+
+func resourceDrsJobCompareDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	log.Printf("[DEBUG] the comparison (%s) is only removed from the state, its results are kept by the DRS job",
+		d.Id())
+	return nil
+}
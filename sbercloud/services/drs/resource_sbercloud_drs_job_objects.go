@@ -0,0 +1,385 @@
+package drs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/drs/v3/jobs"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// objectsConfigurableStatuses are the statuses in which the objects of a job can be selected again before it starts.
+var objectsConfigurableStatuses = []string{"CONFIGURATION", "WAITING_FOR_START"}
+
+// objectsReEditableStatuses are the statuses in which the objects of a synchronization job can be edited again.
+var objectsReEditableStatuses = []string{"INCRE_TRANSFER_STARTED", "INCRE_TRANSFER_FAILED"}
+
+func databasesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"include": {
+					Type:          schema.TypeSet,
+					Optional:      true,
+					Elem:          &schema.Schema{Type: schema.TypeString},
+					ConflictsWith: []string{"databases.0.exclude"},
+				},
+				"exclude": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"rename": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func tablesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"database": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"include": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"exclude": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"rename": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func objectName(name string, renames map[string]interface{}) string {
+	if v, ok := renames[name].(string); ok && v != "" {
+		return v
+	}
+	return name
+}
+
+// buildDbObjectParams builds the object selection of the job. The selection is nil if neither the databases nor the
+// tables are specified, which means the whole instance is migrated.
+func buildDbObjectParams(databases, tables []interface{}) (map[string]interface{}, error) {
+	if len(databases) == 0 && len(tables) == 0 {
+		return nil, nil
+	}
+
+	var included, excluded []interface{}
+	dbRenames := make(map[string]interface{})
+	if len(databases) > 0 && databases[0] != nil {
+		raw := databases[0].(map[string]interface{})
+		included = raw["include"].(*schema.Set).List()
+		excluded = raw["exclude"].(*schema.Set).List()
+		dbRenames = raw["rename"].(map[string]interface{})
+	}
+
+	objectInfo := make(map[string]interface{})
+	for _, v := range included {
+		name := v.(string)
+		objectInfo[name] = map[string]interface{}{
+			"sync_type": "config",
+			"name":      objectName(name, dbRenames),
+			"all":       true,
+		}
+	}
+	for _, v := range excluded {
+		name := v.(string)
+		objectInfo[name] = map[string]interface{}{
+			"sync_type": "exclude",
+			"name":      name,
+			"all":       true,
+		}
+	}
+
+	for _, v := range tables {
+		raw := v.(map[string]interface{})
+		database := raw["database"].(string)
+		if _, ok := objectInfo[database]; ok {
+			return nil, fmt.Errorf("database (%s) can not be specified in both databases and tables", database)
+		}
+
+		tableIncluded := raw["include"].(*schema.Set).List()
+		tableExcluded := raw["exclude"].(*schema.Set).List()
+		if (len(tableIncluded) == 0) == (len(tableExcluded) == 0) {
+			return nil, fmt.Errorf("exactly one of include and exclude must be specified for the tables of "+
+				"database (%s)", database)
+		}
+		tableRenames := raw["rename"].(map[string]interface{})
+
+		tableInfo := make(map[string]interface{})
+		for _, t := range tableIncluded {
+			name := t.(string)
+			tableInfo[name] = map[string]interface{}{
+				"sync_type": "config",
+				"name":      objectName(name, tableRenames),
+				"all":       true,
+				"type":      "table",
+			}
+		}
+		for _, t := range tableExcluded {
+			name := t.(string)
+			if _, ok := tableRenames[name]; ok {
+				return nil, fmt.Errorf("excluded table (%s) of database (%s) can not be renamed", name, database)
+			}
+			tableInfo[name] = map[string]interface{}{
+				"sync_type": "exclude",
+				"name":      name,
+				"all":       true,
+				"type":      "table",
+			}
+		}
+
+		objectInfo[database] = map[string]interface{}{
+			"sync_type": "config",
+			"name":      objectName(database, dbRenames),
+			// all the tables except the excluded ones are migrated
+			"all":    len(tableExcluded) > 0,
+			"tables": tableInfo,
+		}
+	}
+
+	for name := range dbRenames {
+		info, ok := objectInfo[name].(map[string]interface{})
+		if !ok || info["sync_type"] == "exclude" {
+			return nil, fmt.Errorf("database (%s) to be renamed is not selected", name)
+		}
+	}
+
+	scope := "database"
+	if len(tables) > 0 {
+		scope = "table"
+	}
+	return map[string]interface{}{
+		"db_object": map[string]interface{}{
+			"object_scope": scope,
+			"object_info":  objectInfo,
+		},
+	}, nil
+}
+
+// updateJobDbObject selects the objects of the job through the job configuration API of DRS v5.
+func updateJobDbObject(client *golangsdk.ServiceClient, jobId string, params map[string]interface{}) error {
+	updatePath := client.Endpoint + "v5/{project_id}/jobs/{job_id}"
+	updatePath = strings.ReplaceAll(updatePath, "{project_id}", client.ProjectID)
+	updatePath = strings.ReplaceAll(updatePath, "{job_id}", jobId)
+	updateOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"job": map[string]interface{}{
+				"type":   "db_object",
+				"params": params,
+			},
+		},
+	}
+	_, err := client.Request("PUT", updatePath, &updateOpt)
+	if err != nil {
+		return fmt.Errorf("error selecting the objects of job (%s): %s", jobId, err)
+	}
+	return nil
+}
+
+// updateJobObjects selects the objects of the job again. A job which has not started is checked again before it
+// starts, and a synchronization job which is transferring the incremental data is restarted to apply the selection.
+func updateJobObjects(ctx context.Context, d *schema.ResourceData, client, clientV5 *golangsdk.ServiceClient,
+	status string) error {
+	reEdit := d.Get("type").(string) == "sync" && utils.StrSliceContains(objectsReEditableStatuses, status)
+	if !reEdit && !utils.StrSliceContains(objectsConfigurableStatuses, status) {
+		return fmt.Errorf("the objects of job (%s) can not be changed in status (%s)", d.Id(), status)
+	}
+
+	params, err := buildDbObjectParams(d.Get("databases").([]interface{}), d.Get("tables").(*schema.Set).List())
+	if err != nil {
+		return err
+	}
+	if params == nil {
+		return fmt.Errorf("the objects of job (%s) can not be deselected, the job always migrates the selected "+
+			"objects once they are specified", d.Id())
+	}
+	if err = updateJobDbObject(clientV5, d.Id(), params); err != nil {
+		return err
+	}
+
+	if !reEdit {
+		return preCheck(ctx, client, d.Id(), "forStartJob", d.Timeout(schema.TimeoutUpdate))
+	}
+
+	if err = preCheck(ctx, client, d.Id(), "forRetryJob", d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return err
+	}
+	err = executeJobAction(clientV5, d.Id(), "restart", map[string]interface{}{"is_sync_re_edit": true})
+	if err != nil {
+		return err
+	}
+
+	// the selection is applied by a child job of the synchronization job
+	childId, err := getChildJobId(ctx, client, d.Id(), d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return err
+	}
+	return waitingforJobStatus(ctx, client, childId, "restart", d.Timeout(schema.TimeoutUpdate))
+}
+
+func getChildJobId(ctx context.Context, client *golangsdk.ServiceClient, jobId string,
+	timeout time.Duration) (string, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"PENDING"},
+		Target:  []string{"COMPLETED"},
+		Refresh: func() (interface{}, string, error) {
+			resp, err := jobs.List(client, jobs.ListJobsReq{
+				CurPage:   1,
+				PerPage:   1,
+				Name:      jobId,
+				DbUseType: "sync",
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			if len(resp.Jobs) == 0 || len(resp.Jobs[0].Children) == 0 || resp.Jobs[0].Children[0].Id == "" {
+				return resp, "PENDING", nil
+			}
+			return resp.Jobs[0].Children[0].Id, "COMPLETED", nil
+		},
+		Timeout:      timeout,
+		Delay:        10 * time.Second,
+		PollInterval: 10 * time.Second,
+	}
+	childId, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error waiting for the child job of synchronization job (%s): %s", jobId, err)
+	}
+	return childId.(string), nil
+}
+
+// flattenJobObjects flattens the selected objects of the job. The excluded objects are not returned by the job
+// detail, so they are kept as configured.
+func flattenJobObjects(objectInfos []jobs.ObjectInfo, databases, tables []interface{}) ([]interface{},
+	[]interface{}) {
+	var excluded []interface{}
+	if len(databases) > 0 && databases[0] != nil {
+		excluded = databases[0].(map[string]interface{})["exclude"].(*schema.Set).List()
+	}
+	excludedDatabases := make(map[string]bool)
+	for _, v := range excluded {
+		excludedDatabases[v.(string)] = true
+	}
+	// the tables of a database excluding some of its tables are kept as configured
+	tablesExcluding := make(map[string]interface{})
+	for _, v := range tables {
+		raw := v.(map[string]interface{})
+		if raw["exclude"].(*schema.Set).Len() > 0 {
+			tablesExcluding[raw["database"].(string)] = raw
+		}
+	}
+
+	// the tables refer to the IDs of their databases
+	databaseNames := make(map[string]string)
+	databaseOrder := make([]string, 0)
+	dbRenames := make(map[string]interface{})
+	for _, info := range objectInfos {
+		if info.Type != "database" || excludedDatabases[info.Name] {
+			continue
+		}
+		databaseNames[info.Id] = info.Name
+		databaseOrder = append(databaseOrder, info.Name)
+		if info.AliasName != "" && info.AliasName != info.Name {
+			dbRenames[info.Name] = info.AliasName
+		}
+	}
+
+	databaseTables := make(map[string][]jobs.ObjectInfo)
+	for _, info := range objectInfos {
+		if info.Type != "table" {
+			continue
+		}
+		database, ok := databaseNames[info.ParentId]
+		if !ok {
+			database = info.ParentId
+			databaseNames[database] = database
+			databaseOrder = append(databaseOrder, database)
+		}
+		databaseTables[database] = append(databaseTables[database], info)
+	}
+
+	included := make([]interface{}, 0)
+	tablesResult := make([]interface{}, 0)
+	for _, database := range databaseOrder {
+		if raw, ok := tablesExcluding[database]; ok {
+			tablesResult = append(tablesResult, raw)
+			delete(tablesExcluding, database)
+			continue
+		}
+		infos, ok := databaseTables[database]
+		if !ok {
+			included = append(included, database)
+			continue
+		}
+
+		names := make([]interface{}, len(infos))
+		tableRenames := make(map[string]interface{})
+		for i, info := range infos {
+			names[i] = info.Name
+			if info.AliasName != "" && info.AliasName != info.Name {
+				tableRenames[info.Name] = info.AliasName
+			}
+		}
+		tablesResult = append(tablesResult, map[string]interface{}{
+			"database": database,
+			"include":  names,
+			"rename":   tableRenames,
+		})
+	}
+	// keep the tables of the databases which are not returned
+	remaining := make([]string, 0, len(tablesExcluding))
+	for database := range tablesExcluding {
+		remaining = append(remaining, database)
+	}
+	sort.Strings(remaining)
+	for _, database := range remaining {
+		tablesResult = append(tablesResult, tablesExcluding[database])
+	}
+
+	var databasesResult []interface{}
+	if len(included) > 0 || len(excluded) > 0 || len(dbRenames) > 0 {
+		databasesResult = []interface{}{
+			map[string]interface{}{
+				"include": included,
+				"exclude": excluded,
+				"rename":  dbRenames,
+			},
+		}
+	}
+	if len(tablesResult) == 0 {
+		tablesResult = nil
+	}
+	return databasesResult, tablesResult
+}
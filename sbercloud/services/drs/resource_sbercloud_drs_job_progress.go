@@ -0,0 +1,82 @@
+package drs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/drs/v3/jobs"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils/logp"
+)
+
+// progressStatuses are the statuses in which the progress of the job is queried.
+var progressStatuses = append([]string{"PAUSING", "FULL_TRANSFER_FAILED", "INCRE_TRANSFER_FAILED"},
+	transferringStatuses...)
+
+// getJobProgress returns the progress and the delay of the job. The progress is not set if the job is not
+// transferring the data or it can not be queried, which does not fail the refresh.
+func getJobProgress(client *golangsdk.ServiceClient, jobId, status string) ([]interface{}, int) {
+	if !utils.StrSliceContains(progressStatuses, status) {
+		return nil, 0
+	}
+
+	resp, err := jobs.Progress(client, jobs.QueryJobReq{Jobs: []string{jobId}})
+	if err != nil {
+		logp.Printf("[WARN] unable to query the progress of job (%s): %s", jobId, err)
+		return nil, 0
+	}
+	if resp.Count == 0 || len(resp.Results) == 0 || resp.Results[0].ErrorCode != "" {
+		logp.Printf("[WARN] unable to query the progress of job (%s): %v", jobId, resp.Results)
+		return nil, 0
+	}
+	return flattenJobProgress(resp.Results[0], status)
+}
+
+// flattenJobProgress returns the full and the incremental progress in percentage and the delay in seconds. The
+// progress of the job is the progress of the full transfer until it completes, and then of the incremental transfer.
+func flattenJobProgress(result jobs.Result, status string) ([]interface{}, int) {
+	progress := parsePercentage(result.Progress)
+	delay := parseDelaySeconds(result.IncreTransDelay, result.IncreTransDelayMillis)
+
+	var full, incremental int
+	isIncremental := strings.HasPrefix(status, "INCRE_") ||
+		(status == "PAUSING" && (result.IncreTransDelay != "" || result.IncreTransDelayMillis != ""))
+	switch {
+	case status == "FULL_TRANSFER_COMPLETE":
+		full = 100
+	case isIncremental:
+		if result.TaskMode != "INCR_TRANS" {
+			full = 100
+		}
+		incremental = progress
+	default:
+		full = progress
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"full":        full,
+			"incremental": incremental,
+		},
+	}, delay
+}
+
+func parsePercentage(v string) int {
+	percentage, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return int(percentage)
+}
+
+func parseDelaySeconds(seconds, millis string) int {
+	if v, err := strconv.Atoi(strings.TrimSpace(seconds)); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseInt(strings.TrimSpace(millis), 10, 64); err == nil {
+		return int(v / 1000)
+	}
+	return 0
+}
@@ -0,0 +1,549 @@
+package drs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/drs/v3/jobs"
+)
+
+func testDrsJobObjects(t *testing.T, raw map[string]interface{}) ([]interface{}, []interface{}) {
+	t.Helper()
+	d := schema.TestResourceDataRaw(t, ResourceDrsJob().Schema, raw)
+	return d.Get("databases").([]interface{}), d.Get("tables").(*schema.Set).List()
+}
+
+func TestBuildDbObjectParams(t *testing.T) {
+	testCases := []struct {
+		name        string
+		raw         map[string]interface{}
+		expected    map[string]interface{}
+		expectedErr string
+	}{
+		{
+			name: "whole instance",
+			raw:  map[string]interface{}{},
+		},
+		{
+			name: "databases",
+			raw: map[string]interface{}{
+				"databases": []interface{}{
+					map[string]interface{}{
+						"include": []interface{}{"orders", "users"},
+						"rename":  map[string]interface{}{"orders": "orders_v2"},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"db_object": map[string]interface{}{
+					"object_scope": "database",
+					"object_info": map[string]interface{}{
+						"orders": map[string]interface{}{"sync_type": "config", "name": "orders_v2", "all": true},
+						"users":  map[string]interface{}{"sync_type": "config", "name": "users", "all": true},
+					},
+				},
+			},
+		},
+		{
+			name: "tables",
+			raw: map[string]interface{}{
+				"databases": []interface{}{
+					map[string]interface{}{
+						"exclude": []interface{}{"audit"},
+					},
+				},
+				"tables": []interface{}{
+					map[string]interface{}{
+						"database": "orders",
+						"include":  []interface{}{"items"},
+						"rename":   map[string]interface{}{"items": "order_items"},
+					},
+					map[string]interface{}{
+						"database": "users",
+						"exclude":  []interface{}{"sessions"},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"db_object": map[string]interface{}{
+					"object_scope": "table",
+					"object_info": map[string]interface{}{
+						"audit": map[string]interface{}{"sync_type": "exclude", "name": "audit", "all": true},
+						"orders": map[string]interface{}{
+							"sync_type": "config",
+							"name":      "orders",
+							"all":       false,
+							"tables": map[string]interface{}{
+								"items": map[string]interface{}{
+									"sync_type": "config", "name": "order_items", "all": true, "type": "table",
+								},
+							},
+						},
+						"users": map[string]interface{}{
+							"sync_type": "config",
+							"name":      "users",
+							"all":       true,
+							"tables": map[string]interface{}{
+								"sessions": map[string]interface{}{
+									"sync_type": "exclude", "name": "sessions", "all": true, "type": "table",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "database in both",
+			raw: map[string]interface{}{
+				"databases": []interface{}{
+					map[string]interface{}{"include": []interface{}{"orders"}},
+				},
+				"tables": []interface{}{
+					map[string]interface{}{"database": "orders", "include": []interface{}{"items"}},
+				},
+			},
+			expectedErr: "can not be specified in both databases and tables",
+		},
+		{
+			name: "tables without include or exclude",
+			raw: map[string]interface{}{
+				"tables": []interface{}{
+					map[string]interface{}{"database": "orders"},
+				},
+			},
+			expectedErr: "exactly one of include and exclude",
+		},
+		{
+			name: "renamed excluded table",
+			raw: map[string]interface{}{
+				"tables": []interface{}{
+					map[string]interface{}{
+						"database": "orders",
+						"exclude":  []interface{}{"items"},
+						"rename":   map[string]interface{}{"items": "order_items"},
+					},
+				},
+			},
+			expectedErr: "can not be renamed",
+		},
+		{
+			name: "renamed database not selected",
+			raw: map[string]interface{}{
+				"databases": []interface{}{
+					map[string]interface{}{
+						"include": []interface{}{"orders"},
+						"rename":  map[string]interface{}{"users": "users_v2"},
+					},
+				},
+			},
+			expectedErr: "to be renamed is not selected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			databases, tables := testDrsJobObjects(t, tc.raw)
+			params, err := buildDbObjectParams(databases, tables)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected the error to contain %q, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(params, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, params)
+			}
+		})
+	}
+}
+
+func TestFlattenJobObjects(t *testing.T) {
+	databases, tables := testDrsJobObjects(t, map[string]interface{}{
+		"databases": []interface{}{
+			map[string]interface{}{
+				"exclude": []interface{}{"audit"},
+			},
+		},
+		"tables": []interface{}{
+			map[string]interface{}{
+				"database": "users",
+				"exclude":  []interface{}{"sessions"},
+			},
+		},
+	})
+	objectInfos := []jobs.ObjectInfo{
+		{Id: "orders", Type: "database", Name: "orders", AliasName: "orders_v2"},
+		{Id: "orders.items", ParentId: "orders", Type: "table", Name: "items", AliasName: "order_items"},
+		{Id: "orders.payments", ParentId: "orders", Type: "table", Name: "payments"},
+		{Id: "users", Type: "database", Name: "users"},
+		{Id: "users.accounts", ParentId: "users", Type: "table", Name: "accounts"},
+		{Id: "reports", Type: "database", Name: "reports"},
+		{Id: "audit", Type: "database", Name: "audit"},
+	}
+
+	databasesResult, tablesResult := flattenJobObjects(objectInfos, databases, tables)
+
+	if len(databasesResult) != 1 {
+		t.Fatalf("expected one databases block, got %#v", databasesResult)
+	}
+	databasesRaw := databasesResult[0].(map[string]interface{})
+	if !reflect.DeepEqual(databasesRaw["include"], []interface{}{"reports"}) {
+		t.Errorf("unexpected included databases: %#v", databasesRaw["include"])
+	}
+	if !reflect.DeepEqual(databasesRaw["exclude"], []interface{}{"audit"}) {
+		t.Errorf("unexpected excluded databases: %#v", databasesRaw["exclude"])
+	}
+	if !reflect.DeepEqual(databasesRaw["rename"], map[string]interface{}{"orders": "orders_v2"}) {
+		t.Errorf("unexpected renamed databases: %#v", databasesRaw["rename"])
+	}
+
+	if len(tablesResult) != 2 {
+		t.Fatalf("expected two tables blocks, got %#v", tablesResult)
+	}
+	orders := tablesResult[0].(map[string]interface{})
+	names := make([]string, 0)
+	for _, v := range orders["include"].([]interface{}) {
+		names = append(names, v.(string))
+	}
+	sort.Strings(names)
+	if orders["database"] != "orders" || !reflect.DeepEqual(names, []string{"items", "payments"}) ||
+		!reflect.DeepEqual(orders["rename"], map[string]interface{}{"items": "order_items"}) {
+		t.Errorf("unexpected tables of database orders: %#v", orders)
+	}
+	// the tables excluding some of the tables are kept as configured
+	users := tablesResult[1].(map[string]interface{})
+	if users["database"] != "users" || users["exclude"].(*schema.Set).Len() != 1 {
+		t.Errorf("unexpected tables of database users: %#v", users)
+	}
+
+	databasesResult, tablesResult = flattenJobObjects(nil, nil, nil)
+	if databasesResult != nil || tablesResult != nil {
+		t.Errorf("expected no objects, got %#v and %#v", databasesResult, tablesResult)
+	}
+}
+
+func TestBuildLimitSpeedInfos(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, ResourceDrsJob().Schema, map[string]interface{}{
+		"limit_speed": []interface{}{
+			map[string]interface{}{"speed": "10", "start_time": "01:00", "end_time": "05:59"},
+			map[string]interface{}{"speed": "100", "start_time": "22:00", "end_time": "23:59"},
+		},
+	})
+	rawList := d.Get("limit_speed").([]interface{})
+
+	speedLimits := buildLimitSpeedInfos(rawList)
+	expected := []jobs.SpeedLimitInfo{
+		{Speed: "10", Begin: "01:00", End: "05:59"},
+		{Speed: "100", Begin: "22:00", End: "23:59"},
+	}
+	if !reflect.DeepEqual(speedLimits, expected) {
+		t.Errorf("expected %#v, got %#v", expected, speedLimits)
+	}
+	if flattened := flattenLimitSpeedInfos(speedLimits); !reflect.DeepEqual(flattened, rawList) {
+		t.Errorf("expected %#v, got %#v", rawList, flattened)
+	}
+
+	if speedLimits = buildLimitSpeedInfos(nil); speedLimits == nil || len(speedLimits) != 0 {
+		t.Errorf("expected an empty list to remove the speed limits, got %#v", speedLimits)
+	}
+}
+
+func TestFlattenJobProgress(t *testing.T) {
+	testCases := []struct {
+		name                string
+		result              jobs.Result
+		status              string
+		expectedFull        int
+		expectedIncremental int
+		expectedDelay       int
+	}{
+		{
+			name:         "full transfer",
+			result:       jobs.Result{Progress: "45%", TaskMode: "FULL_INCR_TRANS"},
+			status:       "FULL_TRANSFER_STARTED",
+			expectedFull: 45,
+		},
+		{
+			name:         "full transfer complete",
+			result:       jobs.Result{Progress: "100", TaskMode: "FULL_TRANS"},
+			status:       "FULL_TRANSFER_COMPLETE",
+			expectedFull: 100,
+		},
+		{
+			name:                "incremental transfer",
+			result:              jobs.Result{Progress: "99.5%", IncreTransDelay: "12", TaskMode: "FULL_INCR_TRANS"},
+			status:              "INCRE_TRANSFER_STARTED",
+			expectedFull:        100,
+			expectedIncremental: 99,
+			expectedDelay:       12,
+		},
+		{
+			name:                "incremental only",
+			result:              jobs.Result{Progress: "80%", IncreTransDelayMillis: "3500", TaskMode: "INCR_TRANS"},
+			status:              "INCRE_TRANSFER_STARTED",
+			expectedIncremental: 80,
+			expectedDelay:       3,
+		},
+		{
+			name:                "paused incremental transfer",
+			result:              jobs.Result{Progress: "90%", IncreTransDelay: "600", TaskMode: "FULL_INCR_TRANS"},
+			status:              "PAUSING",
+			expectedFull:        100,
+			expectedIncremental: 90,
+			expectedDelay:       600,
+		},
+		{
+			name:         "paused full transfer",
+			result:       jobs.Result{Progress: "30%", TaskMode: "FULL_INCR_TRANS"},
+			status:       "PAUSING",
+			expectedFull: 30,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			progress, delay := flattenJobProgress(tc.result, tc.status)
+			expected := []interface{}{
+				map[string]interface{}{"full": tc.expectedFull, "incremental": tc.expectedIncremental},
+			}
+			if !reflect.DeepEqual(progress, expected) {
+				t.Errorf("expected the progress %#v, got %#v", expected, progress)
+			}
+			if delay != tc.expectedDelay {
+				t.Errorf("expected the delay %d, got %d", tc.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestFlattenCompareResults(t *testing.T) {
+	objectResult := map[string]interface{}{
+		"object_level_compare_results": []interface{}{
+			map[string]interface{}{
+				"object_type": "TABLE", "object_compare_result": "CONSISTENT",
+				"source_count": float64(12), "target_count": float64(12), "difference_count": float64(0),
+			},
+			map[string]interface{}{
+				"object_type": "VIEW", "object_compare_result": "INCONSISTENT",
+				"source_count": float64(3), "target_count": float64(1), "difference_count": float64(2),
+			},
+		},
+	}
+	results, total := flattenCompareResults(objectResult, "object")
+	if total != 2 || len(results) != 2 {
+		t.Fatalf("expected 2 differences in 2 results, got %d in %#v", total, results)
+	}
+	expected := map[string]interface{}{
+		"object_type": "VIEW", "source_count": 3, "target_count": 1, "difference_count": 2, "consistent": false,
+	}
+	if !reflect.DeepEqual(results[1], expected) {
+		t.Errorf("expected %#v, got %#v", expected, results[1])
+	}
+
+	lineResult := map[string]interface{}{
+		"line_compare_results": []interface{}{
+			map[string]interface{}{
+				"line_compare_details": []interface{}{
+					map[string]interface{}{
+						"source_db_name": "orders", "source_table_name": "items", "line_compare_result": "CONSISTENT",
+						"source_row_num": float64(100), "target_row_num": float64(100),
+						"difference_row_num": float64(0),
+					},
+				},
+			},
+			map[string]interface{}{
+				"line_compare_details": []interface{}{
+					map[string]interface{}{
+						"source_db_name": "users", "source_table_name": "accounts",
+						"line_compare_result": "INCONSISTENT",
+						"source_row_num":      float64(50), "target_row_num": float64(47),
+						"difference_row_num": float64(3),
+					},
+				},
+			},
+		},
+	}
+	results, total = flattenCompareResults(lineResult, "line")
+	if total != 3 || len(results) != 2 {
+		t.Fatalf("expected 3 differences in 2 results, got %d in %#v", total, results)
+	}
+	expected = map[string]interface{}{
+		"object_type": "table", "database": "users", "table": "accounts", "source_count": 50,
+		"target_count": 47, "difference_count": 3, "consistent": false,
+	}
+	if !reflect.DeepEqual(results[1], expected) {
+		t.Errorf("expected %#v, got %#v", expected, results[1])
+	}
+
+	if status := flattenCompareTaskStatus(map[string]interface{}{
+		"compare_task_list_result": map[string]interface{}{
+			"compare_task_list": []interface{}{
+				map[string]interface{}{"compare_task_id": "other", "status": "FAILED"},
+				map[string]interface{}{"compare_task_id": "compare-id", "status": "SUCCESSFUL"},
+			},
+		},
+	}, "compare-id"); status != "SUCCESSFUL" {
+		t.Errorf("expected the status SUCCESSFUL, got %q", status)
+	}
+}
+
+func TestDoJobAction_jobNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"count": 0, "results": []}`)
+	}))
+	defer server.Close()
+	client := &golangsdk.ServiceClient{
+		ProviderClient: &golangsdk.ProviderClient{},
+		Endpoint:       server.URL + "/v3/project-id/",
+	}
+
+	d := schema.TestResourceDataRaw(t, ResourceDrsJob().Schema, map[string]interface{}{})
+	d.SetId("job-id")
+	err := doJobAction(context.Background(), d, client, client, "pause", time.Minute)
+	if err == nil || err.Error() != "job (job-id) not found" {
+		t.Errorf("expected the job not to be found, got %v", err)
+	}
+}
+
+func TestQueryCompareResult_pages(t *testing.T) {
+	var pages []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			CurrentPage int `json:"current_page"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("error decoding the request: %s", err)
+		}
+		pages = append(pages, body.CurrentPage)
+
+		count := 1
+		if body.CurrentPage == 1 {
+			count = compareResultPageSize
+		}
+		details := make([]interface{}, count)
+		for i := range details {
+			details[i] = map[string]interface{}{"source_table_name": fmt.Sprintf("table-%d-%d", body.CurrentPage, i)}
+		}
+		tasks := []interface{}{}
+		if body.CurrentPage == 2 {
+			tasks = append(tasks, map[string]interface{}{"compare_task_id": "compare-id", "status": "SUCCESSFUL"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"line_compare_results":     []interface{}{map[string]interface{}{"line_compare_details": details}},
+			"compare_task_list_result": map[string]interface{}{"compare_task_list": tasks},
+		})
+	}))
+	defer server.Close()
+	client := &golangsdk.ServiceClient{
+		ProviderClient: &golangsdk.ProviderClient{ProjectID: "project-id"},
+		Endpoint:       server.URL + "/",
+	}
+
+	result, err := queryCompareResult(client, "job-id", "line", "compare-id")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(pages, []int{1, 2}) {
+		t.Errorf("expected the pages 1 and 2 to be queried, got %v", pages)
+	}
+	if results, _ := flattenCompareResults(result, "line"); len(results) != compareResultPageSize+1 {
+		t.Errorf("expected %d results, got %d", compareResultPageSize+1, len(results))
+	}
+	if status := flattenCompareTaskStatus(result, "compare-id"); status != "SUCCESSFUL" {
+		t.Errorf("expected the status SUCCESSFUL, got %q", status)
+	}
+}
+
+func TestJobSwitchoverRefreshFunc(t *testing.T) {
+	testCases := []struct {
+		name           string
+		jobs           []string
+		expectedStates []string
+	}{
+		{
+			name: "switchover not started yet",
+			jobs: []string{
+				`{"job_direction": "up", "job_action": {"current_action": ""}}`,
+				`{"job_direction": "up", "job_action": {"current_action": "SWITCH_OVER"}}`,
+				`{"job_direction": "up", "job_action": {"current_action": ""}}`,
+			},
+			expectedStates: []string{"PENDING", "SWITCH_OVER", "COMPLETED"},
+		},
+		{
+			name: "switchover complete before the first query",
+			jobs: []string{
+				`{"job_direction": "down", "job_action": {"current_action": ""}}`,
+			},
+			expectedStates: []string{"COMPLETED"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"total_record": 1, "jobs": [%s]}`, tc.jobs[requests])
+				requests++
+			}))
+			defer server.Close()
+			client := &golangsdk.ServiceClient{
+				ProviderClient: &golangsdk.ProviderClient{},
+				Endpoint:       server.URL + "/v3/project-id/",
+			}
+
+			refresh := jobSwitchoverRefreshFunc(client, "job-id", "up")
+			for _, expected := range tc.expectedStates {
+				_, state, err := refresh()
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if state != expected {
+					t.Errorf("expected the state %s, got %s", expected, state)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDrsJobErrorToError404(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{name: "job not found", body: `{"results": [{"error_code": "DRS.M00289"}]}`, expected: true},
+		{name: "other error", body: `{"results": [{"error_code": "DRS.M00001"}]}`},
+		{name: "empty results", body: `{"results": []}`},
+		{name: "no results", body: `{"error_code": "DRS.M00289"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			respErr := golangsdk.ErrDefault400{ErrUnexpectedResponseCode: golangsdk.ErrUnexpectedResponseCode{
+				Body: []byte(tc.body),
+			}}
+			_, is404 := parseDrsJobErrorToError404(respErr).(golangsdk.ErrDefault404)
+			if is404 != tc.expected {
+				t.Errorf("expected the error to be converted to 404: %t, got %t", tc.expected, is404)
+			}
+		})
+	}
+}
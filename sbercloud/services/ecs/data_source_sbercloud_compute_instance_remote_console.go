@@ -0,0 +1,103 @@
+package ecs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/common"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// remoteConsoleTypes are the console types of the remote console protocols.
+var remoteConsoleTypes = map[string]string{
+	"vnc":    "novnc",
+	"serial": "serial",
+}
+
+// DataSourceComputeInstanceRemoteConsole returns the URL of the noVNC or the serial console of an ECS instance.
+//
+// @API ECS POST /v1/{project_id}/cloudservers/{server_id}/remote_console
+func DataSourceComputeInstanceRemoteConsole() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceComputeInstanceRemoteConsoleRead,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"instance_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"protocol": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "vnc",
+				ValidateFunc: validation.StringInSlice([]string{"vnc", "serial"}, false),
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func buildComputeInstanceRemoteConsoleBodyParams(protocol string) map[string]interface{} {
+	return map[string]interface{}{
+		"remote_console": map[string]interface{}{
+			"protocol": protocol,
+			"type":     remoteConsoleTypes[protocol],
+		},
+	}
+}
+
+func dataSourceComputeInstanceRemoteConsoleRead(_ context.Context, d *schema.ResourceData,
+	meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	client, err := cfg.NewServiceClient("ecs", region)
+	if err != nil {
+		return diag.Errorf("error creating ECS client: %s", err)
+	}
+
+	instanceID := d.Get("instance_id").(string)
+	getPath := client.Endpoint + "v1/{project_id}/cloudservers/{server_id}/remote_console"
+	getPath = strings.ReplaceAll(getPath, "{project_id}", client.ProjectID)
+	getPath = strings.ReplaceAll(getPath, "{server_id}", instanceID)
+	getOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody:         buildComputeInstanceRemoteConsoleBodyParams(d.Get("protocol").(string)),
+	}
+	resp, err := client.Request("POST", getPath, &getOpt)
+	if err != nil {
+		return common.CheckDeletedDiag(d, err, "error retrieving the remote console of instance")
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(instanceID)
+	mErr := multierror.Append(nil,
+		d.Set("region", region),
+		d.Set("protocol", utils.PathSearch("remote_console.protocol", respBody, nil)),
+		d.Set("type", utils.PathSearch("remote_console.type", respBody, nil)),
+		d.Set("url", utils.PathSearch("remote_console.url", respBody, nil)),
+	)
+	return diag.FromErr(mErr.ErrorOrNil())
+}
@@ -0,0 +1,138 @@
+package ecs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/common"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// DataSourceComputePassword returns the password of a Windows ECS instance, which is encrypted with the public
+// key of the key pair of the instance. The password is decrypted locally if the private key is specified, and the
+// private key is never sent to the cloud.
+//
+// @API ECS GET /v1/{project_id}/cloudservers/{server_id}/os-server-password
+func DataSourceComputePassword() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceComputePasswordRead,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"instance_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"private_key": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"encrypted_password": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func dataSourceComputePasswordRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	client, err := cfg.NewServiceClient("ecs", region)
+	if err != nil {
+		return diag.Errorf("error creating ECS client: %s", err)
+	}
+
+	instanceID := d.Get("instance_id").(string)
+	getPath := client.Endpoint + "v1/{project_id}/cloudservers/{server_id}/os-server-password"
+	getPath = strings.ReplaceAll(getPath, "{project_id}", client.ProjectID)
+	getPath = strings.ReplaceAll(getPath, "{server_id}", instanceID)
+	resp, err := client.Request("GET", getPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+	if err != nil {
+		return common.CheckDeletedDiag(d, err, "error retrieving the password of instance")
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the password is empty until it is generated by Cloudbase-Init, or after it is deleted
+	encrypted := utils.PathSearch("password", respBody, "").(string)
+	var password string
+	if privateKey := d.Get("private_key").(string); privateKey != "" && encrypted != "" {
+		password, err = decryptComputeInstancePassword(encrypted, privateKey)
+		if err != nil {
+			return diag.Errorf("error decrypting the password of instance (%s): %s", instanceID, err)
+		}
+	}
+
+	d.SetId(instanceID)
+	mErr := multierror.Append(nil,
+		d.Set("region", region),
+		d.Set("encrypted_password", encrypted),
+		d.Set("password", password),
+	)
+	return diag.FromErr(mErr.ErrorOrNil())
+}
+
+// decryptComputeInstancePassword decrypts the base64 encoded password with the RSA private key in PEM format, either
+// in PKCS #1 or PKCS #8 form.
+func decryptComputeInstancePassword(encrypted, privateKey string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("the password is not base64 encoded: %s", err)
+	}
+
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return "", fmt.Errorf("the private key is not in PEM format")
+	}
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		var parsed interface{}
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err == nil {
+			var ok bool
+			if key, ok = parsed.(*rsa.PrivateKey); !ok {
+				return "", fmt.Errorf("the private key is not an RSA key")
+			}
+		}
+	default:
+		return "", fmt.Errorf("unsupported private key type (%s)", block.Type)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error parsing the private key: %s", err)
+	}
+
+	plaintext, err := rsa.DecryptPKCS1v15(rand.Reader, key, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("the password can not be decrypted with the private key: %s", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,526 @@
+package ecs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/powers"
+	"github.com/chnsz/golangsdk/openstack/ims/v2/cloudimages"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	ecs_huawei "github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/ecs"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+const (
+	imageUpdatePolicyReplace = "replace"
+	imageUpdatePolicyRebuild = "rebuild"
+)
+
+// ResourceComputeInstance is the SberCloud ECS instance resource. It wraps the huaweicloud resource and layers the
+// SberCloud defaults and validations on top of it. In addition, the image of the instance can be changed in place
+// when image_update_policy is "rebuild", the data disks and the NICs can be attached, extended and detached, and the
+// system disk can be replaced from a snapshot, a backup or an image without replacing the instance. The flavor, the
+// image and the disk types are checked against each other and against the AZ during the plan, and the instance can
+// be stopped gracefully and backed up to a CBR vault before it is deleted. The data disks, the user data and the
+// period are rebuilt when the instance is imported.
+//
+// @API ECS POST /v1/{project_id}/cloudservers/action
+// @API ECS POST /v1/{project_id}/cloudservers/{server_id}/attachvolume
+// @API ECS DELETE /v1/{project_id}/cloudservers/{server_id}/detachvolume/{volume_id}
+// @API ECS POST /v1/{project_id}/cloudservers/{server_id}/changeos
+// @API ECS POST /v2/{project_id}/cloudservers/{server_id}/changeos
+// @API ECS POST /v1/{project_id}/cloudservers/{server_id}/nics
+// @API ECS POST /v1/{project_id}/cloudservers/{server_id}/nics/delete
+// @API ECS GET /v1/{project_id}/cloudservers/{server_id}
+// @API ECS GET /v1/{project_id}/jobs/{job_id}
+// @API EVS POST /v2.1/{project_id}/cloudvolumes
+// @API EVS POST /v2.1/{project_id}/cloudvolumes/{volume_id}/action
+// @API EVS GET /v2/{project_id}/cloudvolumes/{volume_id}
+// @API EVS DELETE /v2/{project_id}/cloudvolumes/{volume_id}
+// @API EVS GET /v1/{project_id}/jobs/{job_id}
+// @API CBR GET /v3/{project_id}/vaults/{vault_id}
+// @API CBR POST /v3/{project_id}/vaults/{vault_id}/addresources
+// @API CBR POST /v3/{project_id}/checkpoints
+// @API CBR GET /v3/{project_id}/checkpoints/{checkpoint_id}
+// @API BSS GET /v2/orders/customer-orders/details/{order_id}
+// @API BSS POST /v2/orders/subscriptions/resources/unsubscribe
+// @API IMS GET /v2/cloudimages
+// @API VPC GET /v1/{project_id}/ports
+// @API VPC GET /v1/{project_id}/ports/{port_id}
+// @API VPC PUT /v1/{project_id}/ports/{port_id}
+func ResourceComputeInstance() *schema.Resource {
+	r := ecs_huawei.ResourceComputeInstance()
+
+	r.SchemaVersion = 1
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Type:    resourceComputeInstanceV0Type(),
+			Upgrade: resourceComputeInstanceStateUpgradeV0,
+		},
+	}
+
+	// the SBC_* environment variables take precedence over the HW_* ones used by huaweicloud
+	for key, env := range map[string]string{
+		"image_id":    "IMAGE_ID",
+		"image_name":  "IMAGE_NAME",
+		"flavor_id":   "FLAVOR_ID",
+		"flavor_name": "FLAVOR_NAME",
+	} {
+		r.Schema[key].DefaultFunc = schema.MultiEnvDefaultFunc([]string{"SBC_" + env, "HW_" + env}, nil)
+	}
+	r.Schema["system_disk_type"].ValidateFunc = validation.StringInSlice([]string{
+		"SAS", "SSD", "GPSSD", "ESSD", "SATA",
+	}, true)
+
+	// the image changes are handled in resourceComputeInstanceImageDiff
+	r.Schema["image_id"].ForceNew = false
+	r.Schema["image_name"].ForceNew = false
+	r.Schema["image_update_policy"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Default:  imageUpdatePolicyReplace,
+		ValidateFunc: validation.StringInSlice([]string{
+			imageUpdatePolicyReplace, imageUpdatePolicyRebuild,
+		}, false),
+	}
+
+	// the data disk changes are handled in updateComputeInstanceDataDisks
+	dataDisks := r.Schema["data_disks"]
+	dataDisks.ForceNew = false
+	dataDiskSchema := dataDisks.Elem.(*schema.Resource).Schema
+	for _, v := range dataDiskSchema {
+		v.ForceNew = false
+	}
+	dataDiskSchema["id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	r.Schema["destroy_policy"] = destroyPolicySchema()
+	r.Schema["root_volume_replacement"] = rootVolumeReplacementSchema()
+	r.Schema["previous_system_disk_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	// the NIC changes are handled in updateComputeInstanceNetworks
+	networks := r.Schema["network"]
+	networks.ForceNew = false
+	networkSchema := networks.Elem.(*schema.Resource).Schema
+	for _, v := range networkSchema {
+		v.ForceNew = false
+	}
+	networkSchema["security_group_ids"] = &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		Set:      schema.HashString,
+	}
+
+	// the write-only password is only sent to the API, see withComputeInstanceAdminPass
+	r.Schema["admin_pass"].ConflictsWith = []string{"admin_pass_wo"}
+	r.Schema["admin_pass_wo"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Sensitive:     true,
+		WriteOnly:     true,
+		ConflictsWith: []string{"admin_pass"},
+	}
+	r.Schema["admin_pass_wo_version"] = &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		RequiredWith: []string{"admin_pass_wo"},
+	}
+
+	// the user data is imported in plain text
+	r.Schema["user_data"].DiffSuppressFunc = suppressComputeInstanceUserDataDiffs
+
+	r.CustomizeDiff = customdiff.Sequence(
+		r.CustomizeDiff,
+		validateComputeInstanceConfig,
+		validateComputeInstanceCompatibility,
+		resourceComputeInstanceImageDiff,
+		resourceComputeInstanceDataDisksDiff,
+		resourceComputeInstanceNetworksDiff,
+		resourceComputeInstanceRootVolumeDiff,
+	)
+
+	create := r.CreateContext
+	r.CreateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		secgroupChanges := getComputeInstanceNicSecgroupChanges(d)
+		diags := withComputeInstanceAdminPass(d, func() diag.Diagnostics {
+			return create(ctx, d, meta)
+		})
+		if diags.HasError() || d.Id() == "" {
+			return diags
+		}
+		if err := updateComputeInstanceNicSecgroups(d, meta, secgroupChanges); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		diags = append(diags, diag.FromErr(setComputeInstanceDataDisks(d))...)
+		return append(diags, diag.FromErr(setComputeInstanceNicSecgroups(d, meta))...)
+	}
+
+	read := r.ReadContext
+	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		diags := read(ctx, d, meta)
+		if !diags.HasError() && d.Id() != "" {
+			diags = append(diags, diag.FromErr(setComputeInstanceDataDisks(d))...)
+			diags = append(diags, diag.FromErr(setComputeInstanceNicSecgroups(d, meta))...)
+		}
+		return diags
+	}
+
+	update := r.UpdateContext
+	r.UpdateContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if d.HasChanges("image_id", "image_name") {
+			if err := rebuildComputeInstance(ctx, d, meta); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		if d.HasChange("root_volume_replacement") && len(d.Get("root_volume_replacement").([]interface{})) > 0 {
+			if err := replaceComputeInstanceRootVolume(ctx, d, meta); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		if d.HasChange("data_disks") {
+			if err := updateComputeInstanceDataDisks(ctx, d, meta); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+
+		var secgroupChanges map[int][]string
+		if d.HasChange("network") {
+			secgroupChanges = getComputeInstanceNicSecgroupChanges(d)
+			if err := updateComputeInstanceNetworks(ctx, d, meta); err != nil {
+				return diag.FromErr(err)
+			}
+		} else if d.HasChanges("security_group_ids", "security_groups") {
+			secgroupChanges = getComputeInstanceNicSecgroupChanges(d)
+		}
+
+		diags := withComputeInstanceAdminPass(d, func() diag.Diagnostics {
+			return update(ctx, d, meta)
+		})
+		if diags.HasError() || len(secgroupChanges) == 0 {
+			return diags
+		}
+		if err := updateComputeInstanceNicSecgroups(d, meta, secgroupChanges); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		return append(diags, diag.FromErr(setComputeInstanceNicSecgroups(d, meta))...)
+	}
+
+	del := r.DeleteContext
+	r.DeleteContext = func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		diags := applyComputeInstanceDestroyPolicy(ctx, d, meta)
+		if diags.HasError() {
+			return diags
+		}
+		if err := deleteComputeInstancePreviousSystemDisk(d, meta); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		return append(diags, del(ctx, d, meta)...)
+	}
+
+	importState := r.Importer.StateContext
+	r.Importer.StateContext = func(ctx context.Context, d *schema.ResourceData,
+		meta interface{}) ([]*schema.ResourceData, error) {
+		results, err := importState(ctx, d, meta)
+		if err != nil {
+			return nil, err
+		}
+		return results, importComputeInstanceState(d, meta)
+	}
+
+	return r
+}
+
+// validateComputeInstanceConfig checks the arguments which are only validated by the API during the creation.
+func validateComputeInstanceConfig(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		if !isComputeInstanceValueSet(d, "flavor_id") && !isComputeInstanceValueSet(d, "flavor_name") {
+			return fmt.Errorf("one of `flavor_id, flavor_name` must be specified")
+		}
+		if !isComputeInstanceValueSet(d, "image_id") && !isComputeInstanceValueSet(d, "image_name") {
+			return fmt.Errorf("one of `image_id, image_name` must be specified")
+		}
+	}
+
+	if d.Get("charging_mode").(string) == "prePaid" && isComputeInstanceValueSet(d, "key_pair") &&
+		!isComputeInstanceValueSet(d, "user_id") {
+		if cfg, ok := meta.(*config.Config); !ok || cfg.UserID == "" {
+			return fmt.Errorf("user_id must be specified when charging_mode is set to prePaid and " +
+				"the ECS is logged in using an SSH key")
+		}
+	}
+	return nil
+}
+
+// isComputeInstanceValueSet returns true if the value of the key is not empty or is specified but not known yet.
+func isComputeInstanceValueSet(d *schema.ResourceDiff, key string) bool {
+	if d.NewValueKnown(key) {
+		return d.Get(key).(string) != ""
+	}
+	// the computed arguments which are not specified are unknown too
+	rawConfig := d.GetRawConfig()
+	return rawConfig.IsKnown() && !rawConfig.IsNull() && !rawConfig.GetAttr(key).IsNull()
+}
+
+func resourceComputeInstanceImageDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChanges("image_id", "image_name") {
+		return nil
+	}
+
+	if d.Get("image_update_policy").(string) != imageUpdatePolicyRebuild {
+		for _, key := range []string{"image_id", "image_name"} {
+			if d.HasChange(key) {
+				if err := d.ForceNew(key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	// the key pair of the rebuilt instance is set by the OS change, it can not be changed by KPS in the same update
+	if d.HasChange("key_pair") {
+		return fmt.Errorf("key_pair can not be changed together with the image when image_update_policy is %q",
+			imageUpdatePolicyRebuild)
+	}
+
+	// the image ID or name which is not specified is refreshed after the rebuild
+	for _, key := range []string{"image_id", "image_name"} {
+		if !d.HasChange(key) && utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), key) == nil {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rebuildComputeInstance changes the OS of the instance to the configured image. The NICs, the EIP and the data
+// disks are kept, the key pair, the admin password and the user data are applied to the new system disk.
+func rebuildComputeInstance(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	ecsClient, err := cfg.ComputeV1Client(region)
+	if err != nil {
+		return fmt.Errorf("error creating compute V1 client: %s", err)
+	}
+	client, err := cfg.NewServiceClient("ecs", region)
+	if err != nil {
+		return fmt.Errorf("error creating ECS client: %s", err)
+	}
+	imsClient, err := cfg.ImageV2Client(region)
+	if err != nil {
+		return fmt.Errorf("error creating IMS client: %s", err)
+	}
+
+	serverID := d.Id()
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	imageID, err := getImageIDFromConfig(d, imsClient)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] stopping instance (%s) to change its image to %s", serverID, imageID)
+	if err := doComputeInstancePowerAction(ctx, ecsClient, serverID, "os-stop", timeout); err != nil {
+		return err
+	}
+
+	jobID, err := changeComputeInstanceOS(client, d, imageID)
+	if err != nil {
+		return fmt.Errorf("error changing the image of instance (%s): %s", serverID, err)
+	}
+	if err := cloudservers.WaitForJobSuccess(ecsClient, int(timeout/time.Second), jobID); err != nil {
+		return fmt.Errorf("error waiting for the image of instance (%s) to be changed: %s", serverID, err)
+	}
+
+	server, err := cloudservers.Get(ecsClient, serverID).Extract()
+	if err != nil {
+		return fmt.Errorf("error retrieving instance (%s): %s", serverID, err)
+	}
+	// keep the instance stopped if it is powered off in the configuration
+	if server.Status == "SHUTOFF" && !strings.HasSuffix(d.Get("power_action").(string), "OFF") {
+		return doComputeInstancePowerAction(ctx, ecsClient, serverID, "os-start", timeout)
+	}
+	return nil
+}
+
+func changeComputeInstanceOS(client *golangsdk.ServiceClient, d *schema.ResourceData, imageID string) (string, error) {
+	// the state only stores the hash of the user data
+	userData, _ := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "user_data").(string)
+
+	httpUrl := "v1/{project_id}/cloudservers/{server_id}/changeos"
+	if userData != "" {
+		// the user data is only supported by the API for the images with Cloud-Init installed
+		httpUrl = "v2/{project_id}/cloudservers/{server_id}/changeos"
+	}
+
+	changePath := client.Endpoint + httpUrl
+	changePath = strings.ReplaceAll(changePath, "{project_id}", client.ProjectID)
+	changePath = strings.ReplaceAll(changePath, "{server_id}", d.Id())
+
+	changeOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: buildComputeInstanceOSChangeBodyParams(imageID, getComputeInstanceAdminPass(d),
+			d.Get("key_pair").(string), userData),
+	}
+	resp, err := client.Request("POST", changePath, &changeOpt)
+	if err != nil {
+		return "", err
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	jobID := utils.PathSearch("job_id", respBody, "").(string)
+	if jobID == "" {
+		return "", fmt.Errorf("unable to find the job ID from the API response")
+	}
+	return jobID, nil
+}
+
+// getComputeInstanceAdminPass returns the write-only admin password from the configuration if it is specified,
+// otherwise the password which is saved in the state.
+func getComputeInstanceAdminPass(d *schema.ResourceData) string {
+	if v, ok := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "admin_pass_wo").(string); ok && v != "" {
+		return v
+	}
+	return d.Get("admin_pass").(string)
+}
+
+// withComputeInstanceAdminPass runs the huaweicloud creation or update with admin_pass set to the write-only
+// password, as they read the password from admin_pass. The password of an existing instance is only changed when
+// admin_pass_wo_version changes, and admin_pass is cleared again before the state is saved.
+func withComputeInstanceAdminPass(d *schema.ResourceData, fn func() diag.Diagnostics) diag.Diagnostics {
+	adminPass, ok := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "admin_pass_wo").(string)
+	if !ok || adminPass == "" {
+		return fn()
+	}
+
+	if d.Id() != "" && !d.HasChange("admin_pass_wo_version") {
+		// keep the current password, it must not be reset when admin_pass is removed from the configuration
+		oldPass, _ := d.GetChange("admin_pass")
+		adminPass = oldPass.(string)
+	}
+	if err := d.Set("admin_pass", adminPass); err != nil {
+		return diag.FromErr(err)
+	}
+	diags := fn()
+	return append(diags, diag.FromErr(d.Set("admin_pass", nil))...)
+}
+
+func buildComputeInstanceOSChangeBodyParams(imageID, adminPass, keyPair, userData string) map[string]interface{} {
+	osChange := map[string]interface{}{
+		"imageid":   imageID,
+		"adminpass": utils.ValueIgnoreEmpty(adminPass),
+		"keyname":   utils.ValueIgnoreEmpty(keyPair),
+		"isAutoPay": "true",
+	}
+	if userData != "" {
+		if _, err := base64.StdEncoding.DecodeString(userData); err != nil {
+			userData = base64.StdEncoding.EncodeToString([]byte(userData))
+		}
+		osChange["metadata"] = map[string]interface{}{
+			"user_data": userData,
+		}
+	}
+
+	return map[string]interface{}{
+		"os-change": utils.RemoveNil(osChange),
+	}
+}
+
+func doComputeInstancePowerAction(ctx context.Context, client *golangsdk.ServiceClient, serverID, action string,
+	timeout time.Duration) error {
+	return doComputeInstancePowerActionByType(ctx, client, serverID, action, "SOFT", timeout)
+}
+
+// doComputeInstancePowerActionByType starts or stops the instance, the stop type is either "SOFT" or "HARD".
+func doComputeInstancePowerActionByType(ctx context.Context, client *golangsdk.ServiceClient, serverID, action,
+	stopType string, timeout time.Duration) error {
+	powerOpts := powers.PowerOpts{
+		Servers: []powers.ServerInfo{
+			{ID: serverID},
+		},
+	}
+	target := "ACTIVE"
+	if action == "os-stop" {
+		powerOpts.Type = stopType
+		target = "SHUTOFF"
+	}
+
+	server, err := cloudservers.Get(client, serverID).Extract()
+	if err != nil {
+		return fmt.Errorf("error retrieving instance (%s): %s", serverID, err)
+	}
+	if server.Status != target {
+		job, err := powers.PowerAction(client, powerOpts, action).ExtractJobResponse()
+		if err != nil {
+			return fmt.Errorf("doing power action (%s) for instance (%s) failed: %s", action, serverID, err)
+		}
+		if err := cloudservers.WaitForJobSuccess(client, int(timeout/time.Second), job.JobID); err != nil {
+			return fmt.Errorf("waiting power action (%s) for instance (%s) failed: %s", action, serverID, err)
+		}
+	}
+
+	stateConf := &resource.StateChangeConf{
+		Pending:      []string{"ACTIVE", "SHUTOFF", "REBOOT", "HARD_REBOOT", "REBUILD"},
+		Target:       []string{target},
+		Refresh:      ecs_huawei.ServerV1StateRefreshFunc(client, serverID),
+		Timeout:      timeout,
+		Delay:        5 * time.Second,
+		PollInterval: 5 * time.Second,
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error waiting for instance (%s) to become %s: %s", serverID, target, err)
+	}
+	return nil
+}
+
+func getImageIDFromConfig(d *schema.ResourceData, imsClient *golangsdk.ServiceClient) (string, error) {
+	if imageID := d.Get("image_id").(string); imageID != "" {
+		return imageID, nil
+	}
+
+	imageName := d.Get("image_name").(string)
+	if imageName == "" {
+		return "", fmt.Errorf("neither a image ID or image name were able to be determined")
+	}
+
+	listOpts := &cloudimages.ListOpts{
+		Name:                imageName,
+		Limit:               1,
+		EnterpriseProjectID: "all_granted_eps",
+	}
+	allPages, err := cloudimages.List(imsClient, listOpts).AllPages()
+	if err != nil {
+		return "", fmt.Errorf("unable to query images: %s", err)
+	}
+	images, err := cloudimages.ExtractImages(allPages)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve images: %s", err)
+	}
+	if len(images) < 1 || images[0].Name != imageName {
+		return "", fmt.Errorf("unable to find image %s", imageName)
+	}
+	return images[0].ID, nil
+}
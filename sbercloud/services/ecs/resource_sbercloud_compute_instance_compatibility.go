@@ -0,0 +1,449 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/ims/v2/cloudimages"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// computeFlavor is the sale information of an ECS flavor.
+type computeFlavor struct {
+	ID   string
+	Name string
+	// Architecture is either "x86" or "arm"
+	Architecture string
+	// Status is the sale status of the flavor in the region, which is overridden by the status of the AZs in AZStatus
+	Status   string
+	AZStatus map[string]string
+}
+
+// computeImage is the architecture of an IMS image.
+type computeImage struct {
+	ID           string
+	Name         string
+	Architecture string
+}
+
+// volumeTypeAvailability lists the AZs where an EVS volume type is offered and sold out, an empty AZs list means
+// that the volume type is offered in all the AZs.
+type volumeTypeAvailability struct {
+	AZs        []string
+	SoldOutAZs []string
+}
+
+// computeInstanceCatalog caches the flavors, the images and the volume types queried during the plan, so that they
+// are queried once for all the instances. The queries which fail are cached as well and the checks depending on them
+// are skipped.
+type computeInstanceCatalog struct {
+	fetchFlavors     func(region string) ([]computeFlavor, error)
+	fetchImage       func(region, id, name string) (*computeImage, error)
+	fetchVolumeTypes func(region string) (map[string]volumeTypeAvailability, error)
+
+	// mu only guards the entries, the queries are sent by the first caller of every entry without holding it
+	mu      sync.Mutex
+	entries map[string]*computeInstanceCatalogEntry
+}
+
+type computeInstanceCatalogEntry struct {
+	once  sync.Once
+	value interface{}
+}
+
+func newComputeInstanceCatalog(cfg *config.Config) *computeInstanceCatalog {
+	return &computeInstanceCatalog{
+		fetchFlavors: func(region string) ([]computeFlavor, error) {
+			return fetchComputeInstanceFlavors(cfg, region)
+		},
+		fetchImage: func(region, id, name string) (*computeImage, error) {
+			return fetchComputeInstanceImage(cfg, region, id, name)
+		},
+		fetchVolumeTypes: func(region string) (map[string]volumeTypeAvailability, error) {
+			return fetchComputeInstanceVolumeTypes(cfg, region)
+		},
+		entries: make(map[string]*computeInstanceCatalogEntry),
+	}
+}
+
+// RegisterComputeInstanceCatalog enables the compatibility check of the compute instances planned with the provider
+// configuration. The catalog is kept in the metadata of the configuration, so it is released with it.
+func RegisterComputeInstanceCatalog(cfg *config.Config) {
+	cfg.Metadata = newComputeInstanceCatalog(cfg)
+}
+
+func getComputeInstanceCatalog(cfg *config.Config) *computeInstanceCatalog {
+	catalog, _ := cfg.Metadata.(*computeInstanceCatalog)
+	return catalog
+}
+
+// load returns the cached value of the key, fetch is called once to query it. The callers of the same key wait for the
+// query, and the callers of other keys are not blocked.
+func (c *computeInstanceCatalog) load(key string, fetch func() interface{}) interface{} {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &computeInstanceCatalogEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value = fetch()
+	})
+	return entry.value
+}
+
+// flavor returns the flavor with the ID or the name. found is false if the flavor does not exist, and ok is false if
+// the flavors can not be queried.
+func (c *computeInstanceCatalog) flavor(region, id, name string) (flavor computeFlavor, found, ok bool) {
+	flavors := c.load("flavors/"+region, func() interface{} {
+		flavors, err := c.fetchFlavors(region)
+		if err != nil {
+			log.Printf("[WARN] unable to query the ECS flavors, the flavor of the instances is not checked: %s", err)
+		}
+		return flavors
+	}).([]computeFlavor)
+	if flavors == nil {
+		return computeFlavor{}, false, false
+	}
+
+	for _, f := range flavors {
+		if (id != "" && f.ID == id) || (id == "" && f.Name == name) {
+			return f, true, true
+		}
+	}
+	return computeFlavor{}, false, true
+}
+
+// image returns the image with the ID or the name, it is nil if the image does not exist or can not be queried.
+func (c *computeInstanceCatalog) image(region, id, name string) *computeImage {
+	key := strings.Join([]string{"images", region, id, name}, "/")
+	return c.load(key, func() interface{} {
+		image, err := c.fetchImage(region, id, name)
+		if err != nil {
+			log.Printf("[WARN] unable to query the image, its architecture is not checked: %s", err)
+		}
+		return image
+	}).(*computeImage)
+}
+
+// volumeType returns the availability of the volume type. found is false if the volume type does not exist, and ok
+// is false if the volume types can not be queried.
+func (c *computeInstanceCatalog) volumeType(region, name string) (availability volumeTypeAvailability, found, ok bool) {
+	volumeTypes := c.load("volume_types/"+region, func() interface{} {
+		volumeTypes, err := c.fetchVolumeTypes(region)
+		if err != nil {
+			log.Printf("[WARN] unable to query the EVS volume types, the disk types of the instances are not "+
+				"checked: %s", err)
+		}
+		return volumeTypes
+	}).(map[string]volumeTypeAvailability)
+	if volumeTypes == nil {
+		return volumeTypeAvailability{}, false, false
+	}
+
+	availability, found = volumeTypes[strings.ToUpper(name)]
+	return availability, found, true
+}
+
+// isComputeFlavorOnSale returns whether the flavor can be used to create instances in the AZ. If the AZ is empty,
+// the instance is created in any AZ where the flavor is on sale.
+func isComputeFlavorOnSale(flavor computeFlavor, az string) bool {
+	if az != "" {
+		status, ok := flavor.AZStatus[az]
+		if !ok {
+			status = flavor.Status
+		}
+		return isComputeFlavorStatusOnSale(status)
+	}
+
+	if isComputeFlavorStatusOnSale(flavor.Status) {
+		return true
+	}
+	for _, status := range flavor.AZStatus {
+		if isComputeFlavorStatusOnSale(status) {
+			return true
+		}
+	}
+	return false
+}
+
+func isComputeFlavorStatusOnSale(status string) bool {
+	return status != "sellout" && status != "abandon"
+}
+
+// validateComputeInstanceCompatibility checks that the flavor is on sale in the AZ, that the architecture of the image
+// matches the flavor and that the disk types are offered in the AZ. The flavor, the image and the disks are only
+// checked when they are created or changed, and the values which are not known yet are not checked.
+func validateComputeInstanceCompatibility(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	cfg, ok := meta.(*config.Config)
+	if !ok {
+		return nil
+	}
+	region := cfg.Region
+	if v, ok := d.Get("region").(string); ok && v != "" {
+		region = v
+	}
+	catalog := getComputeInstanceCatalog(cfg)
+	if catalog == nil || region == "" {
+		return nil
+	}
+
+	isNew := d.Id() == ""
+	az := ""
+	if d.NewValueKnown("availability_zone") {
+		az = d.Get("availability_zone").(string)
+	}
+
+	var mErr *multierror.Error
+	var flavor computeFlavor
+	flavorFound := false
+	flavorChanged := isNew || d.HasChanges("flavor_id", "flavor_name")
+	if flavorID, flavorName, known := getComputeInstanceKnownValue(d, "flavor_id", "flavor_name"); known {
+		var ok bool
+		flavor, flavorFound, ok = catalog.flavor(region, flavorID, flavorName)
+		switch {
+		case !ok || !flavorChanged:
+		case !flavorFound:
+			// only one of the flavor ID and name is returned
+			mErr = multierror.Append(mErr, fmt.Errorf("the flavor %s is not found in region %s",
+				flavorID+flavorName, region))
+		case !isComputeFlavorOnSale(flavor, az):
+			if az != "" {
+				mErr = multierror.Append(mErr, fmt.Errorf("the flavor %s is sold out in availability zone %s",
+					flavor.ID, az))
+			} else {
+				mErr = multierror.Append(mErr, fmt.Errorf("the flavor %s is sold out in region %s", flavor.ID, region))
+			}
+		}
+	}
+
+	if imageID, imageName, known := getComputeInstanceKnownValue(d, "image_id", "image_name"); known && flavorFound &&
+		(flavorChanged || d.HasChanges("image_id", "image_name")) {
+		if image := catalog.image(region, imageID, imageName); image != nil && image.Architecture != flavor.Architecture {
+			mErr = multierror.Append(mErr, fmt.Errorf("the image %s (%s) does not match the architecture of the "+
+				"flavor %s (%s)", image.ID, image.Architecture, flavor.ID, flavor.Architecture))
+		}
+	}
+
+	for _, key := range getComputeInstanceNewDiskTypeKeys(d) {
+		if err := checkComputeInstanceDiskType(catalog, region, az, key, d.Get(key).(string)); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// getComputeInstanceKnownValue returns the ID or the name of the flavor or the image, the one specified in the
+// configuration takes precedence. known is false if the value is not known yet.
+func getComputeInstanceKnownValue(d *schema.ResourceDiff, idKey, nameKey string) (id, name string, known bool) {
+	rawConfig := d.GetRawConfig()
+	for _, key := range []string{idKey, nameKey} {
+		if rawConfig.IsNull() || !rawConfig.IsKnown() || rawConfig.GetAttr(key).IsNull() {
+			continue
+		}
+		if !rawConfig.GetAttr(key).IsKnown() {
+			return "", "", false
+		}
+		if key == idKey {
+			return d.Get(key).(string), "", true
+		}
+		return "", d.Get(key).(string), true
+	}
+
+	// the value may be unknown or set by the environment variables
+	if !d.NewValueKnown(idKey) {
+		return "", "", false
+	}
+	if id := d.Get(idKey).(string); id != "" {
+		return id, "", true
+	}
+	if !d.NewValueKnown(nameKey) {
+		return "", "", false
+	}
+	name = d.Get(nameKey).(string)
+	return "", name, name != ""
+}
+
+// getComputeInstanceNewDiskTypeKeys returns the keys of the known types of the disks to create, the data disks
+// appended to the list of an existing instance are attached in place.
+func getComputeInstanceNewDiskTypeKeys(d *schema.ResourceDiff) []string {
+	keys := make([]string, 0)
+	start := 0
+	if d.Id() == "" {
+		keys = append(keys, "system_disk_type")
+	} else {
+		o, _ := d.GetChange("data_disks")
+		start = len(o.([]interface{}))
+	}
+	for i := start; i < len(d.Get("data_disks").([]interface{})); i++ {
+		keys = append(keys, fmt.Sprintf("data_disks.%d.type", i))
+	}
+
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if d.NewValueKnown(key) && d.Get(key).(string) != "" {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func checkComputeInstanceDiskType(catalog *computeInstanceCatalog, region, az, key, volumeType string) error {
+	availability, found, ok := catalog.volumeType(region, volumeType)
+	if !ok {
+		return nil
+	}
+	if !found {
+		return fmt.Errorf("the disk type %s of %s is not found in region %s", volumeType, key, region)
+	}
+	if az == "" {
+		return nil
+	}
+	if len(availability.AZs) > 0 && !utils.StrSliceContains(availability.AZs, az) {
+		return fmt.Errorf("the disk type %s of %s is not offered in availability zone %s", volumeType, key, az)
+	}
+	if utils.StrSliceContains(availability.SoldOutAZs, az) {
+		return fmt.Errorf("the disk type %s of %s is sold out in availability zone %s", volumeType, key, az)
+	}
+	return nil
+}
+
+func doComputeInstanceCatalogRequest(client *golangsdk.ServiceClient, path string) (interface{}, error) {
+	requestPath := client.Endpoint + strings.ReplaceAll(path, "{project_id}", client.ProjectID)
+	requestOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		MoreHeaders:      map[string]string{"Content-Type": "application/json"},
+	}
+	resp, err := client.Request("GET", requestPath, &requestOpt)
+	if err != nil {
+		return nil, err
+	}
+	return utils.FlattenResponse(resp)
+}
+
+// @API ECS GET /v1/{project_id}/cloudservers/flavors
+func fetchComputeInstanceFlavors(cfg *config.Config, region string) ([]computeFlavor, error) {
+	client, err := cfg.NewServiceClient("ecs", region)
+	if err != nil {
+		return nil, fmt.Errorf("error creating ECS client: %s", err)
+	}
+	resp, err := doComputeInstanceCatalogRequest(client, "v1/{project_id}/cloudservers/flavors")
+	if err != nil {
+		return nil, err
+	}
+
+	flavors := utils.PathSearch("flavors", resp, make([]interface{}, 0)).([]interface{})
+	result := make([]computeFlavor, 0, len(flavors))
+	for _, v := range flavors {
+		extraSpecs := utils.PathSearch("os_extra_specs", v, nil)
+		architecture := "x86"
+		if utils.PathSearch(`"ecs:instance_architecture"`, extraSpecs, "").(string) == "arm64" {
+			architecture = "arm"
+		}
+		result = append(result, computeFlavor{
+			ID:           utils.PathSearch("id", v, "").(string),
+			Name:         utils.PathSearch("name", v, "").(string),
+			Architecture: architecture,
+			Status:       utils.PathSearch(`"cond:operation:status"`, extraSpecs, "normal").(string),
+			AZStatus: parseComputeFlavorAZStatus(
+				utils.PathSearch(`"cond:operation:az"`, extraSpecs, "").(string)),
+		})
+	}
+	return result, nil
+}
+
+// parseComputeFlavorAZStatus parses the status of the flavor in the AZs, such as "az1(normal), az2(sellout)".
+func parseComputeFlavorAZStatus(operationAZ string) map[string]string {
+	result := make(map[string]string)
+	for _, v := range strings.Split(operationAZ, ",") {
+		v = strings.TrimSpace(v)
+		start := strings.Index(v, "(")
+		if start <= 0 || !strings.HasSuffix(v, ")") {
+			continue
+		}
+		result[v[:start]] = v[start+1 : len(v)-1]
+	}
+	return result
+}
+
+// @API IMS GET /v2/cloudimages
+func fetchComputeInstanceImage(cfg *config.Config, region, id, name string) (*computeImage, error) {
+	client, err := cfg.ImageV2Client(region)
+	if err != nil {
+		return nil, fmt.Errorf("error creating IMS client: %s", err)
+	}
+
+	listOpts := &cloudimages.ListOpts{
+		ID:                  id,
+		Name:                name,
+		Limit:               1,
+		EnterpriseProjectID: "all_granted_eps",
+	}
+	allPages, err := cloudimages.List(client, listOpts).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("unable to query images: %s", err)
+	}
+	images, err := cloudimages.ExtractImages(allPages)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve images: %s", err)
+	}
+	if len(images) < 1 || (id != "" && images[0].ID != id) || (name != "" && images[0].Name != name) {
+		return nil, fmt.Errorf("unable to find image %s%s", id, name)
+	}
+
+	image := &computeImage{
+		ID:           images[0].ID,
+		Name:         images[0].Name,
+		Architecture: "x86",
+	}
+	if images[0].SupportArm == "true" {
+		image.Architecture = "arm"
+	}
+	return image, nil
+}
+
+// @API EVS GET /v2/{project_id}/types
+func fetchComputeInstanceVolumeTypes(cfg *config.Config, region string) (map[string]volumeTypeAvailability, error) {
+	client, err := cfg.NewServiceClient("evs", region)
+	if err != nil {
+		return nil, fmt.Errorf("error creating EVS client: %s", err)
+	}
+	resp, err := doComputeInstanceCatalogRequest(client, "v2/{project_id}/types")
+	if err != nil {
+		return nil, err
+	}
+
+	volumeTypes := utils.PathSearch("volume_types", resp, make([]interface{}, 0)).([]interface{})
+	result := make(map[string]volumeTypeAvailability, len(volumeTypes))
+	for _, v := range volumeTypes {
+		name := utils.PathSearch("name", v, "").(string)
+		result[strings.ToUpper(name)] = volumeTypeAvailability{
+			AZs: splitComputeInstanceAZs(
+				utils.PathSearch(`extra_specs."RESKEY:availability_zones"`, v, "").(string)),
+			SoldOutAZs: splitComputeInstanceAZs(
+				utils.PathSearch(`extra_specs."os-vendor-extended:sold_out_availability_zones"`, v, "").(string)),
+		}
+	}
+	return result, nil
+}
+
+func splitComputeInstanceAZs(azs string) []string {
+	result := make([]string, 0)
+	for _, az := range strings.Split(azs, ",") {
+		if az = strings.TrimSpace(az); az != "" {
+			result = append(result, az)
+		}
+	}
+	return result
+}
@@ -0,0 +1,435 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/block_devices"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/common"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// dataDiskReplaceKeys are the attributes of a data disk which can only be set when the disk is created.
+var dataDiskReplaceKeys = []string{"type", "snapshot_id", "kms_key_id", "iops", "throughput", "dss_pool_id"}
+
+// resourceComputeInstanceDataDisksDiff checks the changes of the data disks. The disks are matched by their position
+// in the list: the disks appended to the list are created and attached, the disks removed from the end of the list
+// are detached, and the size of the other disks can be increased. The other changes of an attached disk still
+// replace the instance, as they did before the data disks could be updated.
+func resourceComputeInstanceDataDisksDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("data_disks") {
+		return nil
+	}
+
+	o, n := d.GetChange("data_disks")
+	oldDisks, newDisks := o.([]interface{}), n.([]interface{})
+	for i := 0; i < len(oldDisks) && i < len(newDisks); i++ {
+		oldDisk, _ := oldDisks[i].(map[string]interface{})
+		newDisk, _ := newDisks[i].(map[string]interface{})
+		if oldDisk == nil || newDisk == nil {
+			continue
+		}
+
+		for _, key := range dataDiskReplaceKeys {
+			if oldDisk[key] != newDisk[key] {
+				if err := d.ForceNew(fmt.Sprintf("data_disks.%d.%s", i, key)); err != nil {
+					return err
+				}
+			}
+		}
+		if newDisk["size"].(int) < oldDisk["size"].(int) {
+			if err := d.ForceNew(fmt.Sprintf("data_disks.%d.size", i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateComputeInstanceDataDisks detaches the removed data disks, extends the grown ones and attaches the new ones.
+func updateComputeInstanceDataDisks(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	ecsClient, err := cfg.ComputeV1Client(region)
+	if err != nil {
+		return fmt.Errorf("error creating compute V1 client: %s", err)
+	}
+	evsClient, err := cfg.NewServiceClient("evs", region)
+	if err != nil {
+		return fmt.Errorf("error creating EVS client: %s", err)
+	}
+
+	o, n := d.GetChange("data_disks")
+	oldDisks, newDisks := o.([]interface{}), n.([]interface{})
+
+	// detach the removed disks first, so that the new disks do not exceed the maximum number of attached disks
+	for i := len(newDisks); i < len(oldDisks); i++ {
+		volumeID, err := getComputeInstanceDataDiskID(oldDisks, i)
+		if err != nil {
+			return err
+		}
+		if err := detachComputeInstanceDataDisk(ctx, d, cfg, ecsClient, evsClient, volumeID); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < len(oldDisks) && i < len(newDisks); i++ {
+		oldSize := oldDisks[i].(map[string]interface{})["size"].(int)
+		newSize := newDisks[i].(map[string]interface{})["size"].(int)
+		if newSize <= oldSize {
+			continue
+		}
+
+		volumeID, err := getComputeInstanceDataDiskID(oldDisks, i)
+		if err != nil {
+			return err
+		}
+		log.Printf("[DEBUG] extending data disk (%s) of instance (%s) to %d GB", volumeID, d.Id(), newSize)
+		if err := extendComputeInstanceDataDisk(ctx, d, cfg, evsClient, volumeID, newSize); err != nil {
+			return fmt.Errorf("error extending data disk (%s) of instance (%s): %s", volumeID, d.Id(), err)
+		}
+	}
+
+	result := make([]interface{}, len(newDisks))
+	for i, v := range newDisks {
+		disk := v.(map[string]interface{})
+		if i < len(oldDisks) {
+			disk["id"] = oldDisks[i].(map[string]interface{})["id"]
+		} else {
+			volumeID, err := createComputeInstanceDataDisk(ctx, d, cfg, ecsClient, evsClient, disk, i)
+			if err != nil {
+				// keep the IDs of the disks which have been attached
+				if setErr := d.Set("data_disks", result[:i]); setErr != nil {
+					log.Printf("[WARN] error saving the data disks of instance (%s): %s", d.Id(), setErr)
+				}
+				return err
+			}
+			disk["id"] = volumeID
+		}
+		result[i] = disk
+	}
+	return d.Set("data_disks", result)
+}
+
+func getComputeInstanceDataDiskID(disks []interface{}, index int) (string, error) {
+	volumeID, _ := disks[index].(map[string]interface{})["id"].(string)
+	if volumeID == "" {
+		return "", fmt.Errorf("the ID of data_disks.%d is unknown, please refresh the state and try again", index)
+	}
+	return volumeID, nil
+}
+
+func buildComputeInstanceDataDiskBodyParams(cfg *config.Config, d *schema.ResourceData, disk map[string]interface{},
+	index int) map[string]interface{} {
+	volume := map[string]interface{}{
+		"name":                  fmt.Sprintf("%s-volume-%04d", d.Get("name").(string), index+1),
+		"availability_zone":     d.Get("availability_zone"),
+		"volume_type":           disk["type"],
+		"size":                  disk["size"],
+		"snapshot_id":           utils.ValueIgnoreEmpty(disk["snapshot_id"]),
+		"iops":                  utils.ValueIgnoreEmpty(disk["iops"]),
+		"throughput":            utils.ValueIgnoreEmpty(disk["throughput"]),
+		"enterprise_project_id": utils.ValueIgnoreEmpty(cfg.GetEnterpriseProjectID(d)),
+	}
+	if kmsKeyID, _ := disk["kms_key_id"].(string); kmsKeyID != "" {
+		volume["metadata"] = map[string]interface{}{
+			"__system__cmkid":     kmsKeyID,
+			"__system__encrypted": "1",
+		}
+	}
+
+	bodyParams := map[string]interface{}{
+		"volume": utils.RemoveNil(volume),
+	}
+	if dssPoolID, _ := disk["dss_pool_id"].(string); dssPoolID != "" {
+		bodyParams["OS-SCH-HNT:scheduler_hints"] = map[string]interface{}{
+			"dedicated_storage_id": dssPoolID,
+		}
+	}
+	// the prePaid disks are attached by the order and expire together with the instance
+	if d.Get("charging_mode").(string) == "prePaid" {
+		bodyParams["server_id"] = d.Id()
+		bodyParams["bssParam"] = map[string]interface{}{
+			"chargingMode": "prePaid",
+			"isAutoPay":    common.GetAutoPay(d),
+		}
+	}
+	return bodyParams
+}
+
+func createComputeInstanceDataDisk(ctx context.Context, d *schema.ResourceData, cfg *config.Config, ecsClient,
+	evsClient *golangsdk.ServiceClient, disk map[string]interface{}, index int) (string, error) {
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	createPath := evsClient.Endpoint + "v2.1/{project_id}/cloudvolumes"
+	createPath = strings.ReplaceAll(createPath, "{project_id}", evsClient.ProjectID)
+	createOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody:         buildComputeInstanceDataDiskBodyParams(cfg, d, disk, index),
+	}
+	resp, err := evsClient.Request("POST", createPath, &createOpt)
+	if err != nil {
+		return "", fmt.Errorf("error creating data_disks.%d of instance (%s): %s", index, d.Id(), err)
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	volumeID := utils.PathSearch("volume_ids|[0]", respBody, "").(string)
+	if volumeID == "" {
+		return "", fmt.Errorf("error creating data_disks.%d of instance (%s): ID is not found in API response",
+			index, d.Id())
+	}
+	if err := waitForComputeInstanceVolumeJob(ctx, d, cfg, evsClient, respBody); err != nil {
+		return "", fmt.Errorf("error waiting for data disk (%s) to be created: %s", volumeID, err)
+	}
+	if err := waitForComputeInstanceVolumeReady(ctx, evsClient, volumeID, timeout); err != nil {
+		return "", err
+	}
+
+	if d.Get("charging_mode").(string) != "prePaid" {
+		log.Printf("[DEBUG] attaching data disk (%s) to instance (%s)", volumeID, d.Id())
+		attachOpts := block_devices.AttachOpts{
+			VolumeId: volumeID,
+			ServerId: d.Id(),
+		}
+		job, err := block_devices.Attach(ecsClient, attachOpts)
+		if err != nil {
+			return "", fmt.Errorf("error attaching data disk (%s) to instance (%s): %s", volumeID, d.Id(), err)
+		}
+		if err := cloudservers.WaitForJobSuccess(ecsClient, int(timeout/time.Second), job.ID); err != nil {
+			return "", fmt.Errorf("error waiting for data disk (%s) to be attached: %s", volumeID, err)
+		}
+	}
+	return volumeID, nil
+}
+
+func extendComputeInstanceDataDisk(ctx context.Context, d *schema.ResourceData, cfg *config.Config,
+	client *golangsdk.ServiceClient, volumeID string, size int) error {
+	bodyParams := map[string]interface{}{
+		"os-extend": map[string]interface{}{
+			"new_size": size,
+		},
+	}
+	if d.Get("charging_mode").(string) == "prePaid" {
+		bodyParams["bssParam"] = map[string]interface{}{
+			"isAutoPay": common.GetAutoPay(d),
+		}
+	}
+
+	extendPath := client.Endpoint + "v2.1/{project_id}/cloudvolumes/{volume_id}/action"
+	extendPath = strings.ReplaceAll(extendPath, "{project_id}", client.ProjectID)
+	extendPath = strings.ReplaceAll(extendPath, "{volume_id}", volumeID)
+	extendOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody:         bodyParams,
+	}
+	resp, err := client.Request("POST", extendPath, &extendOpt)
+	if err != nil {
+		return err
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if err := waitForComputeInstanceVolumeJob(ctx, d, cfg, client, respBody); err != nil {
+		return err
+	}
+	return waitForComputeInstanceVolumeReady(ctx, client, volumeID, d.Timeout(schema.TimeoutUpdate))
+}
+
+// detachComputeInstanceDataDisk detaches the data disk from the instance and deletes it when
+// delete_disks_on_termination is true.
+func detachComputeInstanceDataDisk(ctx context.Context, d *schema.ResourceData, cfg *config.Config, ecsClient,
+	evsClient *golangsdk.ServiceClient, volumeID string) error {
+	timeout := d.Timeout(schema.TimeoutUpdate)
+
+	log.Printf("[DEBUG] detaching data disk (%s) from instance (%s)", volumeID, d.Id())
+	detachOpts := block_devices.DetachOpts{
+		ServerId: d.Id(),
+	}
+	job, err := block_devices.Detach(ecsClient, volumeID, detachOpts)
+	if err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			log.Printf("[WARN] data disk (%s) is not attached to instance (%s)", volumeID, d.Id())
+			return nil
+		}
+		return fmt.Errorf("error detaching data disk (%s) from instance (%s): %s", volumeID, d.Id(), err)
+	}
+	if err := cloudservers.WaitForJobSuccess(ecsClient, int(timeout/time.Second), job.ID); err != nil {
+		return fmt.Errorf("error waiting for data disk (%s) to be detached: %s", volumeID, err)
+	}
+
+	if !d.Get("delete_disks_on_termination").(bool) {
+		return nil
+	}
+
+	log.Printf("[DEBUG] deleting data disk (%s) of instance (%s)", volumeID, d.Id())
+	if d.Get("charging_mode").(string) == "prePaid" {
+		if err := common.UnsubscribePrePaidResource(d, cfg, []string{volumeID}); err != nil {
+			return fmt.Errorf("error unsubscribing data disk (%s): %s", volumeID, err)
+		}
+		return nil
+	}
+
+	return deleteComputeInstanceVolume(evsClient, volumeID)
+}
+
+// waitForComputeInstanceVolumeJob waits for the order or the job returned by the EVS API.
+func waitForComputeInstanceVolumeJob(ctx context.Context, d *schema.ResourceData, cfg *config.Config,
+	client *golangsdk.ServiceClient, respBody interface{}) error {
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	if orderID := utils.PathSearch("order_id", respBody, "").(string); orderID != "" {
+		bssClient, err := cfg.BssV2Client(cfg.GetRegion(d))
+		if err != nil {
+			return fmt.Errorf("error creating BSS v2 client: %s", err)
+		}
+		if err := common.WaitOrderComplete(ctx, bssClient, orderID, timeout); err != nil {
+			return fmt.Errorf("the order (%s) is not completed: %s", orderID, err)
+		}
+		if _, err := common.WaitOrderAllResourceComplete(ctx, bssClient, orderID, timeout); err != nil {
+			return err
+		}
+	}
+
+	jobID := utils.PathSearch("job_id", respBody, "").(string)
+	if jobID == "" {
+		return nil
+	}
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"PENDING"},
+		Target:  []string{"COMPLETED"},
+		Refresh: func() (interface{}, string, error) {
+			jobPath := client.Endpoint + "v1/{project_id}/jobs/{job_id}"
+			jobPath = strings.ReplaceAll(jobPath, "{project_id}", client.ProjectID)
+			jobPath = strings.ReplaceAll(jobPath, "{job_id}", jobID)
+			resp, err := client.Request("GET", jobPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			jobBody, err := utils.FlattenResponse(resp)
+			if err != nil {
+				return nil, "ERROR", err
+			}
+
+			switch status := utils.PathSearch("status", jobBody, "").(string); status {
+			case "SUCCESS":
+				return jobBody, "COMPLETED", nil
+			case "FAIL":
+				return jobBody, status, fmt.Errorf("the EVS job (%s) failed: %s", jobID,
+					utils.PathSearch("fail_reason", jobBody, "").(string))
+			default:
+				return jobBody, "PENDING", nil
+			}
+		},
+		Timeout:      timeout,
+		Delay:        5 * time.Second,
+		PollInterval: 10 * time.Second,
+	}
+	_, err := stateConf.WaitForStateContext(ctx)
+	return err
+}
+
+func waitForComputeInstanceVolumeReady(ctx context.Context, client *golangsdk.ServiceClient, volumeID string,
+	timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"creating", "extending", "attaching", "downloading", "restoring-backup"},
+		Target:  []string{"available", "in-use"},
+		Refresh: func() (interface{}, string, error) {
+			getPath := client.Endpoint + "v2/{project_id}/cloudvolumes/{volume_id}"
+			getPath = strings.ReplaceAll(getPath, "{project_id}", client.ProjectID)
+			getPath = strings.ReplaceAll(getPath, "{volume_id}", volumeID)
+			resp, err := client.Request("GET", getPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			respBody, err := utils.FlattenResponse(resp)
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			return respBody, utils.PathSearch("volume.status", respBody, "").(string), nil
+		},
+		Timeout:      timeout,
+		Delay:        5 * time.Second,
+		PollInterval: 5 * time.Second,
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error waiting for volume (%s) to become ready: %s", volumeID, err)
+	}
+	return nil
+}
+
+// flattenComputeInstanceDataDisks refreshes the IDs and sizes of the data disks from the attached volumes. The disks
+// which are no longer attached to the instance are removed, the disks without an ID (e.g. created together with the
+// instance) take the first attached data volume of the same type and size and are kept as is if there is none.
+func flattenComputeInstanceDataDisks(disks, attached []interface{}) []interface{} {
+	volumes := make(map[string]map[string]interface{})
+	var volumeIDs []string
+	for _, v := range attached {
+		volume, ok := v.(map[string]interface{})
+		if !ok || volume["boot_index"] == 0 {
+			continue
+		}
+		volumeID := volume["volume_id"].(string)
+		volumes[volumeID] = volume
+		volumeIDs = append(volumeIDs, volumeID)
+	}
+
+	assigned := make(map[string]bool)
+	for _, v := range disks {
+		if volumeID, _ := v.(map[string]interface{})["id"].(string); volumeID != "" {
+			assigned[volumeID] = true
+		}
+	}
+
+	result := make([]interface{}, 0, len(disks))
+	for _, v := range disks {
+		disk := v.(map[string]interface{})
+		volumeID, _ := disk["id"].(string)
+		if volumeID == "" {
+			for _, id := range volumeIDs {
+				volume := volumes[id]
+				if !assigned[id] && strings.EqualFold(volume["type"].(string), disk["type"].(string)) &&
+					volume["size"] == disk["size"] {
+					volumeID = id
+					assigned[id] = true
+					break
+				}
+			}
+		}
+
+		if volumeID == "" {
+			// keep the disk whose volume can not be identified, it will be matched after the size is corrected
+			result = append(result, disk)
+			continue
+		}
+		volume, ok := volumes[volumeID]
+		if !ok {
+			log.Printf("[WARN] the data disk (%s) is no longer attached to the instance", volumeID)
+			continue
+		}
+		disk["id"] = volumeID
+		disk["size"] = volume["size"]
+		result = append(result, disk)
+	}
+	return result
+}
+
+func setComputeInstanceDataDisks(d *schema.ResourceData) error {
+	disks := d.Get("data_disks").([]interface{})
+	if len(disks) == 0 {
+		return nil
+	}
+	return d.Set("data_disks", flattenComputeInstanceDataDisks(disks, d.Get("volume_attached").([]interface{})))
+}
@@ -0,0 +1,316 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/cbr/v3/checkpoints"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/powers"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// invalidCheckpointNameChars are the characters which are not allowed in the name of a CBR checkpoint.
+var invalidCheckpointNameChars = regexp.MustCompile(`[^\w-]`)
+
+func destroyPolicySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"stop_type": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "SOFT",
+					ValidateFunc: validation.StringInSlice([]string{"SOFT", "HARD"}, false),
+				},
+				"grace_period": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      300,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"vault_id": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"backup_name": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// applyComputeInstanceDestroyPolicy stops the instance and backs it up to the CBR vault of destroy_policy, before the
+// instance is deleted by the huaweicloud implementation. The instance is not deleted if the backup fails.
+func applyComputeInstanceDestroyPolicy(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	policies := d.Get("destroy_policy").([]interface{})
+	if len(policies) == 0 || policies[0] == nil {
+		return nil
+	}
+	policy := policies[0].(map[string]interface{})
+
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	ecsClient, err := cfg.ComputeV1Client(region)
+	if err != nil {
+		return diag.Errorf("error creating compute V1 client: %s", err)
+	}
+
+	if err := stopComputeInstanceBeforeDestroy(ctx, d, ecsClient, policy); err != nil {
+		return diag.FromErr(err)
+	}
+
+	vaultID := policy["vault_id"].(string)
+	if vaultID == "" {
+		return nil
+	}
+	cbrClient, err := cfg.CbrV3Client(region)
+	if err != nil {
+		return diag.Errorf("error creating CBR v3 client: %s", err)
+	}
+	checkpointID, err := backupComputeInstanceBeforeDestroy(ctx, d, cbrClient, vaultID, policy["backup_name"].(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[INFO] instance (%s) is backed up to checkpoint (%s) of vault (%s) before it is deleted", d.Id(),
+		checkpointID, vaultID)
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary: fmt.Sprintf("Instance (%s) is backed up to CBR checkpoint %s before it is deleted", d.Id(),
+				checkpointID),
+			Detail: fmt.Sprintf("The backup of the instance is kept in vault %s, it can be restored or deleted "+
+				"with the CBR checkpoint %s.", vaultID, checkpointID),
+		},
+	}
+}
+
+// stopComputeInstanceBeforeDestroy stops the instance with the stop type of destroy_policy. A grace period of 0 powers
+// the instance off forcibly at once. Otherwise, if the instance is not stopped gracefully within the grace period, the
+// soft stop job is waited for, as the instance can not be stopped again while the job is running, and the instance is
+// powered off forcibly if the job fails.
+func stopComputeInstanceBeforeDestroy(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient,
+	policy map[string]interface{}) error {
+	timeout := d.Timeout(schema.TimeoutDelete)
+	deadline := time.Now().Add(timeout)
+	gracePeriod := time.Duration(policy["grace_period"].(int)) * time.Second
+	if policy["stop_type"].(string) == "SOFT" && gracePeriod > 0 {
+		stopped, err := softStopComputeInstance(ctx, client, d.Id(), gracePeriod, timeout)
+		if err != nil {
+			return fmt.Errorf("error stopping instance (%s) before it is deleted: %s", d.Id(), err)
+		}
+		if stopped {
+			return nil
+		}
+		timeout = time.Until(deadline)
+	}
+
+	if err := doComputeInstancePowerActionByType(ctx, client, d.Id(), "os-stop", "HARD", timeout); err != nil {
+		return fmt.Errorf("error stopping instance (%s) before it is deleted: %s", d.Id(), err)
+	}
+	return nil
+}
+
+// softStopComputeInstance stops the instance gracefully and returns whether it is stopped. The stop job is waited for
+// within the grace period, and then until the job is finished within the timeout.
+func softStopComputeInstance(ctx context.Context, client *golangsdk.ServiceClient, serverID string, gracePeriod,
+	timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	server, err := cloudservers.Get(client, serverID).Extract()
+	if err != nil {
+		return false, fmt.Errorf("error retrieving instance (%s): %s", serverID, err)
+	}
+	if server.Status == "SHUTOFF" {
+		return true, nil
+	}
+
+	powerOpts := powers.PowerOpts{
+		Type:    "SOFT",
+		Servers: []powers.ServerInfo{{ID: serverID}},
+	}
+	job, err := powers.PowerAction(client, powerOpts, "os-stop").ExtractJobResponse()
+	if err != nil {
+		return false, fmt.Errorf("doing power action (os-stop) for instance (%s) failed: %s", serverID, err)
+	}
+
+	log.Printf("[DEBUG] stopping instance (%s) gracefully within %s before it is deleted", serverID, gracePeriod)
+	result, err := waitForComputeInstanceJob(ctx, client, job.JobID, gracePeriod)
+	var timeoutErr *resource.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		log.Printf("[WARN] instance (%s) is not stopped within the grace period, waiting for the stop job (%s) to "+
+			"finish before powering it off", serverID, job.JobID)
+		result, err = waitForComputeInstanceJob(ctx, client, job.JobID, time.Until(deadline))
+	}
+	if err != nil {
+		// the job detail is only returned with the error if the job fails
+		if result == nil {
+			return false, err
+		}
+		log.Printf("[WARN] instance (%s) is not stopped gracefully, powering it off: %s", serverID, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+func buildComputeInstanceCheckpointOpts(d *schema.ResourceData, vaultID, backupName string) checkpoints.CreateOpts {
+	if backupName == "" {
+		backupName = fmt.Sprintf("%s-destroy-%s", d.Get("name").(string), time.Now().UTC().Format("20060102150405"))
+	}
+
+	return checkpoints.CreateOpts{
+		VaultId: vaultID,
+		Parameters: checkpoints.CheckpointParameter{
+			Name:        invalidCheckpointNameChars.ReplaceAllString(backupName, "-"),
+			Description: fmt.Sprintf("Created before instance %s is deleted", d.Id()),
+			ResourceDetails: []checkpoints.Resource{
+				{
+					ID:   d.Id(),
+					Type: "OS::Nova::Server",
+					Name: d.Get("name").(string),
+				},
+			},
+		},
+	}
+}
+
+// associateComputeInstanceWithVault adds the instance to the resources of the vault, unless it is already associated.
+// The instance can not be associated by the resources of the vault, which would be destroyed before the instance.
+func associateComputeInstanceWithVault(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient,
+	vaultID string) error {
+	vaultPath := client.Endpoint + "v3/{project_id}/vaults/{vault_id}"
+	vaultPath = strings.ReplaceAll(vaultPath, "{project_id}", client.ProjectID)
+	vaultPath = strings.ReplaceAll(vaultPath, "{vault_id}", vaultID)
+	isAssociated := func() (bool, error) {
+		resp, err := client.Request("GET", vaultPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+		if err != nil {
+			return false, fmt.Errorf("error retrieving CBR vault (%s): %s", vaultID, err)
+		}
+		respBody, err := utils.FlattenResponse(resp)
+		if err != nil {
+			return false, err
+		}
+		expression := fmt.Sprintf("vault.resources[?id=='%s']|[0].id", d.Id())
+		return utils.PathSearch(expression, respBody, "").(string) != "", nil
+	}
+
+	associated, err := isAssociated()
+	if err != nil || associated {
+		return err
+	}
+
+	log.Printf("[DEBUG] associating instance (%s) with vault (%s) to back it up", d.Id(), vaultID)
+	addOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"resources": []map[string]interface{}{
+				{"id": d.Id(), "type": "OS::Nova::Server"},
+			},
+		},
+	}
+	if _, err := client.Request("POST", vaultPath+"/addresources", &addOpt); err != nil {
+		return fmt.Errorf("error associating instance (%s) with vault (%s): %s", d.Id(), vaultID, err)
+	}
+
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"PENDING"},
+		Target:  []string{"COMPLETED"},
+		Refresh: func() (interface{}, string, error) {
+			associated, err := isAssociated()
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			if associated {
+				return associated, "COMPLETED", nil
+			}
+			return associated, "PENDING", nil
+		},
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		Delay:        5 * time.Second,
+		PollInterval: 5 * time.Second,
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error waiting for instance (%s) to be associated with vault (%s): %s", d.Id(), vaultID, err)
+	}
+	return nil
+}
+
+// backupComputeInstanceBeforeDestroy creates a checkpoint of the instance in the vault and waits for its backup to
+// become available.
+func backupComputeInstanceBeforeDestroy(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient,
+	vaultID, backupName string) (string, error) {
+	if err := associateComputeInstanceWithVault(ctx, d, client, vaultID); err != nil {
+		return "", err
+	}
+
+	checkpoint, err := checkpoints.Create(client, buildComputeInstanceCheckpointOpts(d, vaultID, backupName))
+	if err != nil {
+		return "", fmt.Errorf("error backing up instance (%s) to vault (%s): %s", d.Id(), vaultID, err)
+	}
+	if skipped := checkpoint.Vault.SkippedResources; len(skipped) > 0 {
+		return "", fmt.Errorf("error backing up instance (%s) to vault (%s): %s (%s)", d.Id(), vaultID,
+			skipped[0].Reason, skipped[0].Code)
+	}
+
+	log.Printf("[DEBUG] waiting for checkpoint (%s) of instance (%s) to become available", checkpoint.ID, d.Id())
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"PENDING"},
+		Target:  []string{"COMPLETED"},
+		Refresh: func() (interface{}, string, error) {
+			resp, err := checkpoints.Get(client, checkpoint.ID)
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			status, err := getComputeInstanceCheckpointStatus(resp)
+			return resp, status, err
+		},
+		Timeout:      d.Timeout(schema.TimeoutDelete),
+		Delay:        30 * time.Second,
+		PollInterval: 15 * time.Second,
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return "", fmt.Errorf("error waiting for checkpoint (%s) of instance (%s) to become available: %s",
+			checkpoint.ID, d.Id(), err)
+	}
+	return checkpoint.ID, nil
+}
+
+// getComputeInstanceCheckpointStatus returns "COMPLETED" if the checkpoint and the backups of all its resources are
+// available.
+func getComputeInstanceCheckpointStatus(checkpoint *checkpoints.Checkpoint) (string, error) {
+	if checkpoint.Status == "error" {
+		return "ERROR", fmt.Errorf("unexpected checkpoint status (%s)", checkpoint.Status)
+	}
+	if checkpoint.Status != "available" || len(checkpoint.Vault.Resources) == 0 {
+		return "PENDING", nil
+	}
+
+	for _, res := range checkpoint.Vault.Resources {
+		switch res.ProtectStatus {
+		case "available":
+		case "error":
+			return "ERROR", fmt.Errorf("unexpected backup status (%s) of resource (%s)", res.ProtectStatus, res.ID)
+		default:
+			return "PENDING", nil
+		}
+	}
+	return "COMPLETED", nil
+}
@@ -0,0 +1,468 @@
+package ecs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+	"github.com/chnsz/golangsdk/openstack/networking/v1/subnets"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// computeInstanceGroupMaxBatchSize is the maximum number of the instances created by one ECS job.
+const computeInstanceGroupMaxBatchSize = 500
+
+// ResourceComputeInstanceGroup manages a group of identical postPaid ECS instances. The instances are created in
+// batches, each batch by one ECS job, and the group is scaled by creating or deleting only the difference.
+//
+// @API ECS POST /v1/{project_id}/cloudservers
+// @API ECS GET /v1/{project_id}/cloudservers/detail
+// @API ECS POST /v1/{project_id}/cloudservers/delete
+// @API ECS GET /v1/{project_id}/jobs/{job_id}
+// @API VPC GET /v1/{project_id}/subnets/{subnet_id}
+func ResourceComputeInstanceGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceComputeInstanceGroupCreate,
+		ReadContext:   resourceComputeInstanceGroupRead,
+		UpdateContext: resourceComputeInstanceGroupUpdate,
+		DeleteContext: resourceComputeInstanceGroupDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"instance_count": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"batch_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, computeInstanceGroupMaxBatchSize),
+			},
+			"image_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"flavor_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"availability_zone": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"network": {
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"uuid": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+			"security_group_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"system_disk_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "SAS",
+				ValidateFunc: validation.StringInSlice([]string{
+					"SAS", "SSD", "GPSSD", "ESSD", "SATA",
+				}, true),
+			},
+			"system_disk_size": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"key_pair": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"admin_pass"},
+			},
+			"admin_pass": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				ForceNew:  true,
+				Sensitive: true,
+			},
+			"user_data": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				// just stash the hash for state & diff comparisons
+				StateFunc:        utils.HashAndHexEncode,
+				DiffSuppressFunc: utils.SuppressUserData,
+			},
+			"enterprise_project_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"instance_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"instances": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"access_ip_v4": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func buildComputeInstanceGroupBodyParams(cfg *config.Config, d *schema.ResourceData, vpcID string,
+	count int) map[string]interface{} {
+	networks := d.Get("network").([]interface{})
+	nics := make([]map[string]interface{}, len(networks))
+	for i, v := range networks {
+		nics[i] = map[string]interface{}{
+			"subnet_id": v.(map[string]interface{})["uuid"],
+		}
+	}
+
+	secgroupIDs := utils.ExpandToStringListBySet(d.Get("security_group_ids").(*schema.Set))
+	secgroups := make([]map[string]interface{}, len(secgroupIDs))
+	for i, id := range secgroupIDs {
+		secgroups[i] = map[string]interface{}{"id": id}
+	}
+
+	// the state only stores the hash of the user data
+	userData, _ := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "user_data").(string)
+	if _, err := base64.StdEncoding.DecodeString(userData); err != nil {
+		userData = base64.StdEncoding.EncodeToString([]byte(userData))
+	}
+
+	server := map[string]interface{}{
+		"name":              d.Get("name"),
+		"imageRef":          d.Get("image_id"),
+		"flavorRef":         d.Get("flavor_id"),
+		"availability_zone": d.Get("availability_zone"),
+		"vpcid":             vpcID,
+		"nics":              nics,
+		"security_groups":   utils.ValueIgnoreEmpty(secgroups),
+		"root_volume": utils.RemoveNil(map[string]interface{}{
+			"volumetype": d.Get("system_disk_type"),
+			"size":       utils.ValueIgnoreEmpty(d.Get("system_disk_size")),
+		}),
+		"key_name":     utils.ValueIgnoreEmpty(d.Get("key_pair")),
+		"adminPass":    utils.ValueIgnoreEmpty(d.Get("admin_pass")),
+		"user_data":    utils.ValueIgnoreEmpty(userData),
+		"count":        count,
+		"isAutoRename": true,
+	}
+	if epsID := cfg.GetEnterpriseProjectID(d); epsID != "" {
+		server["extendparam"] = map[string]interface{}{
+			"enterprise_project_id": epsID,
+		}
+	}
+
+	return map[string]interface{}{
+		"server": utils.RemoveNil(server),
+	}
+}
+
+// createComputeInstanceGroupInstances creates the instances in batches and returns the IDs of the created instances,
+// which are returned together with the error if a batch fails.
+func createComputeInstanceGroupInstances(ctx context.Context, cfg *config.Config, d *schema.ResourceData,
+	count int, timeout time.Duration) ([]string, error) {
+	region := cfg.GetRegion(d)
+	client, err := cfg.NewServiceClient("ecs", region)
+	if err != nil {
+		return nil, fmt.Errorf("error creating ECS client: %s", err)
+	}
+	vpcClient, err := cfg.NetworkingV1Client(region)
+	if err != nil {
+		return nil, fmt.Errorf("error creating VPC client: %s", err)
+	}
+
+	subnetID := d.Get("network.0.uuid").(string)
+	subnet, err := subnets.Get(vpcClient, subnetID).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving subnet (%s): %s", subnetID, err)
+	}
+
+	createPath := client.Endpoint + "v1/{project_id}/cloudservers"
+	createPath = strings.ReplaceAll(createPath, "{project_id}", client.ProjectID)
+	batchSize := d.Get("batch_size").(int)
+	serverIDs := make([]string, 0, count)
+	for remaining := count; remaining > 0; remaining -= batchSize {
+		batch := remaining
+		if batch > batchSize {
+			batch = batchSize
+		}
+
+		log.Printf("[DEBUG] creating %d instances of group (%s)", batch, d.Get("name"))
+		createOpt := golangsdk.RequestOpts{
+			KeepResponseBody: true,
+			JSONBody:         buildComputeInstanceGroupBodyParams(cfg, d, subnet.VPC_ID, batch),
+		}
+		resp, err := client.Request("POST", createPath, &createOpt)
+		if err != nil {
+			return serverIDs, fmt.Errorf("error creating the instances of group (%s): %s", d.Get("name"), err)
+		}
+		respBody, err := utils.FlattenResponse(resp)
+		if err != nil {
+			return serverIDs, err
+		}
+		jobID := utils.PathSearch("job_id", respBody, "").(string)
+		if jobID == "" {
+			return serverIDs, fmt.Errorf("unable to find the job ID from the API response")
+		}
+
+		job, err := waitForComputeInstanceJob(ctx, client, jobID, timeout)
+		// the instances created by a failed job are kept in the group
+		serverIDs = append(serverIDs, getComputeInstanceGroupJobServerIDs(job)...)
+		if err != nil {
+			return serverIDs, fmt.Errorf("error waiting for the instances of group (%s) to be created: %s",
+				d.Get("name"), err)
+		}
+	}
+	return serverIDs, nil
+}
+
+// getComputeInstanceGroupJobServerIDs returns the IDs of the instances created successfully by the ECS job.
+func getComputeInstanceGroupJobServerIDs(job interface{}) []string {
+	ids := utils.PathSearch("entities.sub_jobs[?status=='SUCCESS'].entities.server_id", job,
+		make([]interface{}, 0)).([]interface{})
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := id.(string); ok && v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func deleteComputeInstanceGroupInstances(cfg *config.Config, d *schema.ResourceData, serverIDs []string,
+	timeout time.Duration) error {
+	if len(serverIDs) == 0 {
+		return nil
+	}
+	client, err := cfg.ComputeV1Client(cfg.GetRegion(d))
+	if err != nil {
+		return fmt.Errorf("error creating compute V1 client: %s", err)
+	}
+
+	servers := make([]cloudservers.Server, len(serverIDs))
+	for i, id := range serverIDs {
+		servers[i] = cloudservers.Server{Id: id}
+	}
+	deleteOpts := cloudservers.DeleteOpts{
+		Servers:      servers,
+		DeleteVolume: true,
+	}
+	log.Printf("[DEBUG] deleting the instances %v of group (%s)", serverIDs, d.Get("name"))
+	job, err := cloudservers.Delete(client, deleteOpts).ExtractJobResponse()
+	if err != nil {
+		return fmt.Errorf("error deleting the instances of group (%s): %s", d.Get("name"), err)
+	}
+	if err := cloudservers.WaitForJobSuccess(client, int(timeout/time.Second), job.JobID); err != nil {
+		return fmt.Errorf("error waiting for the instances of group (%s) to be deleted: %s", d.Get("name"), err)
+	}
+	return nil
+}
+
+func resourceComputeInstanceGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return diag.Errorf("unable to generate ID: %s", err)
+	}
+
+	serverIDs, createErr := createComputeInstanceGroupInstances(ctx, cfg, d, d.Get("instance_count").(int),
+		d.Timeout(schema.TimeoutCreate))
+	if len(serverIDs) > 0 || createErr == nil {
+		d.SetId(id)
+	}
+	if err := d.Set("instance_ids", serverIDs); err != nil {
+		return diag.FromErr(err)
+	}
+	if createErr != nil {
+		return diag.FromErr(createErr)
+	}
+	return resourceComputeInstanceGroupRead(ctx, d, meta)
+}
+
+// listComputeInstanceGroupServers returns the instances whose name starts with the group name.
+func listComputeInstanceGroupServers(client *golangsdk.ServiceClient, name string) ([]interface{}, error) {
+	listPath := client.Endpoint + "v1/{project_id}/cloudservers/detail"
+	listPath = strings.ReplaceAll(listPath, "{project_id}", client.ProjectID)
+	listPath += fmt.Sprintf("?name=%s&limit=1000", url.QueryEscape(name))
+	listOpt := golangsdk.RequestOpts{KeepResponseBody: true}
+
+	result := make([]interface{}, 0)
+	for offset := 1; ; offset++ {
+		resp, err := client.Request("GET", fmt.Sprintf("%s&offset=%d", listPath, offset), &listOpt)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := utils.FlattenResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+		servers := utils.PathSearch("servers", respBody, make([]interface{}, 0)).([]interface{})
+		result = append(result, servers...)
+		if len(servers) < 1000 {
+			return result, nil
+		}
+	}
+}
+
+// flattenComputeInstanceGroupInstances returns the instances of the group in the order of the instance IDs, the
+// instances which no longer exist are removed.
+func flattenComputeInstanceGroupInstances(serverIDs []string, servers []interface{}) ([]string, []map[string]interface{}) {
+	serversByID := make(map[string]interface{}, len(servers))
+	for _, server := range servers {
+		serversByID[utils.PathSearch("id", server, "").(string)] = server
+	}
+
+	ids := make([]string, 0, len(serverIDs))
+	instances := make([]map[string]interface{}, 0, len(serverIDs))
+	for _, id := range serverIDs {
+		server, ok := serversByID[id]
+		if !ok || utils.PathSearch("status", server, "").(string) == "DELETED" {
+			continue
+		}
+		ids = append(ids, id)
+		instances = append(instances, map[string]interface{}{
+			"id":     id,
+			"name":   utils.PathSearch("name", server, nil),
+			"status": utils.PathSearch("status", server, nil),
+			"access_ip_v4": utils.PathSearch("values(addresses)[]|[?version==`4` && \"OS-EXT-IPS:type\"=='fixed']"+
+				"|[0].addr", server, nil),
+		})
+	}
+	return ids, instances
+}
+
+func resourceComputeInstanceGroupRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	client, err := cfg.NewServiceClient("ecs", region)
+	if err != nil {
+		return diag.Errorf("error creating ECS client: %s", err)
+	}
+
+	servers, err := listComputeInstanceGroupServers(client, d.Get("name").(string))
+	if err != nil {
+		return diag.Errorf("error retrieving the instances of group (%s): %s", d.Get("name"), err)
+	}
+	serverIDs := utils.ExpandToStringList(d.Get("instance_ids").([]interface{}))
+	ids, instances := flattenComputeInstanceGroupInstances(serverIDs, servers)
+	if len(ids) == 0 && len(serverIDs) > 0 {
+		log.Printf("[WARN] all the instances of group (%s) are deleted", d.Get("name"))
+	}
+
+	// the instances deleted outside are created again by scaling out the group
+	mErr := multierror.Append(nil,
+		d.Set("region", region),
+		d.Set("instance_count", len(ids)),
+		d.Set("instance_ids", ids),
+		d.Set("instances", instances),
+	)
+	return diag.FromErr(mErr.ErrorOrNil())
+}
+
+func resourceComputeInstanceGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	if d.HasChange("instance_count") {
+		serverIDs := utils.ExpandToStringList(d.Get("instance_ids").([]interface{}))
+		count := d.Get("instance_count").(int)
+		timeout := d.Timeout(schema.TimeoutUpdate)
+
+		if count > len(serverIDs) {
+			created, err := createComputeInstanceGroupInstances(ctx, cfg, d, count-len(serverIDs), timeout)
+			if setErr := d.Set("instance_ids", append(serverIDs, created...)); setErr != nil {
+				return diag.FromErr(setErr)
+			}
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		} else if count < len(serverIDs) {
+			// the instances created last are deleted first
+			if err := deleteComputeInstanceGroupInstances(cfg, d, serverIDs[count:], timeout); err != nil {
+				return diag.FromErr(err)
+			}
+			if err := d.Set("instance_ids", serverIDs[:count]); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+	return resourceComputeInstanceGroupRead(ctx, d, meta)
+}
+
+func resourceComputeInstanceGroupDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*config.Config)
+	serverIDs := utils.ExpandToStringList(d.Get("instance_ids").([]interface{}))
+	if err := deleteComputeInstanceGroupInstances(cfg, d, serverIDs, d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+func TestBuildComputeInstanceGroupBodyParams(t *testing.T) {
+	r := ResourceComputeInstanceGroup()
+	d := r.Data(&terraform.InstanceState{
+		Attributes: map[string]string{
+			"name":                 "worker",
+			"image_id":             "image-id",
+			"flavor_id":            "s6.large.2",
+			"availability_zone":    "ru-moscow-1a",
+			"network.#":            "1",
+			"network.0.uuid":       "subnet-id",
+			"security_group_ids.#": "1",
+			"security_group_ids.0": "secgroup-id",
+			"system_disk_type":     "SSD",
+			"system_disk_size":     "0",
+			"key_pair":             "key",
+		},
+	})
+	expected := map[string]interface{}{
+		"server": map[string]interface{}{
+			"name":              "worker",
+			"imageRef":          "image-id",
+			"flavorRef":         "s6.large.2",
+			"availability_zone": "ru-moscow-1a",
+			"vpcid":             "vpc-id",
+			"nics": []map[string]interface{}{
+				{"subnet_id": "subnet-id"},
+			},
+			"security_groups": []map[string]interface{}{
+				{"id": "secgroup-id"},
+			},
+			"root_volume": map[string]interface{}{
+				"volumetype": "SSD",
+			},
+			"key_name":     "key",
+			"count":        20,
+			"isAutoRename": true,
+		},
+	}
+
+	actual := buildComputeInstanceGroupBodyParams(&config.Config{}, d, "vpc-id", 20)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestGetComputeInstanceGroupJobServerIDs(t *testing.T) {
+	job := map[string]interface{}{
+		"status": "FAIL",
+		"entities": map[string]interface{}{
+			"sub_jobs": []interface{}{
+				map[string]interface{}{"status": "SUCCESS", "entities": map[string]interface{}{"server_id": "server-1"}},
+				map[string]interface{}{"status": "FAIL", "entities": map[string]interface{}{"server_id": ""}},
+				map[string]interface{}{"status": "SUCCESS", "entities": map[string]interface{}{"server_id": "server-3"}},
+			},
+		},
+	}
+
+	expected := []string{"server-1", "server-3"}
+	if actual := getComputeInstanceGroupJobServerIDs(job); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := getComputeInstanceGroupJobServerIDs(nil); len(actual) != 0 {
+		t.Errorf("expected no server IDs without the job, got %v", actual)
+	}
+}
+
+func TestFlattenComputeInstanceGroupInstances(t *testing.T) {
+	servers := []interface{}{
+		map[string]interface{}{
+			"id":     "server-2",
+			"name":   "worker-0002",
+			"status": "ACTIVE",
+			"addresses": map[string]interface{}{
+				"vpc-id": []interface{}{
+					map[string]interface{}{"addr": "fd00::2", "version": float64(6), "OS-EXT-IPS:type": "fixed"},
+					map[string]interface{}{"addr": "192.168.0.12", "version": float64(4), "OS-EXT-IPS:type": "fixed"},
+				},
+			},
+		},
+		map[string]interface{}{
+			"id":     "server-1",
+			"name":   "worker-0001",
+			"status": "SHUTOFF",
+			"addresses": map[string]interface{}{
+				"vpc-id": []interface{}{
+					map[string]interface{}{"addr": "10.0.0.8", "version": float64(4), "OS-EXT-IPS:type": "floating"},
+					map[string]interface{}{"addr": "192.168.0.11", "version": float64(4), "OS-EXT-IPS:type": "fixed"},
+				},
+			},
+		},
+		map[string]interface{}{
+			"id":     "server-3",
+			"name":   "worker-0003",
+			"status": "DELETED",
+		},
+		map[string]interface{}{
+			"id":     "other",
+			"name":   "worker-other",
+			"status": "ACTIVE",
+		},
+	}
+
+	ids, instances := flattenComputeInstanceGroupInstances([]string{"server-1", "server-2", "server-3", "server-4"},
+		servers)
+	expectedIDs := []string{"server-1", "server-2"}
+	expectedInstances := []map[string]interface{}{
+		{"id": "server-1", "name": "worker-0001", "status": "SHUTOFF", "access_ip_v4": "192.168.0.11"},
+		{"id": "server-2", "name": "worker-0002", "status": "ACTIVE", "access_ip_v4": "192.168.0.12"},
+	}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected the IDs %v, got %v", expectedIDs, ids)
+	}
+	if !reflect.DeepEqual(instances, expectedInstances) {
+		t.Errorf("expected the instances %#v, got %#v", expectedInstances, instances)
+	}
+}
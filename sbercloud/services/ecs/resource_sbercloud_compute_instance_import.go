@@ -0,0 +1,194 @@
+package ecs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+	"github.com/chnsz/golangsdk/openstack/evs/v2/cloudvolumes"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// orderPeriodUnits maps the period types of the BSS order to the period units of the instance.
+var orderPeriodUnits = map[int]string{
+	2: "month",
+	3: "year",
+}
+
+// importComputeInstanceState sets the arguments which are not refreshed by Read: the data disks are rebuilt from the
+// attached volumes, the user data from the instance detail, and the period of a prePaid instance from its BSS order.
+// The image update policy is only known by the configuration, so it is set to its default value.
+// The key pair, the charging mode and the scheduler hints are refreshed by Read.
+func importComputeInstanceState(d *schema.ResourceData, meta interface{}) error {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	ecsClient, err := cfg.ComputeV1Client(region)
+	if err != nil {
+		return fmt.Errorf("error creating compute V1 client: %s", err)
+	}
+	evsClient, err := cfg.BlockStorageV2Client(region)
+	if err != nil {
+		return fmt.Errorf("error creating EVS client: %s", err)
+	}
+
+	server, err := cloudservers.Get(ecsClient, d.Id()).Extract()
+	if err != nil {
+		return fmt.Errorf("error retrieving instance (%s): %s", d.Id(), err)
+	}
+
+	dataDisks, err := flattenComputeInstanceImportedDataDisks(server.VolumeAttached,
+		func(volumeID string) (*cloudvolumes.Volume, error) {
+			return cloudvolumes.Get(evsClient, volumeID).Extract()
+		})
+	if err != nil {
+		return fmt.Errorf("error retrieving the data disks of instance (%s): %s", d.Id(), err)
+	}
+
+	mErr := multierror.Append(nil,
+		d.Set("data_disks", dataDisks),
+		d.Set("user_data", flattenComputeInstanceImportedUserData(server.UserData)),
+		d.Set("image_update_policy", imageUpdatePolicyReplace),
+	)
+
+	if normalizeComputeInstanceChargingMode(server.Metadata.ChargingMode) == "prePaid" {
+		periodUnit, period, err := getComputeInstanceOrderPeriod(cfg, region, server.Metadata.OrderID)
+		if err != nil {
+			// the period is only used to create the instance and to change the charging mode to prePaid
+			log.Printf("[WARN] unable to get the period of instance (%s) from order (%s): %s", d.Id(),
+				server.Metadata.OrderID, err)
+		} else {
+			mErr = multierror.Append(mErr,
+				d.Set("period_unit", periodUnit),
+				d.Set("period", period),
+			)
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// flattenComputeInstanceImportedDataDisks returns the data disks of the instance in the order of their devices.
+func flattenComputeInstanceImportedDataDisks(attachments []cloudservers.VolumeAttached,
+	getVolume func(volumeID string) (*cloudvolumes.Volume, error)) ([]map[string]interface{}, error) {
+	dataAttachments := make([]cloudservers.VolumeAttached, 0, len(attachments))
+	for _, v := range attachments {
+		if v.BootIndex != "0" {
+			dataAttachments = append(dataAttachments, v)
+		}
+	}
+	// "/dev/vdb" is followed by "/dev/vdc", and "/dev/vdz" by "/dev/vdaa"
+	sort.SliceStable(dataAttachments, func(i, j int) bool {
+		a, b := dataAttachments[i].Device, dataAttachments[j].Device
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+
+	result := make([]map[string]interface{}, len(dataAttachments))
+	for i, v := range dataAttachments {
+		volume, err := getVolume(v.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving volume (%s): %s", v.ID, err)
+		}
+		result[i] = map[string]interface{}{
+			"id":          v.ID,
+			"type":        volume.VolumeType,
+			"size":        volume.Size,
+			"snapshot_id": volume.SnapshotID,
+			"kms_key_id":  volume.Metadata.SystemCmkID,
+			"iops":        volume.IOPS.TotalVal,
+			"throughput":  volume.Throughput.TotalVal,
+			"dss_pool_id": volume.DedicatedStorageID,
+		}
+	}
+	return result, nil
+}
+
+// flattenComputeInstanceImportedUserData returns the hash of the user data in plain text, as it is stored for the
+// user data of the configuration.
+func flattenComputeInstanceImportedUserData(userData string) string {
+	if userData == "" {
+		return ""
+	}
+	if plain, err := base64.StdEncoding.DecodeString(userData); err == nil {
+		userData = string(plain)
+	}
+	return utils.HashAndHexEncode(userData)
+}
+
+// suppressComputeInstanceUserDataDiffs suppresses the differences of the user data which is specified in base64
+// format and is imported in plain text.
+func suppressComputeInstanceUserDataDiffs(k, old, new string, d *schema.ResourceData) bool {
+	if utils.SuppressUserData(k, old, new, d) {
+		return true
+	}
+
+	userData, _ := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "user_data").(string)
+	if userData == "" || old == "" {
+		return false
+	}
+	plain, err := base64.StdEncoding.DecodeString(userData)
+	return err == nil && utils.HashAndHexEncode(string(plain)) == old
+}
+
+func normalizeComputeInstanceChargingMode(mode string) string {
+	switch mode {
+	case "1":
+		return "prePaid"
+	case "2":
+		return "spot"
+	default:
+		return "postPaid"
+	}
+}
+
+// getComputeInstanceOrderPeriod returns the period unit and the period of the prePaid instance from its BSS order.
+func getComputeInstanceOrderPeriod(cfg *config.Config, region, orderID string) (string, int, error) {
+	if orderID == "" {
+		return "", 0, fmt.Errorf("the order ID is not found in the metadata of the instance")
+	}
+	client, err := cfg.NewServiceClient("bss", region)
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating BSS client: %s", err)
+	}
+
+	getPath := client.Endpoint + "v2/orders/customer-orders/details/{order_id}"
+	getPath = strings.ReplaceAll(getPath, "{order_id}", orderID)
+	resp, err := client.Request("GET", getPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+	if err != nil {
+		return "", 0, err
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return "", 0, err
+	}
+	return flattenComputeInstanceOrderPeriod(respBody)
+}
+
+// flattenComputeInstanceOrderPeriod returns the period of the ECS line item of the order, or of its first line item
+// if the cloud service type of the items is unknown.
+func flattenComputeInstanceOrderPeriod(order interface{}) (string, int, error) {
+	item := utils.PathSearch("order_line_items[?cloud_service_type_code=='hws.service.type.ec2']|[0]", order, nil)
+	if item == nil {
+		item = utils.PathSearch("order_line_items[0]", order, nil)
+	}
+	if item == nil {
+		return "", 0, fmt.Errorf("no line item is found in the order")
+	}
+
+	periodType := int(utils.PathSearch("period_type", item, float64(0)).(float64))
+	periodUnit, ok := orderPeriodUnits[periodType]
+	if !ok {
+		return "", 0, fmt.Errorf("unsupported period type (%d) of the order", periodType)
+	}
+	return periodUnit, int(utils.PathSearch("period_num", item, float64(0)).(float64)), nil
+}
@@ -0,0 +1,30 @@
+package ecs
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	ecs_huawei "github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/services/ecs"
+)
+
+// resourceComputeInstanceV0Type returns the type of the states of version 0. They were written by the huaweicloud
+// resource, which sbercloud_compute_instance was mapped to before it was wrapped. The type is only used by the SDK
+// to decode the flatmap states of Terraform 0.11, the JSON states are passed to the upgrader as they are.
+func resourceComputeInstanceV0Type() cty.Type {
+	return ecs_huawei.ResourceComputeInstance().CoreConfigSchema().ImpliedType()
+}
+
+// resourceComputeInstanceStateUpgradeV0 fills the defaults of the arguments added by the SberCloud layer, so that
+// the states written by the huaweicloud resource do not show a diff.
+func resourceComputeInstanceStateUpgradeV0(_ context.Context, rawState map[string]interface{},
+	_ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	if rawState["image_update_policy"] == nil {
+		rawState["image_update_policy"] = imageUpdatePolicyReplace
+	}
+	return rawState, nil
+}
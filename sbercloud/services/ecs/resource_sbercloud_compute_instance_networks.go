@@ -0,0 +1,304 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/networking/v1/ports"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+// networkReplaceKeys are the attributes of a NIC which can only be set when the NIC is attached.
+var networkReplaceKeys = []string{"uuid", "port", "fixed_ip_v4", "fixed_ip_v6", "ipv6_enable"}
+
+// resourceComputeInstanceNetworksDiff checks the changes of the NICs. The NICs are matched by their position in the
+// list: the NICs appended to the list are attached, the NICs removed from the end of the list are detached, the
+// primary NIC is handled by the huaweicloud implementation and the other NICs can not be changed in place.
+func resourceComputeInstanceNetworksDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("network") {
+		return nil
+	}
+
+	// the subnet and the IPv4 address of the primary NIC are changed by the huaweicloud implementation
+	for _, key := range []string{"port", "fixed_ip_v6", "ipv6_enable"} {
+		if d.HasChange("network.0." + key) {
+			if err := d.ForceNew("network.0." + key); err != nil {
+				return err
+			}
+		}
+	}
+
+	o, n := d.GetChange("network")
+	oldNetworks, newNetworks := o.([]interface{}), n.([]interface{})
+	for i := 1; i < len(oldNetworks) && i < len(newNetworks); i++ {
+		for _, key := range networkReplaceKeys {
+			if d.HasChange(fmt.Sprintf("network.%d.%s", i, key)) {
+				return fmt.Errorf("the %s of network.%d can not be changed, only the security groups and the "+
+					"source/destination check of an attached NIC can be updated, remove the NIC from the end of the "+
+					"list and append a new one instead", key, i)
+			}
+		}
+	}
+	for i := len(oldNetworks); i < len(newNetworks); i++ {
+		key := fmt.Sprintf("network.%d.fixed_ip_v6", i)
+		if d.NewValueKnown(key) && d.Get(key).(string) != "" {
+			return fmt.Errorf("the fixed_ip_v6 of network.%d can only be specified when the instance is created", i)
+		}
+	}
+	return nil
+}
+
+// updateComputeInstanceNetworks detaches the NICs removed from the end of the network list and attaches the new ones.
+// The ports of the new NICs are saved, so that the huaweicloud implementation is able to update their
+// source/destination check.
+func updateComputeInstanceNetworks(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	cfg := meta.(*config.Config)
+	client, err := cfg.NewServiceClient("ecs", cfg.GetRegion(d))
+	if err != nil {
+		return fmt.Errorf("error creating ECS client: %s", err)
+	}
+
+	o, n := d.GetChange("network")
+	oldNetworks, newNetworks := o.([]interface{}), n.([]interface{})
+	timeout := d.Timeout(schema.TimeoutUpdate)
+
+	var detachPorts []interface{}
+	for i := len(newNetworks); i < len(oldNetworks); i++ {
+		if port := oldNetworks[i].(map[string]interface{})["port"].(string); port != "" {
+			detachPorts = append(detachPorts, map[string]interface{}{"id": port})
+		}
+	}
+	if len(detachPorts) > 0 {
+		log.Printf("[DEBUG] detaching NICs %v from instance (%s)", detachPorts, d.Id())
+		if _, err := doComputeInstanceNicsAction(ctx, client, d.Id(), "nics/delete", detachPorts, timeout); err != nil {
+			return fmt.Errorf("error detaching NICs from instance (%s): %s", d.Id(), err)
+		}
+	}
+
+	if len(newNetworks) <= len(oldNetworks) {
+		return nil
+	}
+
+	defaultSecgroups := d.Get("security_group_ids").(*schema.Set).List()
+	for i := len(oldNetworks); i < len(newNetworks); i++ {
+		network := newNetworks[i].(map[string]interface{})
+		nic := buildComputeInstanceNicBodyParams(network, defaultSecgroups)
+
+		log.Printf("[DEBUG] attaching NIC %v to instance (%s)", nic, d.Id())
+		job, err := doComputeInstanceNicsAction(ctx, client, d.Id(), "nics", []interface{}{nic}, timeout)
+		if err != nil {
+			return fmt.Errorf("error attaching network.%d to instance (%s): %s", i, d.Id(), err)
+		}
+		portID := utils.PathSearch("entities.sub_jobs|[0].entities.nic_id", job, "").(string)
+		if portID == "" {
+			return fmt.Errorf("unable to find the port ID of network.%d from the API response", i)
+		}
+		network["port"] = portID
+	}
+	return d.Set("network", newNetworks)
+}
+
+func buildComputeInstanceNicBodyParams(network map[string]interface{}, defaultSecgroups []interface{}) map[string]interface{} {
+	secgroups := defaultSecgroups
+	if v, ok := network["security_group_ids"].(*schema.Set); ok && v.Len() > 0 {
+		secgroups = v.List()
+	}
+	secgroupParams := make([]interface{}, len(secgroups))
+	for i, id := range secgroups {
+		secgroupParams[i] = map[string]interface{}{"id": id}
+	}
+
+	nic := map[string]interface{}{
+		"subnet_id":       network["uuid"],
+		"port_id":         utils.ValueIgnoreEmpty(network["port"]),
+		"ip_address":      utils.ValueIgnoreEmpty(network["fixed_ip_v4"]),
+		"security_groups": utils.ValueIgnoreEmpty(secgroupParams),
+	}
+	if enabled, _ := network["ipv6_enable"].(bool); enabled {
+		nic["ipv6_enable"] = true
+	}
+	return utils.RemoveNil(nic)
+}
+
+// doComputeInstanceNicsAction attaches or detaches the NICs and returns the detail of the finished job.
+func doComputeInstanceNicsAction(ctx context.Context, client *golangsdk.ServiceClient, serverID, action string,
+	nics []interface{}, timeout time.Duration) (interface{}, error) {
+	actionPath := client.Endpoint + "v1/{project_id}/cloudservers/{server_id}/" + action
+	actionPath = strings.ReplaceAll(actionPath, "{project_id}", client.ProjectID)
+	actionPath = strings.ReplaceAll(actionPath, "{server_id}", serverID)
+	actionOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody: map[string]interface{}{
+			"nics": nics,
+		},
+	}
+	resp, err := client.Request("POST", actionPath, &actionOpt)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	jobID := utils.PathSearch("job_id", respBody, "").(string)
+	if jobID == "" {
+		return nil, fmt.Errorf("unable to find the job ID from the API response")
+	}
+	return waitForComputeInstanceJob(ctx, client, jobID, timeout)
+}
+
+// waitForComputeInstanceJob waits for the ECS job to finish and returns its detail, which is returned together with
+// the error if the job fails.
+func waitForComputeInstanceJob(ctx context.Context, client *golangsdk.ServiceClient, jobID string,
+	timeout time.Duration) (interface{}, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"PENDING"},
+		Target:  []string{"SUCCESS"},
+		Refresh: func() (interface{}, string, error) {
+			jobPath := client.Endpoint + "v1/{project_id}/jobs/{job_id}"
+			jobPath = strings.ReplaceAll(jobPath, "{project_id}", client.ProjectID)
+			jobPath = strings.ReplaceAll(jobPath, "{job_id}", jobID)
+			resp, err := client.Request("GET", jobPath, &golangsdk.RequestOpts{KeepResponseBody: true})
+			if err != nil {
+				return nil, "ERROR", err
+			}
+			jobBody, err := utils.FlattenResponse(resp)
+			if err != nil {
+				return nil, "ERROR", err
+			}
+
+			switch status := utils.PathSearch("status", jobBody, "").(string); status {
+			case "SUCCESS":
+				return jobBody, status, nil
+			case "FAIL":
+				return jobBody, status, fmt.Errorf("the job (%s) failed: %s", jobID,
+					utils.PathSearch("fail_reason", jobBody, "").(string))
+			default:
+				return jobBody, "PENDING", nil
+			}
+		},
+		Timeout:      timeout,
+		Delay:        10 * time.Second,
+		PollInterval: 10 * time.Second,
+	}
+	return stateConf.WaitForStateContext(ctx)
+}
+
+// getComputeInstanceNicSecgroupChanges returns the indexes of the NICs whose security groups are changed. It must be
+// called before the huaweicloud implementation refreshes the network list.
+func getComputeInstanceNicSecgroupChanges(d *schema.ResourceData) map[int][]string {
+	o, _ := d.GetChange("network")
+	networks := d.Get("network").([]interface{})
+	changes := make(map[int][]string)
+	for i, v := range networks {
+		// the security groups of the NICs attached in place are set by the attachment, while the NICs created
+		// together with the instance use the security groups of the instance
+		if !d.IsNewResource() && i >= len(o.([]interface{})) {
+			continue
+		}
+		secgroups := v.(map[string]interface{})["security_group_ids"].(*schema.Set)
+		if secgroups.Len() > 0 && (d.HasChange(fmt.Sprintf("network.%d.security_group_ids", i)) ||
+			d.HasChanges("security_group_ids", "security_groups")) {
+			changes[i] = utils.ExpandToStringListBySet(secgroups)
+		}
+	}
+	return changes
+}
+
+// updateComputeInstanceNicSecgroups applies the security groups of the NICs, it is called after the security groups
+// of the instance are updated, which are applied to all NICs.
+func updateComputeInstanceNicSecgroups(d *schema.ResourceData, meta interface{}, changes map[int][]string) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	cfg := meta.(*config.Config)
+	client, err := cfg.NetworkingV1Client(cfg.GetRegion(d))
+	if err != nil {
+		return fmt.Errorf("error creating networking client: %s", err)
+	}
+
+	networks := d.Get("network").([]interface{})
+	for i, secgroups := range changes {
+		if i >= len(networks) {
+			continue
+		}
+		port := networks[i].(map[string]interface{})["port"].(string)
+		if port == "" {
+			continue
+		}
+
+		log.Printf("[DEBUG] updating the security groups of port (%s) to %v", port, secgroups)
+		// keep the allowed address pairs, which disable the source/destination check
+		p, err := ports.Get(client, port)
+		if err != nil {
+			return fmt.Errorf("error retrieving port (%s): %s", port, err)
+		}
+		updateOpts := ports.UpdateOpts{
+			SecurityGroups:      secgroups,
+			AllowedAddressPairs: p.AllowedAddressPairs,
+		}
+		if _, err := ports.Update(client, port, updateOpts); err != nil {
+			return fmt.Errorf("error updating the security groups of network.%d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// setComputeInstanceNicSecgroups refreshes the security groups of the NICs from the ports of the instance, which are
+// listed with a single request.
+func setComputeInstanceNicSecgroups(d *schema.ResourceData, meta interface{}) error {
+	cfg := meta.(*config.Config)
+	client, err := cfg.NetworkingV1Client(cfg.GetRegion(d))
+	if err != nil {
+		return fmt.Errorf("error creating networking client: %s", err)
+	}
+
+	allPages, err := ports.List(client, ports.ListOpts{DeviceID: d.Id()}).AllPages()
+	if err != nil {
+		return fmt.Errorf("error listing the ports of instance (%s): %s", d.Id(), err)
+	}
+	allPorts, err := ports.ExtractPorts(allPages)
+	if err != nil {
+		return fmt.Errorf("error extracting the ports of instance (%s): %s", d.Id(), err)
+	}
+
+	networks := flattenComputeInstanceNicSecgroups(d.Get("network").([]interface{}), allPorts)
+	return d.Set("network", networks)
+}
+
+func flattenComputeInstanceNicSecgroups(networks []interface{}, allPorts []ports.Port) []interface{} {
+	secgroups := make(map[string][]string, len(allPorts))
+	for _, p := range allPorts {
+		secgroups[p.ID] = p.SecurityGroups
+	}
+
+	for _, v := range networks {
+		network := v.(map[string]interface{})
+		port, _ := network["port"].(string)
+		if port == "" {
+			continue
+		}
+		ids, ok := secgroups[port]
+		if !ok {
+			log.Printf("[WARN] the port (%s) is not found in the ports of the instance", port)
+			continue
+		}
+		secgroupIDs := make([]interface{}, len(ids))
+		for i, id := range ids {
+			secgroupIDs[i] = id
+		}
+		network["security_group_ids"] = secgroupIDs
+	}
+	return networks
+}
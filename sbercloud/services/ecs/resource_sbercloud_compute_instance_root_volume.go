@@ -0,0 +1,297 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/block_devices"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+var rootVolumeSourceKeys = []string{
+	"root_volume_replacement.0.snapshot_id",
+	"root_volume_replacement.0.backup_id",
+	"root_volume_replacement.0.image_id",
+}
+
+func rootVolumeReplacementSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"snapshot_id": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ExactlyOneOf: rootVolumeSourceKeys,
+				},
+				"backup_id": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ExactlyOneOf: rootVolumeSourceKeys,
+				},
+				"image_id": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ExactlyOneOf: rootVolumeSourceKeys,
+				},
+				"trigger": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+		},
+	}
+}
+
+// resourceComputeInstanceRootVolumeDiff marks the system disk as computed when root_volume_replacement is added or
+// changed on an existing instance. The replacement specified together with the creation is ignored.
+func resourceComputeInstanceRootVolumeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange("root_volume_replacement") ||
+		len(d.Get("root_volume_replacement").([]interface{})) == 0 {
+		return nil
+	}
+
+	if d.Get("charging_mode").(string) == "prePaid" {
+		return fmt.Errorf("the root volume of a prePaid instance can not be replaced")
+	}
+	if d.HasChanges("image_id", "image_name") {
+		return fmt.Errorf("the root volume can not be replaced together with the image")
+	}
+
+	for _, key := range []string{"system_disk_id", "previous_system_disk_id", "volume_attached"} {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceComputeInstanceRootVolume creates a volume from the source of root_volume_replacement and swaps it with the
+// system disk of the stopped instance. The NICs, the EIP and the data disks are kept, and the previous system disk is
+// kept as well, so that it can be attached again to roll back the replacement. The system disk kept by the last
+// replacement is deleted first.
+func replaceComputeInstanceRootVolume(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	cfg := meta.(*config.Config)
+	region := cfg.GetRegion(d)
+	ecsClient, err := cfg.ComputeV1Client(region)
+	if err != nil {
+		return fmt.Errorf("error creating compute V1 client: %s", err)
+	}
+	evsClient, err := cfg.NewServiceClient("evs", region)
+	if err != nil {
+		return fmt.Errorf("error creating EVS client: %s", err)
+	}
+
+	if err := deletePreviousSystemDisk(evsClient, d); err != nil {
+		return err
+	}
+
+	serverID := d.Id()
+	// the system disk ID is computed in the diff of the replacement, so the value in the state is used
+	oldVolumeID, _ := d.GetChange("system_disk_id")
+	previousVolumeID := oldVolumeID.(string)
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	// keep the instance stopped if it is powered off in the configuration
+	keepStopped := strings.HasSuffix(d.Get("power_action").(string), "OFF")
+	attachment, err := block_devices.Get(ecsClient, serverID, previousVolumeID).Extract()
+	if err != nil {
+		return fmt.Errorf("error retrieving the system disk (%s) of instance (%s): %s", previousVolumeID, serverID, err)
+	}
+
+	// the volume is created before the instance is stopped to shorten the downtime
+	volumeID, err := createComputeInstanceRootVolume(ctx, d, cfg, evsClient)
+	if err != nil {
+		return err
+	}
+
+	// rollback deletes the new volume and starts the instance again, so that a failed replacement leaves the instance
+	// with its previous system disk
+	rollback := func(err error, start bool) error {
+		mErr := multierror.Append(err, deleteComputeInstanceVolume(evsClient, volumeID))
+		if start && !keepStopped {
+			mErr = multierror.Append(mErr, doComputeInstancePowerAction(ctx, ecsClient, serverID, "os-start", timeout))
+		}
+		return mErr.ErrorOrNil()
+	}
+
+	log.Printf("[DEBUG] stopping instance (%s) to replace its system disk (%s) with %s", serverID,
+		previousVolumeID, volumeID)
+	if err := doComputeInstancePowerAction(ctx, ecsClient, serverID, "os-stop", timeout); err != nil {
+		return rollback(err, false)
+	}
+
+	if err := detachComputeInstanceVolume(ecsClient, serverID, previousVolumeID, timeout); err != nil {
+		return rollback(err, true)
+	}
+	if err := attachComputeInstanceVolume(ecsClient, serverID, volumeID, attachment.Device, timeout); err != nil {
+		log.Printf("[WARN] attaching the previous system disk (%s) to instance (%s) again", previousVolumeID, serverID)
+		if rollbackErr := attachComputeInstanceVolume(ecsClient, serverID, previousVolumeID, attachment.Device,
+			timeout); rollbackErr != nil {
+			// the new volume is kept, as the instance has no system disk to start with
+			return fmt.Errorf("%s, and the previous system disk (%s) can not be attached again: %s", err,
+				previousVolumeID, rollbackErr)
+		}
+		return rollback(err, true)
+	}
+	// the system disk ID is used to extend the new volume if system_disk_size is changed in the same update
+	mErr := multierror.Append(nil,
+		d.Set("system_disk_id", volumeID),
+		d.Set("previous_system_disk_id", previousVolumeID),
+	)
+	if err := mErr.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	if !keepStopped {
+		return doComputeInstancePowerAction(ctx, ecsClient, serverID, "os-start", timeout)
+	}
+	return nil
+}
+
+// deleteComputeInstancePreviousSystemDisk deletes the system disk kept by the last root_volume_replacement before the
+// instance is deleted, as the detached volume is not deleted together with the instance.
+func deleteComputeInstancePreviousSystemDisk(d *schema.ResourceData, meta interface{}) error {
+	if oldVolumeID, _ := d.GetChange("previous_system_disk_id"); oldVolumeID.(string) == "" {
+		return nil
+	}
+
+	cfg := meta.(*config.Config)
+	evsClient, err := cfg.NewServiceClient("evs", cfg.GetRegion(d))
+	if err != nil {
+		return fmt.Errorf("error creating EVS client: %s", err)
+	}
+	return deletePreviousSystemDisk(evsClient, d)
+}
+
+func deletePreviousSystemDisk(client *golangsdk.ServiceClient, d *schema.ResourceData) error {
+	oldVolumeID, _ := d.GetChange("previous_system_disk_id")
+	oldSystemDiskID, _ := d.GetChange("system_disk_id")
+	volumeID := oldVolumeID.(string)
+	// the previous system disk may have been attached again to roll back the replacement
+	if volumeID == "" || volumeID == oldSystemDiskID.(string) {
+		return nil
+	}
+
+	log.Printf("[DEBUG] deleting the previous system disk (%s) of instance (%s)", volumeID, d.Id())
+	if err := deleteComputeInstanceVolume(client, volumeID); err != nil {
+		return fmt.Errorf("%s, delete it or attach it to the instance again before retrying", err)
+	}
+	return d.Set("previous_system_disk_id", "")
+}
+
+func buildComputeInstanceRootVolumeBodyParams(cfg *config.Config, d *schema.ResourceData) map[string]interface{} {
+	// the volume is created with the current size, the new size is applied by the huaweicloud implementation
+	size, _ := d.GetChange("system_disk_size")
+	volume := map[string]interface{}{
+		"name":                  fmt.Sprintf("%s-system", d.Get("name").(string)),
+		"availability_zone":     d.Get("availability_zone"),
+		"volume_type":           d.Get("system_disk_type"),
+		"size":                  size,
+		"snapshot_id":           utils.ValueIgnoreEmpty(d.Get("root_volume_replacement.0.snapshot_id")),
+		"backup_id":             utils.ValueIgnoreEmpty(d.Get("root_volume_replacement.0.backup_id")),
+		"imageRef":              utils.ValueIgnoreEmpty(d.Get("root_volume_replacement.0.image_id")),
+		"enterprise_project_id": utils.ValueIgnoreEmpty(cfg.GetEnterpriseProjectID(d)),
+	}
+	if kmsKeyID := d.Get("system_disk_kms_key_id").(string); kmsKeyID != "" {
+		volume["metadata"] = map[string]interface{}{
+			"__system__cmkid":     kmsKeyID,
+			"__system__encrypted": "1",
+		}
+	}
+
+	return map[string]interface{}{
+		"volume": utils.RemoveNil(volume),
+	}
+}
+
+func createComputeInstanceRootVolume(ctx context.Context, d *schema.ResourceData, cfg *config.Config,
+	client *golangsdk.ServiceClient) (string, error) {
+	createPath := client.Endpoint + "v2.1/{project_id}/cloudvolumes"
+	createPath = strings.ReplaceAll(createPath, "{project_id}", client.ProjectID)
+	createOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		JSONBody:         buildComputeInstanceRootVolumeBodyParams(cfg, d),
+	}
+	resp, err := client.Request("POST", createPath, &createOpt)
+	if err != nil {
+		return "", fmt.Errorf("error creating the root volume of instance (%s): %s", d.Id(), err)
+	}
+	respBody, err := utils.FlattenResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	volumeID := utils.PathSearch("volume_ids|[0]", respBody, "").(string)
+	if volumeID == "" {
+		return "", fmt.Errorf("error creating the root volume of instance (%s): ID is not found in API response",
+			d.Id())
+	}
+	if err := waitForComputeInstanceVolumeJob(ctx, d, cfg, client, respBody); err != nil {
+		return "", fmt.Errorf("error waiting for the root volume (%s) to be created: %s", volumeID, err)
+	}
+	if err := waitForComputeInstanceVolumeReady(ctx, client, volumeID, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return "", err
+	}
+	return volumeID, nil
+}
+
+func attachComputeInstanceVolume(client *golangsdk.ServiceClient, serverID, volumeID, device string,
+	timeout time.Duration) error {
+	attachOpts := block_devices.AttachOpts{
+		ServerId: serverID,
+		VolumeId: volumeID,
+		Device:   device,
+	}
+	job, err := block_devices.Attach(client, attachOpts)
+	if err != nil {
+		return fmt.Errorf("error attaching volume (%s) to instance (%s): %s", volumeID, serverID, err)
+	}
+	if err := cloudservers.WaitForJobSuccess(client, int(timeout/time.Second), job.ID); err != nil {
+		return fmt.Errorf("error waiting for volume (%s) to be attached: %s", volumeID, err)
+	}
+	return nil
+}
+
+func detachComputeInstanceVolume(client *golangsdk.ServiceClient, serverID, volumeID string,
+	timeout time.Duration) error {
+	detachOpts := block_devices.DetachOpts{
+		ServerId: serverID,
+	}
+	job, err := block_devices.Detach(client, volumeID, detachOpts)
+	if err != nil {
+		return fmt.Errorf("error detaching volume (%s) from instance (%s): %s", volumeID, serverID, err)
+	}
+	if err := cloudservers.WaitForJobSuccess(client, int(timeout/time.Second), job.ID); err != nil {
+		return fmt.Errorf("error waiting for volume (%s) to be detached: %s", volumeID, err)
+	}
+	return nil
+}
+
+func deleteComputeInstanceVolume(client *golangsdk.ServiceClient, volumeID string) error {
+	deletePath := client.Endpoint + "v2/{project_id}/cloudvolumes/{volume_id}"
+	deletePath = strings.ReplaceAll(deletePath, "{project_id}", client.ProjectID)
+	deletePath = strings.ReplaceAll(deletePath, "{volume_id}", volumeID)
+	deleteOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+	}
+	if _, err := client.Request("DELETE", deletePath, &deleteOpt); err != nil {
+		if _, ok := err.(golangsdk.ErrDefault404); ok {
+			log.Printf("[WARN] volume (%s) has been deleted", volumeID)
+			return nil
+		}
+		return fmt.Errorf("error deleting volume (%s): %s", volumeID, err)
+	}
+	return nil
+}
@@ -0,0 +1,1485 @@
+package ecs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/chnsz/golangsdk"
+	"github.com/chnsz/golangsdk/openstack/cbr/v3/checkpoints"
+	"github.com/chnsz/golangsdk/openstack/ecs/v1/cloudservers"
+	"github.com/chnsz/golangsdk/openstack/evs/v2/cloudvolumes"
+	"github.com/chnsz/golangsdk/openstack/networking/v1/ports"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/utils"
+)
+
+func TestResourceComputeInstance_schema(t *testing.T) {
+	r := ResourceComputeInstance()
+	if err := r.InternalValidate(nil, true); err != nil {
+		t.Fatalf("the schema is invalid: %s", err)
+	}
+	if r.SchemaVersion != 1 || len(r.StateUpgraders) != 1 {
+		t.Errorf("expected a state upgrader from version 0 to 1")
+	}
+
+	t.Setenv("HW_IMAGE_ID", "hw-image")
+	t.Setenv("HW_FLAVOR_ID", "hw-flavor")
+	t.Setenv("SBC_FLAVOR_ID", "sbc-flavor")
+	for key, expected := range map[string]string{"image_id": "hw-image", "flavor_id": "sbc-flavor"} {
+		value, err := r.Schema[key].DefaultValue()
+		if err != nil || value != expected {
+			t.Errorf("expected the default value of %s to be %q, got %v (%v)", key, expected, value, err)
+		}
+	}
+}
+
+func TestResourceComputeInstance_systemDiskType(t *testing.T) {
+	validate := ResourceComputeInstance().Schema["system_disk_type"].ValidateFunc
+	for value, valid := range map[string]bool{"SSD": true, "essd": true, "GPSSD": true, "SATA": true, "HDD": false} {
+		_, errs := validate(value, "system_disk_type")
+		if valid != (len(errs) == 0) {
+			t.Errorf("expected the system disk type %q to be valid: %t, got %v", value, valid, errs)
+		}
+	}
+}
+
+func TestGetImageIDFromConfig(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"images": [{"id": "image-id", "name": %q}]}`, query.Get("name"))
+	}))
+	defer server.Close()
+	client := &golangsdk.ServiceClient{
+		ProviderClient: &golangsdk.ProviderClient{},
+		Endpoint:       server.URL + "/v2/",
+	}
+
+	testCases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected string
+		err      string
+	}{
+		{
+			name:     "image ID",
+			raw:      map[string]interface{}{"image_id": "configured-id", "image_name": "ignored"},
+			expected: "configured-id",
+		},
+		{
+			name:     "image name",
+			raw:      map[string]interface{}{"image_name": "Ubuntu 22.04"},
+			expected: "image-id",
+		},
+		{
+			name: "neither",
+			raw:  map[string]interface{}{},
+			err:  "neither a image ID or image name",
+		},
+	}
+
+	r := ResourceComputeInstance()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("SBC_IMAGE_ID", "")
+			t.Setenv("HW_IMAGE_ID", "")
+			d := schema.TestResourceDataRaw(t, r.Schema, tc.raw)
+			imageID, err := getImageIDFromConfig(d, client)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected an error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if imageID != tc.expected {
+				t.Errorf("expected the image ID %q, got %q", tc.expected, imageID)
+			}
+		})
+	}
+	if query.Get("name") != "Ubuntu 22.04" || query.Get("limit") != "1" {
+		t.Errorf("unexpected image query: %v", query)
+	}
+}
+
+func TestValidateComputeInstanceConfig(t *testing.T) {
+	r := ResourceComputeInstance()
+
+	testCases := []struct {
+		name        string
+		config      map[string]interface{}
+		userID      string
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			config: map[string]interface{}{
+				"flavor_id": "s6.large.2",
+				"image_id":  "ad091b52-742f-469e-8f3c-fd81cadf0743",
+			},
+		},
+		{
+			name:        "missing flavor",
+			config:      map[string]interface{}{"image_name": "Ubuntu 20.04 server 64bit"},
+			expectedErr: "one of `flavor_id, flavor_name` must be specified",
+		},
+		{
+			name:        "missing image",
+			config:      map[string]interface{}{"flavor_name": "s6.large.2"},
+			expectedErr: "one of `image_id, image_name` must be specified",
+		},
+		{
+			name: "prePaid with a key pair",
+			config: map[string]interface{}{
+				"flavor_id":     "s6.large.2",
+				"image_id":      "ad091b52-742f-469e-8f3c-fd81cadf0743",
+				"key_pair":      "test",
+				"charging_mode": "prePaid",
+				"period_unit":   "month",
+				"period":        1,
+			},
+			expectedErr: "user_id must be specified",
+		},
+		{
+			name: "prePaid with a key pair and the provider user ID",
+			config: map[string]interface{}{
+				"flavor_id":     "s6.large.2",
+				"image_id":      "ad091b52-742f-469e-8f3c-fd81cadf0743",
+				"key_pair":      "test",
+				"charging_mode": "prePaid",
+				"period_unit":   "month",
+				"period":        1,
+			},
+			userID: "c6f9b3e0b3f24c3c9c2b2d9e8c1a7f60",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"name":    "test",
+				"network": []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+			}
+			for k, v := range tc.config {
+				raw[k] = v
+			}
+
+			meta := &config.Config{UserID: tc.userID}
+			_, err := r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), meta)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected the error to contain %q, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildComputeInstanceOSChangeBodyParams(t *testing.T) {
+	testCases := []struct {
+		name      string
+		adminPass string
+		keyPair   string
+		userData  string
+		expected  map[string]interface{}
+	}{
+		{
+			name:    "key pair",
+			keyPair: "test",
+			expected: map[string]interface{}{
+				"imageid":   "image-id",
+				"keyname":   "test",
+				"isAutoPay": "true",
+			},
+		},
+		{
+			name:      "plain user data",
+			adminPass: "Test@123",
+			userData:  "#!/bin/bash",
+			expected: map[string]interface{}{
+				"imageid":   "image-id",
+				"adminpass": "Test@123",
+				"isAutoPay": "true",
+				"metadata":  map[string]interface{}{"user_data": "IyEvYmluL2Jhc2g="},
+			},
+		},
+		{
+			name:     "base64 user data",
+			userData: "IyEvYmluL2Jhc2g=",
+			expected: map[string]interface{}{
+				"imageid":   "image-id",
+				"isAutoPay": "true",
+				"metadata":  map[string]interface{}{"user_data": "IyEvYmluL2Jhc2g="},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := buildComputeInstanceOSChangeBodyParams("image-id", tc.adminPass, tc.keyPair, tc.userData)
+			if !reflect.DeepEqual(body["os-change"], tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, body["os-change"])
+			}
+		})
+	}
+}
+
+func TestResourceComputeInstanceImageUpdatePolicyDiff(t *testing.T) {
+	r := ResourceComputeInstance()
+	meta := &config.Config{Region: "ru-moscow-1"}
+	state := &terraform.InstanceState{
+		ID: "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		Attributes: map[string]string{
+			"id":                  "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+			"name":                "test",
+			"flavor_id":           "s6.large.2",
+			"image_id":            "old-image-id",
+			"image_name":          "Ubuntu 18.04 server 64bit",
+			"image_update_policy": "replace",
+			"key_pair":            "old-key",
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		config      map[string]interface{}
+		requiresNew bool
+		computed    string
+		expectedErr string
+	}{
+		{
+			name:        "replace",
+			config:      map[string]interface{}{"image_id": "new-image-id"},
+			requiresNew: true,
+		},
+		{
+			name:     "rebuild by ID",
+			config:   map[string]interface{}{"image_id": "new-image-id", "image_update_policy": "rebuild"},
+			computed: "image_name",
+		},
+		{
+			name:     "rebuild by name",
+			config:   map[string]interface{}{"image_name": "Ubuntu 20.04 server 64bit", "image_update_policy": "rebuild"},
+			computed: "image_id",
+		},
+		{
+			name: "rebuild with a new key pair",
+			config: map[string]interface{}{
+				"image_id": "new-image-id", "image_update_policy": "rebuild", "key_pair": "new-key",
+			},
+			expectedErr: "key_pair can not be changed together with the image",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"name":      "test",
+				"flavor_id": "s6.large.2",
+				"key_pair":  "old-key",
+				"network":   []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+			}
+			for k, v := range tc.config {
+				raw[k] = v
+			}
+
+			diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected the error to contain %q, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, key := range []string{"image_id", "image_name"} {
+				if _, ok := tc.config[key]; !ok {
+					continue
+				}
+				if attr, ok := diff.Attributes[key]; !ok || attr.RequiresNew != tc.requiresNew {
+					t.Errorf("expected a change of %s with RequiresNew %t, got: %#v", key, tc.requiresNew, attr)
+				}
+			}
+			if tc.computed != "" {
+				if attr, ok := diff.Attributes[tc.computed]; !ok || !attr.NewComputed {
+					t.Errorf("expected %s to be computed after the rebuild", tc.computed)
+				}
+			}
+		})
+	}
+}
+
+func TestResourceComputeInstanceStateUpgradeV0(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "default policy",
+			state: map[string]interface{}{
+				"id":                  "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+				"stop_before_destroy": true,
+				"network": []interface{}{
+					map[string]interface{}{"uuid": "subnet-1", "source_dest_check": false},
+				},
+			},
+			expected: map[string]interface{}{
+				"id":                  "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+				"stop_before_destroy": true,
+				"image_update_policy": "replace",
+				"network": []interface{}{
+					map[string]interface{}{"uuid": "subnet-1", "source_dest_check": false},
+				},
+			},
+		},
+		{
+			name: "policy already set",
+			state: map[string]interface{}{
+				"id":                  "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+				"image_update_policy": "rebuild",
+			},
+			expected: map[string]interface{}{
+				"id":                  "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+				"image_update_policy": "rebuild",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resourceComputeInstanceStateUpgradeV0(context.Background(), tc.state, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestResourceComputeInstanceV0Type checks that the states of version 0 decode with the huaweicloud schema, which
+// wrote them.
+func TestResourceComputeInstanceV0Type(t *testing.T) {
+	v0Type := resourceComputeInstanceV0Type()
+	for _, key := range []string{"name", "network", "data_disks", "delete_eip_on_termination"} {
+		if !v0Type.HasAttribute(key) {
+			t.Errorf("expected the attribute %s in the schema of version 0", key)
+		}
+	}
+	for _, key := range []string{"image_update_policy", "root_volume_replacement", "destroy_policy"} {
+		if v0Type.HasAttribute(key) {
+			t.Errorf("unexpected attribute %s in the schema of version 0", key)
+		}
+	}
+}
+
+func TestResourceComputeInstanceDataDisksDiff(t *testing.T) {
+	r := ResourceComputeInstance()
+	state := &terraform.InstanceState{
+		ID: "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		Attributes: map[string]string{
+			"id":                "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+			"name":              "test",
+			"flavor_id":         "s6.large.2",
+			"image_id":          "ad091b52-742f-469e-8f3c-fd81cadf0743",
+			"data_disks.#":      "1",
+			"data_disks.0.id":   "volume-1",
+			"data_disks.0.type": "SSD",
+			"data_disks.0.size": "20",
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		disks       []interface{}
+		requiresNew string
+	}{
+		{
+			name: "extend and attach",
+			disks: []interface{}{
+				map[string]interface{}{"type": "SSD", "size": 40},
+				map[string]interface{}{"type": "SAS", "size": 10},
+			},
+		},
+		{
+			name: "detach",
+		},
+		{
+			name:        "shrink",
+			disks:       []interface{}{map[string]interface{}{"type": "SSD", "size": 10}},
+			requiresNew: "data_disks.0.size",
+		},
+		{
+			name:        "change the type",
+			disks:       []interface{}{map[string]interface{}{"type": "SAS", "size": 20}},
+			requiresNew: "data_disks.0.type",
+		},
+		{
+			name:        "change the snapshot",
+			disks:       []interface{}{map[string]interface{}{"type": "SSD", "size": 20, "snapshot_id": "snapshot-id"}},
+			requiresNew: "data_disks.0.snapshot_id",
+		},
+		{
+			name:        "change the KMS key",
+			disks:       []interface{}{map[string]interface{}{"type": "SSD", "size": 20, "kms_key_id": "key-id"}},
+			requiresNew: "data_disks.0.kms_key_id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"name":      "test",
+				"flavor_id": "s6.large.2",
+				"image_id":  "ad091b52-742f-469e-8f3c-fd81cadf0743",
+				"network":   []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+			}
+			if tc.disks != nil {
+				raw["data_disks"] = tc.disks
+			}
+
+			diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), &config.Config{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for key, attr := range diff.Attributes {
+				if !strings.HasPrefix(key, "data_disks") {
+					continue
+				}
+				if key == tc.requiresNew && !attr.RequiresNew {
+					t.Errorf("expected %s to replace the instance", key)
+				}
+				if key != tc.requiresNew && attr.RequiresNew {
+					t.Errorf("expected %s to be updated in place", key)
+				}
+			}
+			if tc.requiresNew != "" && diff.Attributes[tc.requiresNew] == nil {
+				t.Errorf("expected a diff of %s", tc.requiresNew)
+			}
+		})
+	}
+}
+
+func TestFlattenComputeInstanceDataDisks(t *testing.T) {
+	attached := []interface{}{
+		map[string]interface{}{"volume_id": "system", "type": "SSD", "size": 40, "boot_index": 0},
+		map[string]interface{}{"volume_id": "volume-1", "type": "SSD", "size": 30, "boot_index": 1},
+		map[string]interface{}{"volume_id": "volume-2", "type": "SAS", "size": 10, "boot_index": 2},
+		map[string]interface{}{"volume_id": "volume-3", "type": "SAS", "size": 10, "boot_index": 3},
+	}
+	disks := []interface{}{
+		// resized outside of terraform
+		map[string]interface{}{"id": "volume-1", "type": "SSD", "size": 20},
+		// detached outside of terraform
+		map[string]interface{}{"id": "volume-4", "type": "SSD", "size": 20},
+		// created together with the instance
+		map[string]interface{}{"id": "", "type": "sas", "size": 10},
+		map[string]interface{}{"id": "", "type": "GPSSD", "size": 10},
+	}
+	expected := []interface{}{
+		map[string]interface{}{"id": "volume-1", "type": "SSD", "size": 30},
+		map[string]interface{}{"id": "volume-2", "type": "sas", "size": 10},
+		map[string]interface{}{"id": "", "type": "GPSSD", "size": 10},
+	}
+
+	actual := flattenComputeInstanceDataDisks(disks, attached)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestResourceComputeInstanceNetworksDiff(t *testing.T) {
+	r := ResourceComputeInstance()
+	state := &terraform.InstanceState{
+		ID: "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		Attributes: map[string]string{
+			"id":                             "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+			"name":                           "test",
+			"flavor_id":                      "s6.large.2",
+			"image_id":                       "ad091b52-742f-469e-8f3c-fd81cadf0743",
+			"network.#":                      "2",
+			"network.0.uuid":                 "subnet-1",
+			"network.0.port":                 "port-1",
+			"network.0.fixed_ip_v4":          "192.168.0.10",
+			"network.0.source_dest_check":    "true",
+			"network.0.access_network":       "false",
+			"network.0.ipv6_enable":          "false",
+			"network.1.uuid":                 "subnet-2",
+			"network.1.port":                 "port-2",
+			"network.1.fixed_ip_v4":          "192.168.1.10",
+			"network.1.source_dest_check":    "true",
+			"network.1.access_network":       "false",
+			"network.1.ipv6_enable":          "false",
+			"network.1.security_group_ids.#": "0",
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		networks    []interface{}
+		requiresNew bool
+		expectedErr string
+	}{
+		{
+			name: "attach",
+			networks: []interface{}{
+				map[string]interface{}{"uuid": "subnet-1"},
+				map[string]interface{}{"uuid": "subnet-2", "security_group_ids": []interface{}{"secgroup-1"}},
+				map[string]interface{}{"uuid": "subnet-3", "fixed_ip_v4": "192.168.2.10", "ipv6_enable": true},
+			},
+		},
+		{
+			name:     "detach",
+			networks: []interface{}{map[string]interface{}{"uuid": "subnet-1"}},
+		},
+		{
+			name: "enable IPv6 of the primary NIC",
+			networks: []interface{}{
+				map[string]interface{}{"uuid": "subnet-1", "ipv6_enable": true},
+				map[string]interface{}{"uuid": "subnet-2"},
+			},
+			requiresNew: true,
+		},
+		{
+			name: "change the subnet of an attached NIC",
+			networks: []interface{}{
+				map[string]interface{}{"uuid": "subnet-1"},
+				map[string]interface{}{"uuid": "subnet-3"},
+			},
+			expectedErr: "the uuid of network.1 can not be changed",
+		},
+		{
+			name: "attach with an IPv6 address",
+			networks: []interface{}{
+				map[string]interface{}{"uuid": "subnet-1"},
+				map[string]interface{}{"uuid": "subnet-2"},
+				map[string]interface{}{"uuid": "subnet-3", "ipv6_enable": true, "fixed_ip_v6": "2001:db8::10"},
+			},
+			expectedErr: "the fixed_ip_v6 of network.2 can only be specified when the instance is created",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"name":      "test",
+				"flavor_id": "s6.large.2",
+				"image_id":  "ad091b52-742f-469e-8f3c-fd81cadf0743",
+				"network":   tc.networks,
+			}
+
+			diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), &config.Config{})
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected the error to contain %q, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var requiresNew bool
+			for key, attr := range diff.Attributes {
+				if strings.HasPrefix(key, "network") && attr.RequiresNew {
+					requiresNew = true
+				}
+			}
+			if requiresNew != tc.requiresNew {
+				t.Errorf("expected the network changes with RequiresNew %t, got: %#v", tc.requiresNew, diff.Attributes)
+			}
+		})
+	}
+}
+
+func TestBuildComputeInstanceNicBodyParams(t *testing.T) {
+	defaultSecgroups := []interface{}{"secgroup-default"}
+
+	testCases := []struct {
+		name     string
+		network  map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "instance security groups",
+			network: map[string]interface{}{
+				"uuid":               "subnet-id",
+				"port":               "",
+				"fixed_ip_v4":        "",
+				"ipv6_enable":        false,
+				"security_group_ids": schema.NewSet(schema.HashString, nil),
+			},
+			expected: map[string]interface{}{
+				"subnet_id":       "subnet-id",
+				"security_groups": []interface{}{map[string]interface{}{"id": "secgroup-default"}},
+			},
+		},
+		{
+			name: "NIC security groups",
+			network: map[string]interface{}{
+				"uuid":               "subnet-id",
+				"fixed_ip_v4":        "192.168.0.10",
+				"ipv6_enable":        true,
+				"security_group_ids": schema.NewSet(schema.HashString, []interface{}{"secgroup-nic"}),
+			},
+			expected: map[string]interface{}{
+				"subnet_id":       "subnet-id",
+				"ip_address":      "192.168.0.10",
+				"ipv6_enable":     true,
+				"security_groups": []interface{}{map[string]interface{}{"id": "secgroup-nic"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := buildComputeInstanceNicBodyParams(tc.network, defaultSecgroups)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenComputeInstanceNicSecgroups(t *testing.T) {
+	networks := []interface{}{
+		map[string]interface{}{"port": "port-1", "security_group_ids": []interface{}{"secgroup-1"}},
+		map[string]interface{}{"port": "port-2", "security_group_ids": []interface{}{"secgroup-1"}},
+		map[string]interface{}{"port": "", "security_group_ids": []interface{}{}},
+	}
+	allPorts := []ports.Port{
+		{ID: "port-1", SecurityGroups: []string{"secgroup-1", "secgroup-2"}},
+		{ID: "port-3", SecurityGroups: []string{"secgroup-3"}},
+	}
+	expected := []interface{}{
+		map[string]interface{}{"port": "port-1", "security_group_ids": []interface{}{"secgroup-1", "secgroup-2"}},
+		map[string]interface{}{"port": "port-2", "security_group_ids": []interface{}{"secgroup-1"}},
+		map[string]interface{}{"port": "", "security_group_ids": []interface{}{}},
+	}
+
+	actual := flattenComputeInstanceNicSecgroups(networks, allPorts)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestResourceComputeInstanceRootVolumeDiff(t *testing.T) {
+	r := ResourceComputeInstance()
+	attributes := map[string]string{
+		"id":                "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		"name":              "test",
+		"flavor_id":         "s6.large.2",
+		"image_id":          "ad091b52-742f-469e-8f3c-fd81cadf0743",
+		"system_disk_id":    "volume-system",
+		"charging_mode":     "postPaid",
+		"network.#":         "1",
+		"network.0.uuid":    "subnet-id",
+		"network.0.port":    "port-id",
+		"system_disk_type":  "SAS",
+		"system_disk_size":  "40",
+		"availability_zone": "ru-moscow-1a",
+	}
+
+	testCases := []struct {
+		name         string
+		config       map[string]interface{}
+		chargingMode string
+		computed     bool
+		expectedErr  string
+	}{
+		{
+			name: "snapshot",
+			config: map[string]interface{}{
+				"root_volume_replacement": []interface{}{map[string]interface{}{"snapshot_id": "snapshot-id"}},
+			},
+			computed: true,
+		},
+		{
+			name: "prePaid",
+			config: map[string]interface{}{
+				"root_volume_replacement": []interface{}{map[string]interface{}{"snapshot_id": "snapshot-id"}},
+			},
+			chargingMode: "prePaid",
+			expectedErr:  "the root volume of a prePaid instance can not be replaced",
+		},
+		{
+			name: "together with the image",
+			config: map[string]interface{}{
+				"image_id":                "new-image-id",
+				"image_update_policy":     "rebuild",
+				"root_volume_replacement": []interface{}{map[string]interface{}{"image_id": "new-image-id"}},
+			},
+			expectedErr: "the root volume can not be replaced together with the image",
+		},
+		{
+			name: "multiple sources",
+			config: map[string]interface{}{
+				"root_volume_replacement": []interface{}{
+					map[string]interface{}{"snapshot_id": "snapshot-id", "backup_id": "backup-id"},
+				},
+			},
+			expectedErr: "only one of",
+		},
+		{
+			name: "backup and image",
+			config: map[string]interface{}{
+				"root_volume_replacement": []interface{}{
+					map[string]interface{}{"backup_id": "backup-id", "image_id": "image-id"},
+				},
+			},
+			expectedErr: "only one of",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &terraform.InstanceState{ID: attributes["id"], Attributes: make(map[string]string)}
+			for k, v := range attributes {
+				state.Attributes[k] = v
+			}
+			raw := map[string]interface{}{
+				"name":      "test",
+				"flavor_id": "s6.large.2",
+				"image_id":  "ad091b52-742f-469e-8f3c-fd81cadf0743",
+				"network":   []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+			}
+			if tc.chargingMode != "" {
+				state.Attributes["charging_mode"] = tc.chargingMode
+				raw["charging_mode"] = tc.chargingMode
+				raw["period_unit"] = "month"
+				raw["period"] = 1
+			}
+			for k, v := range tc.config {
+				raw[k] = v
+			}
+
+			cfg := terraform.NewResourceConfigRaw(raw)
+			diags := r.Validate(cfg)
+			diff, err := r.SimpleDiff(context.Background(), state, cfg, &config.Config{UserID: "user-id"})
+			if diags.HasError() && err == nil {
+				err = fmt.Errorf("%v", diags)
+			}
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected the error to contain %q, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, key := range []string{"system_disk_id", "previous_system_disk_id"} {
+				if attr, ok := diff.Attributes[key]; !ok || attr.NewComputed != tc.computed || attr.RequiresNew {
+					t.Errorf("expected %s to be computed in place, got: %#v", key, attr)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildComputeInstanceRootVolumeBodyParams(t *testing.T) {
+	r := ResourceComputeInstance()
+	d := r.Data(&terraform.InstanceState{
+		ID: "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		Attributes: map[string]string{
+			"name":                                  "test",
+			"availability_zone":                     "ru-moscow-1a",
+			"system_disk_type":                      "SAS",
+			"system_disk_size":                      "40",
+			"root_volume_replacement.#":             "1",
+			"root_volume_replacement.0.backup_id":   "backup-id",
+			"root_volume_replacement.0.snapshot_id": "",
+			"root_volume_replacement.0.image_id":    "",
+		},
+	})
+	expected := map[string]interface{}{
+		"volume": map[string]interface{}{
+			"name":              "test-system",
+			"availability_zone": "ru-moscow-1a",
+			"volume_type":       "SAS",
+			"size":              40,
+			"backup_id":         "backup-id",
+		},
+	}
+
+	actual := buildComputeInstanceRootVolumeBodyParams(&config.Config{}, d)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func newTestComputeInstanceCatalog(cfg *config.Config) (*computeInstanceCatalog, map[string]int) {
+	fetches := make(map[string]int)
+	c := newComputeInstanceCatalog(cfg)
+	c.fetchFlavors = func(_ string) ([]computeFlavor, error) {
+		fetches["flavors"]++
+		return []computeFlavor{
+			{ID: "s6.large.2", Name: "s6.large.2", Architecture: "x86", Status: "normal",
+				AZStatus: map[string]string{"ru-moscow-1b": "sellout"}},
+			{ID: "kc1.large.2", Name: "kc1.large.2", Architecture: "arm", Status: "sellout",
+				AZStatus: map[string]string{"ru-moscow-1a": "normal"}},
+		}, nil
+	}
+	c.fetchImage = func(_, id, name string) (*computeImage, error) {
+		fetches["images"]++
+		switch {
+		case id == "image-x86" || name == "Ubuntu 20.04 server 64bit":
+			return &computeImage{ID: "image-x86", Name: "Ubuntu 20.04 server 64bit", Architecture: "x86"}, nil
+		case id == "image-arm":
+			return &computeImage{ID: "image-arm", Name: "Ubuntu 20.04 server 64bit for Kunpeng", Architecture: "arm"}, nil
+		}
+		return nil, fmt.Errorf("unable to find image %s%s", id, name)
+	}
+	c.fetchVolumeTypes = func(_ string) (map[string]volumeTypeAvailability, error) {
+		fetches["volume_types"]++
+		return map[string]volumeTypeAvailability{
+			"SAS":  {},
+			"SSD":  {AZs: []string{"ru-moscow-1a", "ru-moscow-1b"}, SoldOutAZs: []string{"ru-moscow-1b"}},
+			"ESSD": {AZs: []string{"ru-moscow-1a"}},
+		}, nil
+	}
+	cfg.Metadata = c
+	return c, fetches
+}
+
+func TestDeletePreviousSystemDisk(t *testing.T) {
+	testCases := []struct {
+		name            string
+		previousDiskID  string
+		status          int
+		expectedDeletes int
+		expectedErr     bool
+	}{
+		{
+			name: "no previous system disk",
+		},
+		{
+			name:           "previous system disk attached again",
+			previousDiskID: "system-disk-id",
+		},
+		{
+			name:            "previous system disk deleted",
+			previousDiskID:  "previous-disk-id",
+			status:          http.StatusOK,
+			expectedDeletes: 1,
+		},
+		{
+			name:            "previous system disk not found",
+			previousDiskID:  "previous-disk-id",
+			status:          http.StatusNotFound,
+			expectedDeletes: 1,
+		},
+		{
+			name:            "previous system disk in use",
+			previousDiskID:  "previous-disk-id",
+			status:          http.StatusBadRequest,
+			expectedDeletes: 1,
+			expectedErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deletes := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "DELETE" || !strings.HasSuffix(r.URL.Path, "/cloudvolumes/previous-disk-id") {
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				deletes++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, `{"job_id": "job-id"}`)
+			}))
+			defer server.Close()
+			client := &golangsdk.ServiceClient{
+				ProviderClient: &golangsdk.ProviderClient{ProjectID: "project-id"},
+				Endpoint:       server.URL + "/",
+			}
+
+			d := ResourceComputeInstance().Data(&terraform.InstanceState{
+				ID: "server-id",
+				Attributes: map[string]string{
+					"system_disk_id":          "system-disk-id",
+					"previous_system_disk_id": tc.previousDiskID,
+				},
+			})
+			err := deletePreviousSystemDisk(client, d)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error %t, got: %v", tc.expectedErr, err)
+			}
+			if deletes != tc.expectedDeletes {
+				t.Errorf("expected %d deletions, got: %d", tc.expectedDeletes, deletes)
+			}
+			expectedID := tc.previousDiskID
+			if tc.expectedDeletes > 0 && !tc.expectedErr {
+				expectedID = ""
+			}
+			if got := d.Get("previous_system_disk_id").(string); got != expectedID {
+				t.Errorf("expected previous_system_disk_id %q, got: %q", expectedID, got)
+			}
+		})
+	}
+}
+
+func TestValidateComputeInstanceCompatibility(t *testing.T) {
+	r := ResourceComputeInstance()
+
+	testCases := []struct {
+		name        string
+		config      map[string]interface{}
+		expectedErr string
+	}{
+		{
+			name: "compatible",
+			config: map[string]interface{}{
+				"flavor_id":         "s6.large.2",
+				"image_name":        "Ubuntu 20.04 server 64bit",
+				"availability_zone": "ru-moscow-1a",
+				"system_disk_type":  "SSD",
+				"data_disks":        []interface{}{map[string]interface{}{"type": "ESSD", "size": 10}},
+			},
+		},
+		{
+			name: "without the AZ",
+			config: map[string]interface{}{
+				"flavor_name": "kc1.large.2",
+				"image_id":    "image-arm",
+			},
+		},
+		{
+			name: "unknown flavor",
+			config: map[string]interface{}{
+				"flavor_id": "s7.large.2",
+				"image_id":  "image-x86",
+			},
+			expectedErr: "the flavor s7.large.2 is not found in region ru-moscow-1",
+		},
+		{
+			name: "flavor sold out in the AZ",
+			config: map[string]interface{}{
+				"flavor_id":         "s6.large.2",
+				"image_id":          "image-x86",
+				"availability_zone": "ru-moscow-1b",
+			},
+			expectedErr: "the flavor s6.large.2 is sold out in availability zone ru-moscow-1b",
+		},
+		{
+			name: "flavor sold out in the region",
+			config: map[string]interface{}{
+				"flavor_id":         "kc1.large.2",
+				"image_id":          "image-arm",
+				"availability_zone": "ru-moscow-1c",
+			},
+			expectedErr: "the flavor kc1.large.2 is sold out in availability zone ru-moscow-1c",
+		},
+		{
+			name: "architecture mismatch",
+			config: map[string]interface{}{
+				"flavor_id": "kc1.large.2",
+				"image_id":  "image-x86",
+			},
+			expectedErr: "the image image-x86 (x86) does not match the architecture of the flavor kc1.large.2 (arm)",
+		},
+		{
+			name: "unknown image",
+			config: map[string]interface{}{
+				"flavor_id": "s6.large.2",
+				"image_id":  "image-private",
+			},
+		},
+		{
+			name: "disk types",
+			config: map[string]interface{}{
+				"flavor_id":         "s6.large.2",
+				"image_id":          "image-x86",
+				"availability_zone": "ru-moscow-1b",
+				"system_disk_type":  "ESSD",
+				"data_disks": []interface{}{
+					map[string]interface{}{"type": "SAS", "size": 10},
+					map[string]interface{}{"type": "ssd", "size": 10},
+				},
+			},
+			expectedErr: "the disk type ESSD of system_disk_type is not offered in availability zone ru-moscow-1b",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"name":    "test",
+				"network": []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+			}
+			for k, v := range tc.config {
+				raw[k] = v
+			}
+
+			meta := &config.Config{Region: "ru-moscow-1"}
+			newTestComputeInstanceCatalog(meta)
+			_, err := r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), meta)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected the error to contain %q, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateComputeInstanceCompatibility_cache(t *testing.T) {
+	r := ResourceComputeInstance()
+	meta := &config.Config{Region: "ru-moscow-1"}
+	_, fetches := newTestComputeInstanceCatalog(meta)
+
+	for i := 0; i < 200; i++ {
+		raw := map[string]interface{}{
+			"name":              fmt.Sprintf("test-%03d", i),
+			"flavor_id":         "s6.large.2",
+			"image_id":          "image-x86",
+			"availability_zone": "ru-moscow-1a",
+			"system_disk_type":  "SSD",
+			"network":           []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+		}
+		if _, err := r.SimpleDiff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), meta); err != nil {
+			t.Fatalf("unexpected error for instance %d: %s", i, err)
+		}
+	}
+
+	expected := map[string]int{"flavors": 1, "images": 1, "volume_types": 1}
+	if !reflect.DeepEqual(fetches, expected) {
+		t.Errorf("expected the catalog to be queried %v, got: %v", expected, fetches)
+	}
+}
+
+func TestValidateComputeInstanceCompatibility_existingInstance(t *testing.T) {
+	r := ResourceComputeInstance()
+	meta := &config.Config{Region: "ru-moscow-1"}
+	newTestComputeInstanceCatalog(meta)
+
+	// the flavor of the instance is no longer listed, but it is not changed
+	state := &terraform.InstanceState{
+		ID: "server-id",
+		Attributes: map[string]string{
+			"id":                "server-id",
+			"name":              "test",
+			"flavor_id":         "s3.large.2",
+			"image_id":          "image-x86",
+			"availability_zone": "ru-moscow-1a",
+			"network.#":         "1",
+			"network.0.uuid":    "subnet-id",
+		},
+	}
+	raw := map[string]interface{}{
+		"name":              "test-renamed",
+		"flavor_id":         "s3.large.2",
+		"image_id":          "image-x86",
+		"availability_zone": "ru-moscow-1a",
+		"network":           []interface{}{map[string]interface{}{"uuid": "subnet-id"}},
+	}
+	if _, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	raw["flavor_id"] = "s7.large.2"
+	_, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if expectedErr := "the flavor s7.large.2 is not found"; err == nil || !strings.Contains(err.Error(), expectedErr) {
+		t.Errorf("expected the error to contain %q, got: %v", expectedErr, err)
+	}
+}
+
+func TestComputeInstanceCatalog_concurrentQueries(t *testing.T) {
+	catalog, _ := newTestComputeInstanceCatalog(&config.Config{Region: "ru-moscow-1"})
+	fetchFlavors := catalog.fetchFlavors
+	started := make(chan struct{})
+	release := make(chan struct{})
+	catalog.fetchFlavors = func(region string) ([]computeFlavor, error) {
+		close(started)
+		<-release
+		return fetchFlavors(region)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, found, _ := catalog.flavor("ru-moscow-1", "s6.large.2", ""); !found {
+			t.Errorf("expected the flavor s6.large.2 to be found")
+		}
+	}()
+	<-started
+
+	// the query of the flavors is pending, the other queries of the catalog must not wait for it
+	if _, found, _ := catalog.volumeType("ru-moscow-1", "SSD"); !found {
+		t.Errorf("expected the volume type SSD to be found")
+	}
+	if image := catalog.image("ru-moscow-1", "image-x86", ""); image == nil {
+		t.Errorf("expected the image image-x86 to be found")
+	}
+
+	close(release)
+	<-done
+}
+
+func TestParseComputeFlavorAZStatus(t *testing.T) {
+	actual := parseComputeFlavorAZStatus("ru-moscow-1a(normal), ru-moscow-1b(sellout),invalid")
+	expected := map[string]string{
+		"ru-moscow-1a": "normal",
+		"ru-moscow-1b": "sellout",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got: %v", expected, actual)
+	}
+}
+
+func TestBuildComputeInstanceCheckpointOpts(t *testing.T) {
+	r := ResourceComputeInstance()
+	d := r.Data(&terraform.InstanceState{
+		ID:         "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		Attributes: map[string]string{"name": "web.example"},
+	})
+
+	opts := buildComputeInstanceCheckpointOpts(d, "vault-id", "")
+	if opts.VaultId != "vault-id" {
+		t.Errorf("expected the vault ID vault-id, got: %s", opts.VaultId)
+	}
+	if !strings.HasPrefix(opts.Parameters.Name, "web-example-destroy-") {
+		t.Errorf("expected the generated name to start with web-example-destroy-, got: %s", opts.Parameters.Name)
+	}
+	expected := []checkpoints.Resource{
+		{ID: "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52", Type: "OS::Nova::Server", Name: "web.example"},
+	}
+	if !reflect.DeepEqual(opts.Parameters.ResourceDetails, expected) {
+		t.Errorf("expected %#v, got %#v", expected, opts.Parameters.ResourceDetails)
+	}
+
+	if opts := buildComputeInstanceCheckpointOpts(d, "vault-id", "last backup"); opts.Parameters.Name != "last-backup" {
+		t.Errorf("expected the name last-backup, got: %s", opts.Parameters.Name)
+	}
+}
+
+func TestStopComputeInstanceBeforeDestroy(t *testing.T) {
+	testCases := []struct {
+		name            string
+		status          string
+		policy          map[string]interface{}
+		expectedActions []string
+		expectedErr     bool
+	}{
+		{
+			name:            "no grace period",
+			status:          "ACTIVE",
+			policy:          map[string]interface{}{"stop_type": "SOFT", "grace_period": 0},
+			expectedActions: []string{"HARD"},
+			expectedErr:     true,
+		},
+		{
+			name:            "hard stop",
+			status:          "ACTIVE",
+			policy:          map[string]interface{}{"stop_type": "HARD", "grace_period": 300},
+			expectedActions: []string{"HARD"},
+			expectedErr:     true,
+		},
+		{
+			name:   "already stopped",
+			status: "SHUTOFF",
+			policy: map[string]interface{}{"stop_type": "SOFT", "grace_period": 300},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actions := make([]string, 0)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/cloudservers/server-id"):
+					fmt.Fprintf(w, `{"server": {"id": "server-id", "status": "%s"}}`, tc.status)
+				case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/cloudservers/action"):
+					var body struct {
+						Stop struct {
+							Type string `json:"type"`
+						} `json:"os-stop"`
+					}
+					_ = json.NewDecoder(r.Body).Decode(&body)
+					actions = append(actions, body.Stop.Type)
+					// the power action fails, so that the stop is not waited for
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprint(w, `{"error": {"message": "internal error"}}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			client := &golangsdk.ServiceClient{
+				ProviderClient: &golangsdk.ProviderClient{},
+				Endpoint:       server.URL + "/v1/project-id/",
+			}
+
+			d := ResourceComputeInstance().Data(&terraform.InstanceState{ID: "server-id"})
+			err := stopComputeInstanceBeforeDestroy(context.Background(), d, client, tc.policy)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error %t, got: %v", tc.expectedErr, err)
+			}
+			if len(tc.expectedActions) == 0 && len(actions) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(actions, tc.expectedActions) {
+				t.Errorf("expected the stop actions %v, got: %v", tc.expectedActions, actions)
+			}
+		})
+	}
+}
+
+func TestGetComputeInstanceCheckpointStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         string
+		protectStatus  []string
+		expectedStatus string
+		expectedErr    bool
+	}{
+		{name: "protecting", status: "protecting", expectedStatus: "PENDING"},
+		{name: "backup protecting", status: "available", protectStatus: []string{"available", "protecting"},
+			expectedStatus: "PENDING"},
+		{name: "no backups", status: "available", expectedStatus: "PENDING"},
+		{name: "available", status: "available", protectStatus: []string{"available"}, expectedStatus: "COMPLETED"},
+		{name: "checkpoint error", status: "error", expectedStatus: "ERROR", expectedErr: true},
+		{name: "backup error", status: "available", protectStatus: []string{"error"}, expectedStatus: "ERROR",
+			expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkpoint := &checkpoints.Checkpoint{Status: tc.status}
+			for i, status := range tc.protectStatus {
+				checkpoint.Vault.Resources = append(checkpoint.Vault.Resources, checkpoints.CheckpointResource{
+					ID:            fmt.Sprintf("resource-%d", i),
+					ProtectStatus: status,
+				})
+			}
+
+			status, err := getComputeInstanceCheckpointStatus(checkpoint)
+			if status != tc.expectedStatus || (err != nil) != tc.expectedErr {
+				t.Errorf("expected status %s and error %t, got: %s, %v", tc.expectedStatus, tc.expectedErr, status, err)
+			}
+		})
+	}
+}
+
+func TestFlattenComputeInstanceImportedDataDisks(t *testing.T) {
+	attachments := []cloudservers.VolumeAttached{
+		{ID: "volume-aa", BootIndex: "27", Device: "/dev/vdaa"},
+		{ID: "volume-c", BootIndex: "2", Device: "/dev/vdc"},
+		{ID: "system", BootIndex: "0", Device: "/dev/vda"},
+		{ID: "volume-b", BootIndex: "1", Device: "/dev/vdb"},
+	}
+	volumes := map[string]*cloudvolumes.Volume{
+		"volume-b":  {VolumeType: "SSD", Size: 20, SnapshotID: "snapshot-id"},
+		"volume-c":  {VolumeType: "GPSSD2", Size: 30, IOPS: cloudvolumes.IOPSAndThroughput{TotalVal: 3000}},
+		"volume-aa": {VolumeType: "SAS", Size: 10, DedicatedStorageID: "dss-id"},
+	}
+	volumes["volume-b"].Metadata.SystemCmkID = "kms-id"
+	volumes["volume-c"].Throughput.TotalVal = 125
+	expected := []map[string]interface{}{
+		{"id": "volume-b", "type": "SSD", "size": 20, "snapshot_id": "snapshot-id", "kms_key_id": "kms-id",
+			"iops": 0, "throughput": 0, "dss_pool_id": ""},
+		{"id": "volume-c", "type": "GPSSD2", "size": 30, "snapshot_id": "", "kms_key_id": "",
+			"iops": 3000, "throughput": 125, "dss_pool_id": ""},
+		{"id": "volume-aa", "type": "SAS", "size": 10, "snapshot_id": "", "kms_key_id": "",
+			"iops": 0, "throughput": 0, "dss_pool_id": "dss-id"},
+	}
+
+	actual, err := flattenComputeInstanceImportedDataDisks(attachments, func(volumeID string) (*cloudvolumes.Volume, error) {
+		if volumeID == "system" {
+			t.Errorf("the system disk is retrieved as a data disk")
+		}
+		return volumes[volumeID], nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+
+	_, err = flattenComputeInstanceImportedDataDisks(attachments, func(string) (*cloudvolumes.Volume, error) {
+		return nil, fmt.Errorf("not found")
+	})
+	if err == nil {
+		t.Errorf("expected an error if a volume can not be retrieved")
+	}
+}
+
+func TestSuppressComputeInstanceUserDataDiffs(t *testing.T) {
+	userData := "#!/bin/bash\necho hello\n"
+	encoded := base64.StdEncoding.EncodeToString([]byte(userData))
+	imported := flattenComputeInstanceImportedUserData(encoded)
+	if imported != utils.HashAndHexEncode(userData) {
+		t.Fatalf("expected the user data to be imported as the hash of the plain text, got %s", imported)
+	}
+
+	testCases := []struct {
+		name     string
+		config   string
+		expected bool
+	}{
+		{name: "base64", config: encoded, expected: true},
+		{name: "changed", config: base64.StdEncoding.EncodeToString([]byte("echo bye")), expected: false},
+		{name: "removed", config: "", expected: false},
+	}
+	r := ResourceComputeInstance()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawConfig := cty.ObjectVal(map[string]cty.Value{"user_data": cty.NullVal(cty.String)})
+			if tc.config != "" {
+				rawConfig = cty.ObjectVal(map[string]cty.Value{"user_data": cty.StringVal(tc.config)})
+			}
+			d := r.Data(&terraform.InstanceState{ID: "server-id", RawConfig: rawConfig})
+			actual := suppressComputeInstanceUserDataDiffs("user_data", imported, utils.HashAndHexEncode(tc.config), d)
+			if actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenComputeInstanceOrderPeriod(t *testing.T) {
+	testCases := []struct {
+		name           string
+		order          string
+		expectedUnit   string
+		expectedPeriod int
+		expectedErr    bool
+	}{
+		{
+			name: "ECS line item",
+			order: `{"order_line_items": [
+				{"cloud_service_type_code": "hws.service.type.ebs", "period_type": 2, "period_num": 6},
+				{"cloud_service_type_code": "hws.service.type.ec2", "period_type": 3, "period_num": 1}
+			]}`,
+			expectedUnit:   "year",
+			expectedPeriod: 1,
+		},
+		{
+			name:           "first line item",
+			order:          `{"order_line_items": [{"period_type": 2, "period_num": 3}]}`,
+			expectedUnit:   "month",
+			expectedPeriod: 3,
+		},
+		{
+			name:        "one-off",
+			order:       `{"order_line_items": [{"period_type": 4, "period_num": 1}]}`,
+			expectedErr: true,
+		},
+		{
+			name:        "no line items",
+			order:       `{"order_info": {}}`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var order interface{}
+			if err := json.Unmarshal([]byte(tc.order), &order); err != nil {
+				t.Fatal(err)
+			}
+			unit, period, err := flattenComputeInstanceOrderPeriod(order)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got: %v", tc.expectedErr, err)
+			}
+			if unit != tc.expectedUnit || period != tc.expectedPeriod {
+				t.Errorf("expected %d %s, got %d %s", tc.expectedPeriod, tc.expectedUnit, period, unit)
+			}
+		})
+	}
+}
+
+func TestWithComputeInstanceAdminPass(t *testing.T) {
+	r := ResourceComputeInstance()
+	d := r.Data(&terraform.InstanceState{
+		RawConfig: cty.ObjectVal(map[string]cty.Value{"admin_pass_wo": cty.StringVal("Test@123")}),
+	})
+
+	if adminPass := getComputeInstanceAdminPass(d); adminPass != "Test@123" {
+		t.Fatalf("expected the write-only password, got %q", adminPass)
+	}
+
+	var createPass string
+	diags := withComputeInstanceAdminPass(d, func() diag.Diagnostics {
+		createPass = d.Get("admin_pass").(string)
+		d.SetId("c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52")
+		return nil
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if createPass != "Test@123" {
+		t.Errorf("expected the creation to use the write-only password, got %q", createPass)
+	}
+	if adminPass := d.State().Attributes["admin_pass"]; adminPass != "" {
+		t.Errorf("expected the write-only password not to be saved in the state, got %q", adminPass)
+	}
+
+	state := &terraform.InstanceState{
+		ID: "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+		Attributes: map[string]string{
+			"id":                    "c7a2d7a4-3c6f-4f8a-9f0e-4b7d1a0c6e52",
+			"admin_pass_wo_version": "1",
+		},
+	}
+	for version, expected := range map[string]string{"1": "", "2": "Test@123"} {
+		diff := &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{},
+			RawConfig: cty.ObjectVal(map[string]cty.Value{
+				"admin_pass_wo": cty.StringVal("Test@123"),
+			}),
+		}
+		if version != "1" {
+			diff.Attributes["admin_pass_wo_version"] = &terraform.ResourceAttrDiff{Old: "1", New: version}
+		}
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("error building the resource data: %s", err)
+		}
+
+		var updatePass string
+		diags := withComputeInstanceAdminPass(d, func() diag.Diagnostics {
+			updatePass = d.Get("admin_pass").(string)
+			return nil
+		})
+		if diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if updatePass != expected {
+			t.Errorf("version %s: expected the update to use the password %q, got %q", version, expected, updatePass)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 // @API GES GET /v2/{project_id}/graphs/{id}
 // @API GES DELETE /v2/{project_id}/graphs/{id}
 // @API GES POST /v2/{project_id}/graphs/{id}/expand
+// @API GES POST /v2/{project_id}/graphs/{id}/tags/action
 func ResourceGesGraph() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceGesGraphCreate,
@@ -43,6 +44,8 @@ func ResourceGesGraph() *schema.Resource {
 			Delete: schema.DefaultTimeout(60 * time.Minute),
 		},
 
+		CustomizeDiff: config.MergeDefaultTags(),
+
 		Schema: map[string]*schema.Schema{
 			"region": {
 				Type:     schema.TypeString,
@@ -143,7 +146,6 @@ func ResourceGesGraph() *schema.Resource {
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: `The key/value pairs to associate with the graph.`,
 			},
 			"enable_rbac": {
@@ -687,9 +689,69 @@ func resourceGesGraphUpdate(ctx context.Context, d *schema.ResourceData, meta in
 		}
 	}
 
+	if d.HasChange("tags") {
+		client, err := cfg.NewServiceClient("ges", region)
+		if err != nil {
+			return diag.Errorf("error creating GES Client: %s", err)
+		}
+		if err := updateGraphTags(client, d); err != nil {
+			return diag.Errorf("error updating the tags of GesGraph (%s): %s", d.Id(), err)
+		}
+	}
+
 	return resourceGesGraphRead(ctx, d, meta)
 }
 
+// updateGraphTags deletes the tags which are removed or changed, and then creates the tags which are added or changed.
+func updateGraphTags(client *golangsdk.ServiceClient, d *schema.ResourceData) error {
+	oRaw, nRaw := d.GetChange("tags")
+	oldTags := oRaw.(map[string]interface{})
+	newTags := nRaw.(map[string]interface{})
+
+	deleteTags := make(map[string]interface{})
+	for k, v := range oldTags {
+		if nv, ok := newTags[k]; !ok || nv != v {
+			deleteTags[k] = v
+		}
+	}
+	createTags := make(map[string]interface{})
+	for k, v := range newTags {
+		if ov, ok := oldTags[k]; !ok || ov != v {
+			createTags[k] = v
+		}
+	}
+
+	if len(deleteTags) > 0 {
+		if err := doGraphTagsAction(client, d.Id(), "delete", deleteTags); err != nil {
+			return err
+		}
+	}
+	if len(createTags) > 0 {
+		return doGraphTagsAction(client, d.Id(), "create", createTags)
+	}
+	return nil
+}
+
+func doGraphTagsAction(client *golangsdk.ServiceClient, graphId, action string, tags map[string]interface{}) error {
+	tagsActionPath := client.Endpoint + "v2/{project_id}/graphs/{id}/tags/action"
+	tagsActionPath = strings.ReplaceAll(tagsActionPath, "{project_id}", client.ProjectID)
+	tagsActionPath = strings.ReplaceAll(tagsActionPath, "{id}", graphId)
+
+	tagsActionOpt := golangsdk.RequestOpts{
+		KeepResponseBody: true,
+		OkCodes: []int{
+			200, 204,
+		},
+		MoreHeaders: map[string]string{"Content-Type": "application/json;charset=UTF-8"},
+		JSONBody: map[string]interface{}{
+			"action": action,
+			"tags":   utils.ExpandResourceTags(tags),
+		},
+	}
+	_, err := client.Request("POST", tagsActionPath, &tagsActionOpt)
+	return err
+}
+
 func buildExpandGraphReplicationBodyParams(d *schema.ResourceData) map[string]interface{} {
 	bodyParams := map[string]interface{}{
 		"expand": map[string]interface{}{
@@ -85,6 +85,8 @@ func ResourceRdsInstance() *schema.Resource {
 			Default: schema.DefaultTimeout(15 * time.Minute),
 		},
 
+		CustomizeDiff: config.MergeDefaultTags(),
+
 		Schema: map[string]*schema.Schema{
 			"region": {
 				Type:     schema.TypeString,
@@ -131,9 +133,22 @@ func ResourceRdsInstance() *schema.Resource {
 							ForceNew: true,
 						},
 						"password": {
-							Type:      schema.TypeString,
-							Sensitive: true,
-							Optional:  true,
+							Type:          schema.TypeString,
+							Sensitive:     true,
+							Optional:      true,
+							ConflictsWith: []string{"db.0.password_wo"},
+						},
+						"password_wo": {
+							Type:          schema.TypeString,
+							Sensitive:     true,
+							Optional:      true,
+							WriteOnly:     true,
+							ConflictsWith: []string{"db.0.password"},
+						},
+						"password_wo_version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							RequiredWith: []string{"db.0.password_wo"},
 						},
 						"port": {
 							Type:     schema.TypeInt,
@@ -588,7 +603,7 @@ func resourceRdsInstanceCreate(ctx context.Context, d *schema.ResourceData, meta
 
 	log.Printf("[DEBUG] Create Options: %#v", createOpts)
 	// Add password here so it wouldn't go in the above log entry
-	createOpts.Password = d.Get("db.0.password").(string)
+	createOpts.Password = getRdsRootPassword(d)
 
 	res, err := instances.Create(client, createOpts).Extract()
 	if err != nil {
@@ -850,6 +865,7 @@ func resourceRdsInstanceRead(ctx context.Context, d *schema.ResourceData, meta i
 	}
 	if len(d.Get("db").([]interface{})) > 0 {
 		database["password"] = d.Get("db.0.password")
+		database["password_wo_version"] = d.Get("db.0.password_wo_version")
 	}
 	dbList[0] = database
 	if err := d.Set("db", dbList); err != nil {
@@ -2377,14 +2393,23 @@ func rdsInstanceStateRefreshFunc(client *golangsdk.ServiceClient, instanceID str
 	}
 }
 
+// getRdsRootPassword returns the write-only password from the configuration if it is specified,
+// otherwise the password which is saved in the state.
+func getRdsRootPassword(d *schema.ResourceData) string {
+	if v, ok := utils.GetNestedObjectFromRawConfig(d.GetRawConfig(), "db.0.password_wo").(string); ok && v != "" {
+		return v
+	}
+	return d.Get("db.0.password").(string)
+}
+
 func updateRdsRootPassword(ctx context.Context, d *schema.ResourceData, client *golangsdk.ServiceClient,
 	instanceID string) error {
-	if !d.HasChange("db.0.password") {
+	if !d.HasChanges("db.0.password", "db.0.password_wo_version") {
 		return nil
 	}
 
 	updateOpts := instances.RestRootPasswordOpts{
-		DbUserPwd: d.Get("db.0.password").(string),
+		DbUserPwd: getRdsRootPassword(d),
 	}
 
 	retryFunc := func() (interface{}, bool, error) {
@@ -0,0 +1,120 @@
+package sbercloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+// defaultSharedConfigFile is the location of the SberCloud CLI config file, relative to the user home directory.
+const defaultSharedConfigFile = ".sbercloud/config.json"
+
+// sharedConfigFilePath returns the expanded path of the shared config file.
+// If path is empty, the default SberCloud location in the user home directory is used.
+func sharedConfigFilePath(path string) (string, error) {
+	if path != "" && path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the user home directory: %s", err)
+	}
+
+	if path == "" {
+		return filepath.Join(home, defaultSharedConfigFile), nil
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// readSharedConfig loads the credentials of the selected profile from the shared config file into c.
+//
+// The profile is selected by c.Profile, or by the "current" field of the file if c.Profile is empty.
+// Credentials configured for the provider always take precedence over the profile:
+//   - if access_key and secret_key are set, the credentials of the profile are ignored;
+//   - if user_name and password are set, the credentials of the profile are ignored;
+//   - otherwise the AK/SK (and security token) of the profile are used.
+//
+// The region of the provider is never overridden. The project ID of the profile is only used when the
+// region of the profile matches the region of the provider, as project IDs are region specific.
+func readSharedConfig(c *config.Config) error {
+	path, err := sharedConfigFilePath(c.SharedConfigFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("the specified shared config file %s does not exist", path)
+		}
+		return fmt.Errorf("error reading from shared config file %s: %s", path, err)
+	}
+
+	var sharedConfig config.SharedConfig
+	if err := json.Unmarshal(data, &sharedConfig); err != nil {
+		return fmt.Errorf("error parsing shared config file %s: %s", path, err)
+	}
+
+	current := c.Profile
+	if current == "" {
+		current = sharedConfig.Current
+	}
+
+	var profile *config.Profile
+	for i := range sharedConfig.Profiles {
+		if sharedConfig.Profiles[i].Name == current {
+			profile = &sharedConfig.Profiles[i]
+			break
+		}
+	}
+	if profile == nil {
+		return fmt.Errorf("error finding profile %q in shared config file %s", current, path)
+	}
+
+	if c.AccessKey != "" && c.SecretKey != "" {
+		log.Printf("[DEBUG] access_key is configured, ignoring the credentials of profile %s", current)
+		return nil
+	}
+	if c.Username != "" && c.Password != "" {
+		log.Printf("[DEBUG] user_name is configured, ignoring the credentials of profile %s", current)
+		return nil
+	}
+
+	if profile.Mode == "SSO" {
+		stsToken := profile.SsoAuth.StsToken
+		if (stsToken == config.StsToken{}) {
+			return fmt.Errorf("error finding ssoAuth.stsToken in profile %s, which is required in SSO mode", current)
+		}
+		c.AccessKey = stsToken.AccessKeyId
+		c.SecretKey = stsToken.SecretAccessKey
+		c.SecurityToken = stsToken.SecurityToken
+	} else {
+		c.AccessKey = profile.AccessKeyId
+		c.SecretKey = profile.SecretAccessKey
+		c.SecurityToken = profile.SecurityToken
+	}
+
+	if c.AccessKey == "" || c.SecretKey == "" {
+		return fmt.Errorf("the access key and secret key must be set in profile %s", current)
+	}
+
+	if profile.ProjectId != "" && profile.Region == c.Region {
+		c.TenantID = profile.ProjectId
+	}
+	if profile.DomainId != "" && c.DomainID == "" {
+		c.DomainID = profile.DomainId
+	}
+	if profile.AgencyName != "" {
+		c.AssumeRoleAgency = profile.AgencyName
+		c.AssumeRoleDomain = profile.AgencyDomainName
+	}
+
+	log.Printf("[DEBUG] using the credentials of profile %s from shared config file %s", current, path)
+	return nil
+}
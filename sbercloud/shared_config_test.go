@@ -0,0 +1,245 @@
+package sbercloud
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/huaweicloud/terraform-provider-huaweicloud/huaweicloud/config"
+)
+
+const testSharedConfigFile = "testdata/shared_config.json"
+
+func TestReadSharedConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    config.Config
+		expected config.Config
+	}{
+		{
+			name: "current profile",
+			input: config.Config{
+				Region: "ru-moscow-1",
+			},
+			expected: config.Config{
+				Region:    "ru-moscow-1",
+				AccessKey: "DEV_ACCESS_KEY",
+				SecretKey: "DEV_SECRET_KEY",
+				TenantID:  "dev-project-id",
+				DomainID:  "dev-domain-id",
+			},
+		},
+		{
+			name: "named profile",
+			input: config.Config{
+				Region:  "ru-moscow-1",
+				Profile: "prod",
+			},
+			expected: config.Config{
+				Region:           "ru-moscow-1",
+				Profile:          "prod",
+				AccessKey:        "PROD_ACCESS_KEY",
+				SecretKey:        "PROD_SECRET_KEY",
+				SecurityToken:    "PROD_SECURITY_TOKEN",
+				TenantID:         "prod-project-id",
+				AssumeRoleAgency: "prod-agency",
+				AssumeRoleDomain: "prod-domain",
+			},
+		},
+		{
+			name: "SSO profile in another region",
+			input: config.Config{
+				Region:  "ru-moscow-1",
+				Profile: "stage",
+			},
+			expected: config.Config{
+				Region:        "ru-moscow-1",
+				Profile:       "stage",
+				AccessKey:     "STAGE_STS_ACCESS_KEY",
+				SecretKey:     "STAGE_STS_SECRET_KEY",
+				SecurityToken: "STAGE_STS_SECURITY_TOKEN",
+			},
+		},
+		{
+			name: "access_key takes precedence",
+			input: config.Config{
+				Region:    "ru-moscow-1",
+				Profile:   "prod",
+				AccessKey: "STATIC_ACCESS_KEY",
+				SecretKey: "STATIC_SECRET_KEY",
+			},
+			expected: config.Config{
+				Region:    "ru-moscow-1",
+				Profile:   "prod",
+				AccessKey: "STATIC_ACCESS_KEY",
+				SecretKey: "STATIC_SECRET_KEY",
+			},
+		},
+		{
+			name: "user_name takes precedence",
+			input: config.Config{
+				Region:   "ru-moscow-1",
+				Profile:  "prod",
+				Username: "user",
+				Password: "password",
+			},
+			expected: config.Config{
+				Region:   "ru-moscow-1",
+				Profile:  "prod",
+				Username: "user",
+				Password: "password",
+			},
+		},
+		{
+			name: "domain_id takes precedence",
+			input: config.Config{
+				Region:   "ru-moscow-1",
+				DomainID: "static-domain-id",
+			},
+			expected: config.Config{
+				Region:    "ru-moscow-1",
+				AccessKey: "DEV_ACCESS_KEY",
+				SecretKey: "DEV_SECRET_KEY",
+				TenantID:  "dev-project-id",
+				DomainID:  "static-domain-id",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.input
+			c.SharedConfigFile = testSharedConfigFile
+
+			if err := readSharedConfig(&c); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			checks := map[string][2]string{
+				"AccessKey":        {tc.expected.AccessKey, c.AccessKey},
+				"SecretKey":        {tc.expected.SecretKey, c.SecretKey},
+				"SecurityToken":    {tc.expected.SecurityToken, c.SecurityToken},
+				"Region":           {tc.expected.Region, c.Region},
+				"TenantID":         {tc.expected.TenantID, c.TenantID},
+				"DomainID":         {tc.expected.DomainID, c.DomainID},
+				"Username":         {tc.expected.Username, c.Username},
+				"Password":         {tc.expected.Password, c.Password},
+				"AssumeRoleAgency": {tc.expected.AssumeRoleAgency, c.AssumeRoleAgency},
+				"AssumeRoleDomain": {tc.expected.AssumeRoleDomain, c.AssumeRoleDomain},
+			}
+			for field, v := range checks {
+				if v[0] != v[1] {
+					t.Errorf("expected %s to be %q, got %q", field, v[0], v[1])
+				}
+			}
+		})
+	}
+}
+
+func TestReadSharedConfig_Errors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		file      string
+		profile   string
+		accessKey string
+		errorMsg  string
+	}{
+		{
+			name:     "missing file",
+			file:     "testdata/not_exist.json",
+			errorMsg: "does not exist",
+		},
+		{
+			name:     "missing profile",
+			file:     testSharedConfigFile,
+			profile:  "unknown",
+			errorMsg: `error finding profile "unknown"`,
+		},
+		{
+			// a missing profile must be reported even if the static credentials are set
+			name:      "missing profile with access_key",
+			file:      testSharedConfigFile,
+			profile:   "unknown",
+			accessKey: "STATIC_ACCESS_KEY",
+			errorMsg:  `error finding profile "unknown"`,
+		},
+		{
+			name:     "SSO profile without token",
+			file:     testSharedConfigFile,
+			profile:  "broken-sso",
+			errorMsg: "ssoAuth.stsToken",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.Config{
+				Region:           "ru-moscow-1",
+				SharedConfigFile: tc.file,
+				Profile:          tc.profile,
+			}
+			if tc.accessKey != "" {
+				c.AccessKey, c.SecretKey = tc.accessKey, "STATIC_SECRET_KEY"
+			}
+
+			err := readSharedConfig(&c)
+			if err == nil {
+				t.Fatalf("expected an error containing %q, got nil", tc.errorMsg)
+			}
+			if !strings.Contains(err.Error(), tc.errorMsg) {
+				t.Errorf("expected an error containing %q, got %q", tc.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestSharedConfigFilePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	testCases := map[string]string{
+		"":                      filepath.Join(home, ".sbercloud", "config.json"),
+		"~/.config/sbc.json":    filepath.Join(home, ".config", "sbc.json"),
+		"/etc/sbercloud/config": "/etc/sbercloud/config",
+	}
+
+	for input, expected := range testCases {
+		got, err := sharedConfigFilePath(input)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", input, err)
+		}
+		if got != expected {
+			t.Errorf("expected %q for %q, got %q", expected, input, got)
+		}
+	}
+}
+
+func TestReadSharedConfig_DefaultFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	data, err := os.ReadFile(testSharedConfigFile)
+	if err != nil {
+		t.Fatalf("error reading fixture: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".sbercloud"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".sbercloud", "config.json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.Config{
+		Region:  "ru-moscow-1",
+		Profile: "prod",
+	}
+	if err := readSharedConfig(&c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if c.AccessKey != "PROD_ACCESS_KEY" {
+		t.Errorf("expected the credentials of profile prod, got access key %q", c.AccessKey)
+	}
+}